@@ -2,27 +2,38 @@ package main
 
 import (
 	"auction/internal/app"
-	"github.com/labstack/gommon/log"
+	"auction/internal/infrastructure/logging"
 	"log/slog"
+	"os"
 )
 
 func main() {
 	cfg, err := app.MustLoad()
 	if err != nil {
-		log.Fatal("Ошибка инициализации конфига", slog.Any("error", err))
+		fatal(slog.Default(), "Ошибка инициализации конфига", err)
 	}
 
+	logger := logging.New(cfg.Env, os.Stdout)
+	slog.SetDefault(logger)
+
 	dbc, err := app.InitDB(*cfg)
 	if err != nil {
-		log.Fatal("Ошибка инициализации базы данных", slog.Any("error", err))
+		fatal(logger, "Ошибка инициализации базы данных", err)
 	}
 	// Применение миграций
-	if err := app.RunMigrations(dbc); err != nil {
-		log.Fatal("Ошибка выполнения миграций", slog.Any("error", err))
+	if err := app.RunMigrations(dbc, logger); err != nil {
+		fatal(logger, "Ошибка выполнения миграций", err)
 	}
-	a, err := app.NewApp(*cfg, dbc)
+	a, err := app.NewApp(*cfg, dbc, logger)
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "Ошибка инициализации приложения", err)
+	}
+	if err := a.Run(); err != nil {
+		fatal(logger, "Ошибка работы приложения", err)
 	}
-	a.Run()
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
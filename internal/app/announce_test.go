@@ -0,0 +1,76 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/notify"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyUsersAboutNewAuctionsDigest(t *testing.T) {
+	now := time.Now()
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1, CreatedAt: now.Add(-5 * time.Minute)}
+	store.state.auctions[2] = domain.Auction{AuctionID: 2, CreatedAt: now.Add(-time.Minute)}
+	service := newFakeService(store)
+
+	// Самый старый аукцион ждёт меньше окна: рассылка откладывается
+	require.NoError(t, service.NotifyUsersAboutNewAuctions(context.Background(), 10*time.Minute))
+	assert.Empty(t, store.state.outbox)
+	assert.Nil(t, store.state.auctions[1].AnnouncedAt)
+
+	closedAt := now.Add(-time.Second)
+	cancelledAt := now.Add(-time.Second)
+	store.state.auctions[3] = domain.Auction{AuctionID: 3, CreatedAt: now.Add(-20 * time.Minute), ClosedAt: &closedAt}
+	store.state.auctions[4] = domain.Auction{AuctionID: 4, CreatedAt: now.Add(-20 * time.Minute), CancelledAt: &cancelledAt}
+
+	require.NoError(t, service.NotifyUsersAboutNewAuctions(context.Background(), 10*time.Minute))
+	require.Len(t, store.state.outbox, 1)
+	message := store.state.outbox[0]
+	assert.Equal(t, domain.OutboxAuctionsAnnounced, message.Topic)
+	var event domain.AuctionsAnnouncedEvent
+	require.NoError(t, json.Unmarshal(message.Payload, &event))
+	// Закрытый и отменённый аукционы отмечены, но не объявляются
+	assert.Equal(t, []int{1, 2}, event.AuctionIDs)
+	for _, auction := range store.state.auctions {
+		assert.NotNil(t, auction.AnnouncedAt, "auction %d", auction.AuctionID)
+	}
+
+	// Каждый аукцион объявляется один раз
+	require.NoError(t, service.NotifyUsersAboutNewAuctions(context.Background(), 0))
+	assert.Len(t, store.state.outbox, 1)
+}
+
+func TestNotifyUsersAboutNewAuctionsRollsBack(t *testing.T) {
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1, CreatedAt: time.Now().Add(-time.Hour)}
+	store.failures["outbox.enqueue"] = errors.New("connection reset")
+	service := newFakeService(store)
+
+	require.Error(t, service.NotifyUsersAboutNewAuctions(context.Background(), time.Minute))
+	// Без события аукцион остаётся необъявленным и попадёт в следующую рассылку
+	assert.Nil(t, store.state.auctions[1].AnnouncedAt)
+}
+
+func TestNotificationSinkAnnouncesAuctions(t *testing.T) {
+	store := newFakeStore()
+	store.state.lots[7] = domain.Lot{LotID: 7, AuctionID: 3, Title: "Часы", StartPrice: 100}
+	store.state.lots[8] = domain.Lot{LotID: 8, AuctionID: 4, Title: "Картина", StartPrice: 500}
+	notifier := &fakeNotifyService{}
+	message, err := domain.NewOutboxMessage(domain.OutboxAuctionsAnnounced, domain.AuctionsAnnouncedEvent{AuctionIDs: []int{3, 4}})
+	require.NoError(t, err)
+
+	require.NoError(t, NewNotificationSink(notifier, &fakeLotRepo{store: store}).Handle(context.Background(), message))
+
+	require.Len(t, notifier.announced, 1)
+	assert.Equal(t, []notify.AuctionSummary{
+		{AuctionID: 3, LotTitle: "Часы", StartPrice: 100},
+		{AuctionID: 4, LotTitle: "Картина", StartPrice: 500},
+	}, notifier.announced[0])
+}
@@ -2,78 +2,324 @@ package app
 
 import (
 	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
 	"auction/internal/infrastructure/notify"
+	"auction/internal/infrastructure/outbound"
 	"auction/internal/infrastructure/payment"
 	"auction/internal/infrastructure/repo"
+	"auction/internal/infrastructure/webhook"
 	"auction/internal/interfaces/rpc"
 	v1 "auction/internal/interfaces/rpc/pb"
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"github.com/go-pg/migrations/v8"
 	"github.com/go-pg/pg/v10"
-	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"google.golang.org/grpc"
-	"log"
-	"net"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 )
 
 type App struct {
 	Cfg     Config
 	Db      *pg.DB
-	Log     *log.Logger
+	Log     *slog.Logger
 	Auction domain.AuctionService
-	workers []Worker
+	Auth    *auth.Verifier
+	Authz   *rpc.Authorizer
+	Limiter *rpc.RateLimiter
+	Proxies rpc.TrustedProxies
+	Health  *Health
+	// Payments - провайдер, уведомления которого принимает REST-шлюз
+	Payments payment.PaymentProvider
+	Metrics  *rpc.ServerMetrics
+	// Registry - реестр показателей, отдаваемых на /metrics
+	Registry *prometheus.Registry
+	servers  []Server
+	workers  []Worker
+	tracing  *sdktrace.TracerProvider
+	closers  []closer
 }
 
+// closer - ресурс, закрываемый последним при остановке; name попадает в журнал при ошибке
+type closer struct {
+	name string
+	io.Closer
+}
+
+// Worker - фоновый обработчик. Stop перестаёт брать новую работу и ждёт завершения текущей;
+// по дедлайну ctx текущая работа отменяется.
 type Worker interface {
 	Start()
-	Stop()
+	Stop(ctx context.Context) error
 }
 
-func NewApp(cfg Config, db *pg.DB) (*App, error) {
-	log := log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
+func NewApp(cfg Config, db *pg.DB, log *slog.Logger) (*App, error) {
+	verifier, err := NewTokenVerifier(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init token verifier: %w", err)
+	}
+
+	proxies, err := rpc.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+	}
 
-	lotRepo := repo.NewLotRepository(db)
-	userRepo := repo.NewUserRepository(db)
-	auctionRepo := repo.NewAuctionRepository(db)
-	bidRepo := repo.NewBidRepository(db)
+	limiter, err := NewRateLimiter(cfg.RateLimit, proxies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init rate limiter: %w", err)
+	}
 
-	notifyService := notify.NewNotifyService(userRepo)
-	payment := payment.NewBalanceService()
-	auctionService := NewAuctionService(lotRepo, userRepo, auctionRepo, bidRepo, notifyService, payment)
+	exporter, err := NewSpanExporter(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init tracing: %w", err)
+	}
+	var tracing *sdktrace.TracerProvider
+	if exporter != nil {
+		tracing = NewTracerProvider(cfg.Tracing, exporter)
+		otel.SetTracerProvider(tracing)
+	}
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
 
-	auctionWorker := NewAuctionWorker(auctionService, log)
+	repos := NewRepositories(db)
 
-	return &App{
-		Cfg:     cfg,
-		Db:      db,
-		Log:     log,
-		Auction: auctionService,
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	metrics := NewMetrics(registry)
+	serverMetrics := rpc.NewServerMetrics()
+	registry.MustRegister(serverMetrics)
+
+	templates, err := notify.NewTemplates(cfg.Notify.WithDefaults().BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	notifyService := notify.NewNotifyService(repos.Users, repos.Notifications, repos.Preferences, repos.Outbox, templates, NewNotificationDrivers(cfg.Notify)...)
+	balance := payment.NewBalanceService(repos.Users)
+	payouts, err := NewPayoutProvider(cfg.Payments, log)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := NewPaymentProvider(cfg.Payments, log)
+	if err != nil {
+		return nil, err
+	}
+	commission, err := cfg.Commission.Rules()
+	if err != nil {
+		return nil, fmt.Errorf("invalid commission config: %w", err)
+	}
+	closing := NewClosingSchedule()
+	auctionService := NewTracedAuctionService(NewAuctionService(repos, notifyService, balance, payouts, payments, commission, closing, metrics))
+
+	auctionWorker := NewAuctionWorker(auctionService, closing, repo.NewAdvisoryLock(db, leaderLockKey), cfg.Scheduler, log, metrics)
+	outboxDispatcher := NewOutboxDispatcher(repos.Outbox, cfg.Outbox, log,
+		NewNotificationSink(notifyService, repos.Lots), NewWebhookSink(repos.Webhooks),
+		NewShillDetector(repos.Shill, cfg.Shill.Rules()))
+	webhookDispatcher := NewWebhookDispatcher(repos.Webhooks, webhook.NewSender(outbound.NewClient(cfg.Webhooks.WithDefaults().Timeout)), cfg.Webhooks, log)
+	health := NewHealth(log, PostgresCheck(db), MigrationsCheck(db), WorkerCheck(auctionWorker))
+
+	a := &App{
+		Cfg:      cfg,
+		Db:       db,
+		Log:      log,
+		Auction:  auctionService,
+		Auth:     verifier,
+		Authz:    rpc.NewAuthorizer(repos.Users, repos.Lots, rpc.AuctionPolicies(), healthMethods...),
+		Limiter:  limiter,
+		Proxies:  proxies,
+		Health:   health,
+		Payments: payments,
+		Metrics:  serverMetrics,
+		Registry: registry,
 		workers: []Worker{
 			auctionWorker,
+			outboxDispatcher,
+			webhookDispatcher,
+			health,
 		},
-	}, nil
+		tracing: tracing,
+		closers: []closer{{name: "database", Closer: db}},
+	}
+
+	gateway, err := a.newRESTGateway()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init REST gateway: %w", err)
+	}
+	// Шлюз проксирует запросы в gRPC, поэтому останавливается первым
+	a.servers = []Server{gateway, a.newGRPCServer()}
+
+	return a, nil
 }
 
-func (a *App) awaitShutdown() {
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-	<-stop
+// leaderLockKey - ключ advisory-блокировки, которой владеет ведущий экземпляр сервиса
+const leaderLockKey = 0x61756374696f6e
+
+// NewRepositories создаёт хранилища, работающие с базой db
+func NewRepositories(db *pg.DB) Repositories {
+	return Repositories{
+		Lots:          repo.NewLotRepository(db),
+		Users:         repo.NewUserRepository(db),
+		Auctions:      repo.NewAuctionRepository(db),
+		Bids:          repo.NewBidRepository(db),
+		Shill:         repo.NewShillRepository(db),
+		Audit:         repo.NewAuditRepository(db),
+		Settlements:   repo.NewSettlementRepository(db),
+		Outbox:        repo.NewOutboxRepository(db),
+		Notifications: repo.NewNotificationRepository(db),
+		Preferences:   repo.NewNotificationPreferencesRepository(db),
+		Webhooks:      repo.NewWebhookRepository(db),
+		Transactions:  repo.NewTransactionRepository(db),
+		Withdrawals:   repo.NewWithdrawalRepository(db),
+		Payments:      repo.NewPaymentRepository(db),
+		Watches:       repo.NewWatchRepository(db),
+		Transactor:    repo.NewTransactor(db),
+	}
+}
 
-	for _, worker := range a.workers {
-		worker.Stop()
+// NewNotificationDrivers создаёт драйверы каналов уведомлений по настройкам
+func NewNotificationDrivers(cfg Notify) []notify.Driver {
+	cfg = cfg.WithDefaults()
+	drivers := []notify.Driver{
+		notify.InboxDriver{},
+		notify.NewWebhookDriver(outbound.NewClient(cfg.WebhookTimeout)),
+	}
+	if cfg.SMTP.Host != "" {
+		drivers = append(drivers, notify.NewEmailDriver(notify.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			Timeout:  cfg.SMTP.Timeout,
+		}))
+	}
+	return drivers
+}
+
+// placeholderCallbackSecret - секрет из примера конфигурации, с которым сервис не запускается
+const placeholderCallbackSecret = "change-me-local-payment-secret"
+
+// errLocalPaymentsDisabled - провайдер local выбран без payments.allow_local
+var errLocalPaymentsDisabled = errors.New("local payment providers require payments.allow_local")
+
+// NewPaymentProvider выбирает провайдера приёма платежей по конфигурации
+func NewPaymentProvider(cfg Payments, log *slog.Logger) (payment.PaymentProvider, error) {
+	cfg = cfg.WithDefaults()
+	if cfg.CallbackSecret == "" {
+		return nil, errors.New("payments.callback_secret is required")
+	}
+	if cfg.CallbackSecret == placeholderCallbackSecret {
+		return nil, errors.New("payments.callback_secret must be changed from the example value")
+	}
+	switch cfg.Provider {
+	case "":
+		return nil, errors.New("payments.provider is required")
+	case "local":
+		if !cfg.AllowLocal {
+			return nil, errLocalPaymentsDisabled
+		}
+		return payment.NewLocalPaymentProvider(cfg.CallbackSecret, cfg.PublicURL, &http.Client{Timeout: 10 * time.Second}, log), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.Provider)
+	}
+}
+
+// NewPayoutProvider выбирает провайдера выплат по конфигурации
+func NewPayoutProvider(cfg Payments, log *slog.Logger) (payment.PayoutProvider, error) {
+	switch cfg.PayoutProvider {
+	case "":
+		return nil, errors.New("payments.payout_provider is required")
+	case "local":
+		if !cfg.AllowLocal {
+			return nil, errLocalPaymentsDisabled
+		}
+		return payment.NewLocalPayoutProvider(log), nil
+	default:
+		return nil, fmt.Errorf("unknown payout provider %q", cfg.PayoutProvider)
+	}
+}
+
+// placeholderAuthSecret - секрет ключа HS256 из примера конфигурации, с которым сервис не запускается
+const placeholderAuthSecret = "change-me-local-development-secret"
+
+// minAuthSecretLength - минимальная длина секрета HS256 в байтах: ключ не короче хеша SHA-256
+const minAuthSecretLength = 32
+
+func NewTokenVerifier(cfg Auth) (*auth.Verifier, error) {
+	keys := make([]auth.Key, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		var (
+			key auth.Key
+			err error
+		)
+		switch k.Algorithm {
+		case "HS256":
+			switch {
+			case k.Secret == "":
+				return nil, fmt.Errorf("key %q: secret is required", k.ID)
+			case k.Secret == placeholderAuthSecret:
+				return nil, fmt.Errorf("key %q: secret must be changed from the example value", k.ID)
+			case len(k.Secret) < minAuthSecretLength:
+				return nil, fmt.Errorf("key %q: secret must be at least %d bytes", k.ID, minAuthSecretLength)
+			}
+			key, err = auth.NewHMACKey(k.ID, []byte(k.Secret))
+		case "RS256":
+			var pem []byte
+			pem, err = os.ReadFile(k.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", k.ID, err)
+			}
+			key, err = auth.NewRSAKey(k.ID, pem)
+		default:
+			err = fmt.Errorf("key %q: unsupported algorithm %q", k.ID, k.Algorithm)
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return auth.NewVerifier(keys, cfg.Issuer, cfg.Audience)
+}
+
+func NewRateLimiter(cfg RateLimit, proxies rpc.TrustedProxies) (*rpc.RateLimiter, error) {
+	methods := make(map[string]bool)
+	for _, m := range v1.AuctionService_ServiceDesc.Methods {
+		methods[m.MethodName] = true
+	}
+	for _, m := range v1.AuctionService_ServiceDesc.Streams {
+		methods[m.StreamName] = true
 	}
 
-	if err := a.Db.Close(); err != nil {
-		a.Log.Printf("failed to close database: %v", err)
+	limits := make(map[string]rpc.MethodRateLimit, len(cfg.Methods))
+	for name, limit := range cfg.Methods {
+		if !methods[name] {
+			return nil, fmt.Errorf("unknown method %q", name)
+		}
+		fullMethod := "/" + v1.AuctionService_ServiceDesc.ServiceName + "/" + name
+		limits[fullMethod] = rpc.MethodRateLimit{
+			User: rpc.RateLimit{Rate: limit.UserRate, Burst: limit.UserBurst},
+			IP:   rpc.RateLimit{Rate: limit.IPRate, Burst: limit.IPBurst},
+		}
 	}
 
+	return rpc.NewRateLimiter(limits, proxies), nil
 }
 
 func InitDB(cfg Config) (*pg.DB, error) {
@@ -82,81 +328,154 @@ func InitDB(cfg Config) (*pg.DB, error) {
 		panic(err)
 	}
 	bdc := pg.Connect(opt)
+	bdc.AddQueryHook(repo.QueryHook{})
 
 	return bdc, nil
 }
 
-func (a *App) Run() {
-	var wg sync.WaitGroup
+// Run запускает серверы и обработчики и работает до SIGINT/SIGTERM или ошибки сервера
+func (a *App) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := a.startGRPCServer(); err != nil {
-			a.Log.Fatalf("failed to start gRPC server: %v", err)
-		}
-	}()
+	return a.run(ctx)
+}
+
+func (a *App) run(ctx context.Context) error {
+	errCh := make(chan error, len(a.servers))
+	for _, server := range a.servers {
+		go func(s Server) {
+			if err := s.Serve(); err != nil {
+				errCh <- err
+			}
+		}(server)
+	}
+
+	for _, worker := range a.workers {
+		worker.Start()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.Log.Info("shutting down")
+	case runErr = <-errCh:
+		a.Log.Error("server failed, shutting down", "error", runErr)
+	}
+
+	a.shutdown()
+	return runErr
+}
+
+// shutdown останавливает приложение в пределах ShutdownTimeout: сначала серверы дожидаются
+// текущих запросов, затем обработчики завершают текущую работу, и только после этого
+// закрывается база
+func (a *App) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.Cfg.shutdownTimeout())
+	defer cancel()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := a.startRESTGateway(); err != nil {
-			a.Log.Fatalf("failed to start REST gateway: %v", err)
+	if a.Health != nil {
+		a.Health.Shutdown()
+	}
+
+	for _, server := range a.servers {
+		if err := server.Shutdown(ctx); err != nil {
+			a.Log.Error("server shutdown failed", "error", err)
 		}
-	}()
+	}
 
+	var wg sync.WaitGroup
 	for _, worker := range a.workers {
 		wg.Add(1)
 		go func(w Worker) {
 			defer wg.Done()
-			w.Start()
+			if err := w.Stop(ctx); err != nil {
+				a.Log.Error("worker shutdown failed", "error", err)
+			}
 		}(worker)
 	}
-
-	a.awaitShutdown()
-
-	for _, worker := range a.workers {
-		worker.Stop()
-	}
-
 	wg.Wait()
-}
 
-func (a *App) startGRPCServer() error {
-	lis, err := net.Listen("tcp", ":"+a.Cfg.GRPCPort)
-	if err != nil {
-		return err
+	// Отправляются span'ы, оставшиеся в буфере после остановки обработчиков
+	if a.tracing != nil {
+		if err := a.tracing.Shutdown(ctx); err != nil {
+			a.Log.Error("tracer shutdown failed", "error", err)
+		}
 	}
 
-	grpcServer := grpc.NewServer()
-	v1.RegisterAuctionServiceServer(grpcServer, rpc.NewAuctionHandler(a.Auction))
+	for _, c := range a.closers {
+		if err := c.Close(); err != nil {
+			a.Log.Error("failed to close "+c.name, "error", err)
+		}
+	}
+}
 
-	a.Log.Printf("Starting gRPC server on :%s", a.Cfg.GRPCPort)
-	return grpcServer.Serve(lis)
+func (a *App) newGRPCServer() *grpcServer {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(
+			a.Metrics.UnaryInterceptor(),
+			rpc.RequestInfoUnaryInterceptor(a.Proxies, a.Log),
+			// Ограничение по IP стоит до аутентификации и защищает её от перебора токенов
+			a.Limiter.IPUnaryInterceptor(),
+			rpc.AuthUnaryInterceptor(a.Auth, healthMethods...),
+			a.Limiter.UserUnaryInterceptor(),
+			a.Authz.UnaryInterceptor(),
+			rpc.ErrorUnaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			a.Metrics.StreamInterceptor(),
+			rpc.RequestInfoStreamInterceptor(a.Proxies, a.Log),
+			a.Limiter.IPStreamInterceptor(),
+			rpc.AuthStreamInterceptor(a.Auth, healthMethods...),
+			a.Limiter.UserStreamInterceptor(),
+			a.Authz.StreamInterceptor(),
+		),
+	)
+	v1.RegisterAuctionServiceServer(server, rpc.NewAuctionHandler(a.Auction))
+	healthpb.RegisterHealthServer(server, a.Health.GRPCServer())
+
+	return newGRPCServer(server, ":"+a.Cfg.GRPCPort, a.Log)
 }
 
-func (a *App) startRESTGateway() error {
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+func (a *App) newRESTGateway() (*httpServer, error) {
+	ctx, cancel := context.WithCancel(context.Background())
 
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithInsecure()}
+	mux := rpc.NewGatewayMux()
+	opts := []grpc.DialOption{grpc.WithInsecure(), grpc.WithStatsHandler(otelgrpc.NewClientHandler())}
 
 	endpoint := "localhost:" + a.Cfg.GRPCPort
 	err := v1.RegisterAuctionServiceHandlerFromEndpoint(ctx, mux, endpoint, opts)
 	if err != nil {
-		return err
+		cancel()
+		return nil, err
 	}
 
-	a.Log.Printf("Starting HTTP/REST gateway on :%s", a.Cfg.HTTPServer.Port)
-	return http.ListenAndServe(":"+a.Cfg.HTTPServer.Port, mux)
+	handler := http.NewServeMux()
+	handler.Handle("/metrics", promhttp.HandlerFor(a.Registry, promhttp.HandlerOpts{Registry: a.Registry}))
+	handler.Handle("/healthz", a.Health.LivenessHandler())
+	handler.Handle("/readyz", a.Health.ReadinessHandler())
+	// Уведомления провайдера проверяются по подписи, а не токеном пользователя, поэтому идут мимо gRPC
+	handler.Handle("POST "+payment.CallbackPath,
+		otelhttp.NewHandler(rpc.NewPaymentCallbackHandler(a.Auction, a.Payments, a.Log), "payment_callback"))
+	// Страница оплаты локального провайдера пополняет баланс, поэтому открыта только владельцу платежа
+	if local, ok := a.Payments.(*payment.LocalPaymentProvider); ok {
+		handler.Handle(payment.LocalCheckoutPattern, rpc.AuthHTTPHandler(a.Auth, local.CheckoutHandler()))
+	}
+	handler.Handle("/", otelhttp.NewHandler(mux, "gateway"))
+
+	server := &http.Server{
+		Addr:        ":" + a.Cfg.HTTPServer.Port,
+		Handler:     handler,
+		IdleTimeout: a.Cfg.HTTPServer.IdleTimeout,
+	}
+	return newHTTPServer(server, cancel, a.Log), nil
 }
 
 //go:embed migrations/*.sql
 var MigrationFS embed.FS
 
-func RunMigrations(db *pg.DB) error {
+func RunMigrations(db *pg.DB, log *slog.Logger) error {
 	_, _, err := migrations.Run(db, "init")
 	if err != nil {
 		if err.Error() != "migration table exists" {
@@ -172,22 +491,19 @@ func RunMigrations(db *pg.DB) error {
 	if err != nil {
 		return fmt.Errorf("failed to read embedded migrations: %w", err)
 	}
-	fmt.Println("Discovered migration files:")
+	files := make([]string, 0, len(entries))
 	for _, entry := range entries {
-		fmt.Println(" -", entry.Name())
+		files = append(files, entry.Name())
 	}
+	log.Debug("discovered migration files", "files", files)
 
 	collection.DiscoverSQLMigrationsFromFilesystem(fs, "migrations")
 
-	fmt.Println("Migrations to apply:")
-	for _, m := range collection.Migrations() {
-		fmt.Println(" -", m)
-	}
-
-	if _, _, err := collection.Run(db); err != nil {
+	oldVersion, newVersion, err := collection.Run(db)
+	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	fmt.Println("Migrations applied successfully.")
+	log.Info("migrations applied", "from_version", oldVersion, "to_version", newVersion)
 	return nil
 }
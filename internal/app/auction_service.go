@@ -2,67 +2,483 @@ package app
 
 import (
 	"auction/internal/domain"
+	"auction/internal/infrastructure/logging"
 	"auction/internal/infrastructure/notify"
 	"auction/internal/infrastructure/payment"
 	"auction/internal/infrastructure/repo"
+	"auction/internal/infrastructure/webhook"
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 )
 
+// Repositories - хранилища, с которыми работает AuctionService
+type Repositories struct {
+	Lots          repo.LotRepository
+	Users         repo.UserRepository
+	Auctions      repo.AuctionRepository
+	Bids          repo.BidRepository
+	Shill         repo.ShillRepository
+	Audit         repo.AuditRepository
+	Settlements   repo.SettlementRepository
+	Outbox        repo.OutboxRepository
+	Notifications repo.NotificationRepository
+	Preferences   repo.NotificationPreferencesRepository
+	Webhooks      repo.WebhookRepository
+	Transactions  repo.TransactionRepository
+	Withdrawals   repo.WithdrawalRepository
+	Payments      repo.PaymentRepository
+	Watches       repo.WatchRepository
+	Transactor    repo.Transactor
+}
+
 type AuctionService struct {
-	lotRepo     repo.LotRepository
-	userRepo    repo.UserRepository
-	auctionRepo repo.AuctionRepository
-	bidRepo     repo.BidRepository
-	notify      notify.NotifyService
-	balance     payment.BalanceService
-}
-
-func NewAuctionService(lotRepo repo.LotRepository,
-	userRepo repo.UserRepository,
-	auctionRepo repo.AuctionRepository,
-	bidRepo repo.BidRepository,
+	lotRepo        repo.LotRepository
+	userRepo       repo.UserRepository
+	auctionRepo    repo.AuctionRepository
+	bidRepo        repo.BidRepository
+	shillRepo      repo.ShillRepository
+	auditRepo      repo.AuditRepository
+	settlementRepo repo.SettlementRepository
+	outboxRepo     repo.OutboxRepository
+	notifications  repo.NotificationRepository
+	preferences    repo.NotificationPreferencesRepository
+	webhookRepo    repo.WebhookRepository
+	transactions   repo.TransactionRepository
+	withdrawalRepo repo.WithdrawalRepository
+	paymentRepo    repo.PaymentRepository
+	watchRepo      repo.WatchRepository
+	tx             repo.Transactor
+	notify         notify.NotifyService
+	balance        payment.BalanceService
+	payouts        payment.PayoutProvider
+	payments       payment.PaymentProvider
+	commission     domain.Commission
+	closing        *ClosingSchedule
+	metrics        *Metrics
+}
+
+func NewAuctionService(repos Repositories,
 	notify notify.NotifyService,
-	balance payment.BalanceService) *AuctionService {
+	balance payment.BalanceService,
+	payouts payment.PayoutProvider,
+	payments payment.PaymentProvider,
+	commission domain.Commission,
+	closing *ClosingSchedule,
+	metrics *Metrics) *AuctionService {
 	return &AuctionService{
-		lotRepo:     lotRepo,
-		userRepo:    userRepo,
-		auctionRepo: auctionRepo,
-		bidRepo:     bidRepo,
-		notify:      notify,
-		balance:     balance,
+		lotRepo:        repos.Lots,
+		userRepo:       repos.Users,
+		auctionRepo:    repos.Auctions,
+		bidRepo:        repos.Bids,
+		shillRepo:      repos.Shill,
+		auditRepo:      repos.Audit,
+		settlementRepo: repos.Settlements,
+		outboxRepo:     repos.Outbox,
+		notifications:  repos.Notifications,
+		preferences:    repos.Preferences,
+		webhookRepo:    repos.Webhooks,
+		transactions:   repos.Transactions,
+		withdrawalRepo: repos.Withdrawals,
+		paymentRepo:    repos.Payments,
+		watchRepo:      repos.Watches,
+		tx:             repos.Transactor,
+		notify:         notify,
+		balance:        balance,
+		payouts:        payouts,
+		payments:       payments,
+		commission:     commission,
+		closing:        closing,
+		metrics:        metrics,
 	}
 }
 
 func (s *AuctionService) CreateLot(ctx context.Context, lot domain.Lot) (int, error) {
 	auction := domain.Auction{
 		CreatedAt: time.Now(),
+		UserID:    &lot.UserID,
 	}
 	err := domain.ValidateLot(lot)
 	if err != nil {
 		return 0, err
 	}
 	auction.ClosedAt = lot.ClosedAt
-	auctionID, err := s.auctionRepo.Create(ctx, auction)
+
+	var lotID int
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		auctionID, err := s.auctionRepo.Create(ctx, auction)
+		if err != nil {
+			return err
+		}
+
+		lot.AuctionID = auctionID
+		lotID, err = s.lotRepo.Create(ctx, lot)
+		if err != nil {
+			return err
+		}
+		lot.LotID = lotID
+
+		err = s.enqueue(ctx, domain.OutboxLotCreated, domain.LotCreatedEvent{
+			LotID:      lotID,
+			AuctionID:  lot.AuctionID,
+			SellerID:   lot.UserID,
+			Title:      lot.Title,
+			StartPrice: lot.StartPrice,
+			Step:       lot.Step,
+			ClosedAt:   lot.ClosedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, domain.AuditLotCreated, domain.EntityLot, lotID, nil, lotSnapshot(lot))
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	lot.AuctionID = auctionID
+	if s.closing != nil && lot.ClosedAt != nil {
+		s.closing.Set(lot.AuctionID, *lot.ClosedAt)
+	}
+	s.metrics.lotCreated()
 
-	return s.lotRepo.Create(ctx, lot)
+	return lotID, nil
 }
 
+// RefillBalance зачисляет средства без оплаты. Пользователи пополняют баланс через
+// CreatePayment, а зачисление напрямую остаётся администраторам.
 func (s *AuctionService) RefillBalance(ctx context.Context, userID int, amount int64) error {
 	if amount <= 0 {
 		return errors.New("amount must be greater than zero")
 	}
-	return s.userRepo.RefillBalance(ctx, userID, amount)
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.refill(ctx, userID, amount, nil)
+	})
+}
+
+// refill зачисляет средства и записывает пополнение в журнал. Вызывается в транзакции.
+func (s *AuctionService) refill(ctx context.Context, userID int, amount int64, paymentID *int) error {
+	if err := s.userRepo.Credit(ctx, userID, amount); err != nil {
+		return err
+	}
+	err := s.transactions.Record(ctx, domain.BalanceTransaction{
+		UserID:    userID,
+		Type:      domain.TransactionRefill,
+		Amount:    amount,
+		PaymentID: paymentID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Строка пользователя заблокирована обновлением до конца транзакции
+	balance, err := s.userRepo.GetBalance(ctx, userID)
+	if err != nil {
+		return err
+	}
+	after := int64Value(balance)
+
+	return s.audit(ctx, domain.AuditBalanceRefilled, domain.EntityUser, userID,
+		balanceSnapshot{Balance: after - amount}, balanceSnapshot{Balance: after, Amount: amount, PaymentID: paymentID})
+}
+
+// CreatePayment создаёт платёж на пополнение баланса и намерение оплаты у провайдера.
+// Баланс пополнится после подтверждения оплаты в ConfirmPayment.
+func (s *AuctionService) CreatePayment(ctx context.Context, userID int, amount int64) (domain.Payment, error) {
+	if amount <= 0 {
+		return domain.Payment{}, domain.ErrInvalidPaymentAmount
+	}
+
+	payment := domain.Payment{
+		UserID:    userID,
+		Amount:    amount,
+		Provider:  s.payments.Name(),
+		Status:    domain.PaymentPending,
+		CreatedAt: time.Now(),
+	}
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		payment.PaymentID, err = s.paymentRepo.Create(ctx, payment)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditPaymentCreated, domain.EntityPayment, payment.PaymentID,
+			nil, paymentSnapshot{Status: payment.Status, Amount: payment.Amount})
+	})
+	if err != nil {
+		return domain.Payment{}, err
+	}
+
+	// Намерение создаётся после записи платежа, чтобы провайдер получил его идентификатор
+	intent, err := s.payments.CreateIntent(ctx, payment)
+	if err != nil {
+		if confirmErr := s.confirmPayment(ctx, payment, domain.PaymentFailed); confirmErr != nil {
+			return domain.Payment{}, errors.Join(fmt.Errorf("failed to create payment intent: %w", err), confirmErr)
+		}
+		return domain.Payment{}, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	if err := s.paymentRepo.SetIntent(ctx, payment.PaymentID, intent.ID, intent.CheckoutURL); err != nil {
+		return domain.Payment{}, err
+	}
+	payment.ProviderPaymentID, payment.CheckoutURL = intent.ID, intent.CheckoutURL
+	return payment, nil
+}
+
+// ConfirmPayment применяет уведомление провайдера с проверенной подписью: при успешной
+// оплате пополняет баланс. Провайдеры повторяют уведомления, поэтому уведомление
+// по уже подтверждённому платежу ничего не меняет. Исключение - успешная оплата после
+// отказа: деньги у провайдера списаны, поэтому платёж зачисляется.
+func (s *AuctionService) ConfirmPayment(ctx context.Context, provider string, callback domain.PaymentCallback) (domain.Payment, error) {
+	var payment domain.Payment
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		payment, err = s.paymentRepo.GetByProviderIDForUpdate(ctx, provider, callback.ProviderPaymentID)
+		if err != nil {
+			return err
+		}
+		succeededAfterFailure := payment.Status == domain.PaymentFailed && callback.Status == domain.PaymentSucceeded
+		if payment.Status != domain.PaymentPending && !succeededAfterFailure {
+			return nil
+		}
+		if err := domain.ValidatePaymentCallback(payment, callback); err != nil {
+			return err
+		}
+		if succeededAfterFailure {
+			logging.FromContext(ctx).Warn("payment succeeded after failure", "payment_id", payment.PaymentID, "user_id", payment.UserID)
+		}
+
+		if err := s.recordPaymentStatus(ctx, payment, callback.Status); err != nil {
+			return err
+		}
+		now := time.Now()
+		payment.Status, payment.ConfirmedAt = callback.Status, &now
+
+		if payment.Status != domain.PaymentSucceeded {
+			return nil
+		}
+		return s.refill(ctx, payment.UserID, payment.Amount, &payment.PaymentID)
+	})
+	if err != nil {
+		return domain.Payment{}, err
+	}
+	return payment, nil
+}
+
+// confirmPayment записывает окончательный статус платежа в отдельной транзакции
+func (s *AuctionService) confirmPayment(ctx context.Context, payment domain.Payment, status domain.PaymentStatus) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.recordPaymentStatus(ctx, payment, status)
+	})
+}
+
+// recordPaymentStatus сохраняет окончательный статус платежа вместе с событием аудита
+func (s *AuctionService) recordPaymentStatus(ctx context.Context, payment domain.Payment, status domain.PaymentStatus) error {
+	if err := s.paymentRepo.Confirm(ctx, payment.PaymentID, status); err != nil {
+		return err
+	}
+	return s.audit(ctx, domain.AuditPaymentConfirmed, domain.EntityPayment, payment.PaymentID,
+		paymentSnapshot{Status: payment.Status, Amount: payment.Amount},
+		paymentSnapshot{Status: status, Amount: payment.Amount})
+}
+
+func (s *AuctionService) GetPayment(ctx context.Context, paymentID int) (domain.Payment, error) {
+	return s.paymentRepo.Get(ctx, paymentID)
+}
+
+// RequestWithdrawal создаёт заявку на вывод и удерживает её сумму с баланса. Вывести можно
+// только средства, не зарезервированные ставками в нерассчитанных аукционах.
+func (s *AuctionService) RequestWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) (int, error) {
+	withdrawal.Status = domain.WithdrawalPending
+	withdrawal.CreatedAt = time.Now()
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkAvailable(ctx, withdrawal.UserID, func(balance int64, bids []domain.Bid) error {
+			return domain.ValidateWithdrawal(withdrawal, balance, bids)
+		}); err != nil {
+			return err
+		}
+		if err := s.userRepo.Debit(ctx, withdrawal.UserID, withdrawal.Amount); err != nil {
+			return err
+		}
+
+		var err error
+		withdrawal.WithdrawalID, err = s.withdrawalRepo.Create(ctx, withdrawal)
+		if err != nil {
+			return err
+		}
+		err = s.transactions.Record(ctx, domain.BalanceTransaction{
+			UserID:       withdrawal.UserID,
+			Type:         domain.TransactionHold,
+			Amount:       -withdrawal.Amount,
+			WithdrawalID: &withdrawal.WithdrawalID,
+			CreatedAt:    withdrawal.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, domain.AuditWithdrawalRequested, domain.EntityWithdrawal, withdrawal.WithdrawalID,
+			nil, withdrawalSnapshot{Status: withdrawal.Status, Amount: withdrawal.Amount})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return withdrawal.WithdrawalID, nil
+}
+
+const (
+	// payoutTimeout ограничивает обращение к провайдеру выплат
+	payoutTimeout = 30 * time.Second
+	// payoutWriteTimeout ограничивает запись результата выплаты
+	payoutWriteTimeout = 10 * time.Second
+	// payoutStaleAfter - через сколько выплата без записанного результата считается зависшей.
+	// С запасом больше таймаутов, чтобы не повторить выплату, которая ещё выполняется.
+	payoutStaleAfter = 5 * time.Minute
+)
+
+// ResolveWithdrawal одобряет или отклоняет заявку на вывод. Одобренная заявка переводится
+// в processing и отправляется провайдеру выплат; если выплата не прошла, заявка возвращается
+// в approved с ошибкой, и повторное одобрение повторяет выплату. Так же повторяется выплата,
+// зависшая в processing дольше payoutStaleAfter. Отклонение возвращает удержание на баланс
+// и возможно только до первой попытки выплаты.
+func (s *AuctionService) ResolveWithdrawal(ctx context.Context, withdrawalID int, resolution domain.WithdrawalStatus, adminID int) (domain.Withdrawal, error) {
+	var withdrawal domain.Withdrawal
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		withdrawal, err = s.withdrawalRepo.GetForUpdate(ctx, withdrawalID)
+		if err != nil {
+			return err
+		}
+		err = domain.ValidateWithdrawalResolution(withdrawal, resolution, time.Now().Add(-payoutStaleAfter))
+		if err != nil {
+			return err
+		}
+		if withdrawal.Status != domain.WithdrawalPending {
+			// Повтор неудавшейся или зависшей выплаты: решение уже записано в журнал
+			return s.startPayout(ctx, &withdrawal)
+		}
+
+		before := withdrawalSnapshot{Status: withdrawal.Status, Amount: withdrawal.Amount}
+		if err := s.withdrawalRepo.Resolve(ctx, withdrawalID, resolution, adminID); err != nil {
+			return err
+		}
+		now := time.Now()
+		withdrawal.Status, withdrawal.ResolvedAt, withdrawal.ResolvedBy = resolution, &now, &adminID
+
+		if resolution == domain.WithdrawalRejected {
+			if err := s.userRepo.Credit(ctx, withdrawal.UserID, withdrawal.Amount); err != nil {
+				return err
+			}
+			err := s.transactions.Record(ctx, domain.BalanceTransaction{
+				UserID:       withdrawal.UserID,
+				Type:         domain.TransactionRelease,
+				Amount:       withdrawal.Amount,
+				WithdrawalID: &withdrawal.WithdrawalID,
+				CreatedAt:    now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.audit(ctx, domain.AuditWithdrawalResolved, domain.EntityWithdrawal, withdrawalID,
+			before, withdrawalSnapshot{Status: resolution, Amount: withdrawal.Amount, ResolvedBy: &adminID})
+		if err != nil || resolution != domain.WithdrawalApproved {
+			return err
+		}
+		return s.startPayout(ctx, &withdrawal)
+	})
+	if err != nil || withdrawal.Status != domain.WithdrawalProcessing {
+		return withdrawal, err
+	}
+
+	return s.payout(ctx, withdrawal)
+}
+
+// startPayout переводит заявку в processing под блокировкой строки. Параллельное одобрение
+// увидит этот статус и не отправит выплату второй раз.
+func (s *AuctionService) startPayout(ctx context.Context, withdrawal *domain.Withdrawal) error {
+	before := withdrawalSnapshot{Status: withdrawal.Status, Amount: withdrawal.Amount, LastError: withdrawal.LastError}
+	if err := s.withdrawalRepo.StartPayout(ctx, withdrawal.WithdrawalID, withdrawal.Status); err != nil {
+		return err
+	}
+	now := time.Now()
+	withdrawal.Status, withdrawal.PayoutStartedAt = domain.WithdrawalProcessing, &now
+	return s.audit(ctx, domain.AuditWithdrawalPayoutStarted, domain.EntityWithdrawal, withdrawal.WithdrawalID,
+		before, withdrawalSnapshot{Status: withdrawal.Status, Amount: withdrawal.Amount})
+}
+
+// payout отправляет заявку в статусе processing провайдеру и записывает результат. После
+// ошибки заявка возвращается в approved и выплату можно повторить. Выплата и запись результата
+// не прерываются вместе с запросом: иначе деньги уйдут, а заявка останется в processing.
+func (s *AuctionService) payout(ctx context.Context, withdrawal domain.Withdrawal) (domain.Withdrawal, error) {
+	ctx = context.WithoutCancel(ctx)
+	payoutCtx, cancel := context.WithTimeout(ctx, payoutTimeout)
+	reference, err := s.payouts.Payout(payoutCtx, withdrawal, withdrawal.PayoutIdempotencyKey())
+	cancel()
+
+	ctx, cancel = context.WithTimeout(ctx, payoutWriteTimeout)
+	defer cancel()
+	if err != nil {
+		payoutErr := fmt.Errorf("failed to pay out withdrawal %d: %w", withdrawal.WithdrawalID, err)
+		failed := withdrawal
+		failed.Status, failed.LastError = domain.WithdrawalApproved, err.Error()
+		markErr := s.finishPayout(ctx, failed, domain.AuditWithdrawalPayoutFailed, func(ctx context.Context) error {
+			return s.withdrawalRepo.MarkPayoutFailed(ctx, failed.WithdrawalID, failed.LastError)
+		})
+		if markErr != nil {
+			return withdrawal, errors.Join(payoutErr, markErr)
+		}
+		return failed, payoutErr
+	}
+
+	paid := withdrawal
+	now := time.Now()
+	paid.Status, paid.ProviderReference, paid.LastError, paid.PaidAt = domain.WithdrawalPaid, reference, "", &now
+	err = s.finishPayout(ctx, paid, domain.AuditWithdrawalPaid, func(ctx context.Context) error {
+		return s.withdrawalRepo.MarkPaid(ctx, paid.WithdrawalID, reference)
+	})
+	if err != nil {
+		return withdrawal, err
+	}
+	return paid, nil
+}
+
+// finishPayout записывает результат выплаты вместе с событием аудита
+func (s *AuctionService) finishPayout(ctx context.Context, result domain.Withdrawal, action domain.AuditAction, mark func(ctx context.Context) error) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := mark(ctx); err != nil {
+			return err
+		}
+		return s.audit(ctx, action, domain.EntityWithdrawal, result.WithdrawalID,
+			withdrawalSnapshot{Status: domain.WithdrawalProcessing, Amount: result.Amount},
+			withdrawalSnapshot{Status: result.Status, Amount: result.Amount,
+				ProviderReference: result.ProviderReference, LastError: result.LastError})
+	})
+}
+
+// ListBalanceTransactions возвращает журнал движений средств, начиная с последних
+func (s *AuctionService) ListBalanceTransactions(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error) {
+	if err := domain.ValidateBalanceTransactionFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.transactions.List(ctx, filter)
+}
+
+func (s *AuctionService) ListWithdrawals(ctx context.Context, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
+	return s.withdrawalRepo.List(ctx, filter)
 }
 
 func (s *AuctionService) PlaceBid(ctx context.Context, bid domain.Bid) (int, error) {
+	bidID, err := s.placeBid(ctx, bid)
+	s.metrics.bidPlaced(err)
+	return bidID, err
+}
+
+func (s *AuctionService) placeBid(ctx context.Context, bid domain.Bid) (int, error) {
 	lot, err := s.lotRepo.GetLotByID(ctx, bid.LotID)
 	if err != nil {
 		if errors.Is(err, domain.ErrLotNotFound) {
@@ -71,32 +487,184 @@ func (s *AuctionService) PlaceBid(ctx context.Context, bid domain.Bid) (int, err
 		return 0, err
 	}
 
-	bid.AuctionID = lot.AuctionID
-	userBalance, err := s.userRepo.GetBalance(ctx, bid.UserID)
+	auction, err := s.auctionRepo.GetByID(ctx, lot.AuctionID)
 	if err != nil {
 		return 0, err
 	}
+	if auction.CancelledAt != nil {
+		return 0, domain.ErrAuctionCancelled
+	}
+
+	bid.AuctionID = lot.AuctionID
+	var topBid *domain.Bid
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.checkAvailable(ctx, bid.UserID, func(balance int64, bids []domain.Bid) error {
+			return domain.ValidateBid(bid, lot, balance, bids)
+		}); err != nil {
+			return err
+		}
 
-	currentBids, err := s.lotRepo.GetUserBids(ctx, bid.UserID)
+		var err error
+		topBid, err = s.bidRepo.GetTopBid(ctx, lot.AuctionID)
+		if err != nil {
+			return err
+		}
+
+		bid.BidID, err = s.lotRepo.PlaceBid(ctx, bid)
+		if err != nil {
+			return err
+		}
+
+		event := domain.BidPlacedEvent{
+			BidID:     bid.BidID,
+			AuctionID: bid.AuctionID,
+			LotID:     bid.LotID,
+			UserID:    bid.UserID,
+			SellerID:  lot.UserID,
+			Price:     bid.Price,
+		}
+		if topBid != nil && topBid.UserID != bid.UserID {
+			event.OutbidUserID = &topBid.UserID
+		}
+		err = s.enqueue(ctx, domain.OutboxBidPlaced, event)
+		if err != nil {
+			return err
+		}
+		if err := s.enqueueBidNotifications(ctx, bid, lot, topBid); err != nil {
+			return err
+		}
+
+		var before any
+		if topBid != nil {
+			before = bidSnapshot(*topBid)
+		}
+		return s.audit(ctx, domain.AuditBidPlaced, domain.EntityBid, bid.BidID, before, bidSnapshot(bid))
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	if err := domain.ValidateBid(bid, *userBalance, currentBids); err != nil {
-		return 0, err
+	return bid.BidID, nil
+}
+
+// checkAvailable блокирует баланс пользователя до конца транзакции и передаёт его в validate
+// вместе со ставками, которые резервируют средства. Вызывается внутри транзакции.
+func (s *AuctionService) checkAvailable(ctx context.Context, userID int, validate func(balance int64, bids []domain.Bid) error) error {
+	balance, err := s.userRepo.GetBalanceForUpdate(ctx, userID)
+	if err != nil {
+		return err
+	}
+	bids, err := s.lotRepo.GetUserBids(ctx, userID)
+	if err != nil {
+		return err
 	}
+	return validate(int64Value(balance), bids)
+}
 
-	return s.lotRepo.PlaceBid(ctx, bid)
+// enqueueBidNotifications записывает уведомления о ставке: продавцу - о первой ставке на лот,
+// предыдущему лидеру - о том, что его ставку перебили
+func (s *AuctionService) enqueueBidNotifications(ctx context.Context, bid domain.Bid, lot domain.Lot, topBid *domain.Bid) error {
+	if topBid == nil {
+		return s.enqueue(ctx, domain.OutboxLotFirstBid, domain.FirstBidEvent{
+			AuctionID: bid.AuctionID,
+			LotID:     bid.LotID,
+			SellerID:  lot.UserID,
+			Price:     bid.Price,
+		})
+	}
+	if bid.Price > topBid.Price && topBid.UserID != bid.UserID {
+		return s.enqueue(ctx, domain.OutboxBidOutbid, domain.OutbidEvent{
+			AuctionID: bid.AuctionID,
+			LotID:     bid.LotID,
+			UserID:    topBid.UserID,
+			Price:     bid.Price,
+		})
+	}
+	return nil
+}
+
+func (s *AuctionService) CancelAuction(ctx context.Context, auctionID int) error {
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Расчёт держит ту же блокировку, поэтому отмена не пересекается с ним
+		auction, err := s.auctionRepo.TryGetForUpdate(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+		if err := domain.ValidateCancellation(auction); err != nil {
+			return err
+		}
+		// У непроданного аукциона нет победителя, рассчитан он или нет - видно по записи расчёта
+		_, err = s.settlementRepo.GetByAuctionID(ctx, auctionID)
+		if err == nil {
+			return domain.ErrAuctionSettled
+		}
+		if !errors.Is(err, domain.ErrSettlementNotFound) {
+			return err
+		}
+
+		if err := s.auctionRepo.Cancel(ctx, auctionID); err != nil {
+			return err
+		}
+
+		cancelled, err := s.auctionRepo.GetByID(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		return s.audit(ctx, domain.AuditAuctionCancelled, domain.EntityAuction, auctionID,
+			auctionSnapshot(auction), auctionSnapshot(cancelled))
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.closing != nil {
+		s.closing.Remove(auctionID)
+	}
+	return nil
+}
+
+func (s *AuctionService) ListShillReviews(ctx context.Context, status domain.ShillReviewStatus, limit, offset int) ([]domain.ShillReview, error) {
+	return s.shillRepo.ListReviews(ctx, status, limit, offset)
+}
+
+func (s *AuctionService) ResolveShillReview(ctx context.Context, reviewID int, status domain.ShillReviewStatus, adminID int) error {
+	if err := domain.ValidateShillResolution(status); err != nil {
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.shillRepo.ResolveReview(ctx, reviewID, status, adminID); err != nil {
+			return err
+		}
+
+		return s.audit(ctx, domain.AuditShillReviewResolved, domain.EntityShillReview, reviewID,
+			shillReviewSnapshot{Status: domain.ShillReviewPending},
+			shillReviewSnapshot{Status: status, ResolvedBy: &adminID})
+	})
+}
+
+func (s *AuctionService) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	return s.auditRepo.List(ctx, filter)
 }
 
 func (s *AuctionService) GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]domain.Auction, error) {
 	return s.auctionRepo.GetCompletedAuctionsWithoutWinner(ctx)
 }
 
+func (s *AuctionService) GetUnsettledClosingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error) {
+	return s.auctionRepo.GetUnsettledClosingBefore(ctx, until)
+}
+
 func (s *AuctionService) GetBidsByAuctionID(ctx context.Context, auctionID int) ([]domain.Bid, error) {
 	return s.bidRepo.GetBidsByAuctionID(ctx, auctionID)
 }
 
+// GetSettlement возвращает итог расчёта аукциона с комиссией и выплатой продавцу
+func (s *AuctionService) GetSettlement(ctx context.Context, auctionID int) (domain.Settlement, error) {
+	return s.settlementRepo.GetByAuctionID(ctx, auctionID)
+}
+
 func (s *AuctionService) DetermineWinner(ctx context.Context, bids []domain.Bid) (int, []int, error) {
 	if len(bids) == 0 {
 		return 0, nil, errors.New("no bids available")
@@ -113,8 +681,10 @@ func (s *AuctionService) DetermineWinner(ctx context.Context, bids []domain.Bid)
 		}
 	}
 
+	seen := map[int]bool{winnerID: true}
 	for _, bid := range bids {
-		if bid.UserID != winnerID {
+		if !seen[bid.UserID] {
+			seen[bid.UserID] = true
 			losers = append(losers, bid.UserID)
 		}
 	}
@@ -122,59 +692,351 @@ func (s *AuctionService) DetermineWinner(ctx context.Context, bids []domain.Bid)
 	return winnerID, losers, nil
 }
 
-func (s *AuctionService) ProcessTransactions(ctx context.Context, auctionID, winnerID int, losers []int) error {
-	winningBid, err := s.bidRepo.GetWinningBid(ctx, auctionID, winnerID)
+// SettleAuction рассчитывает завершённый аукцион в одной транзакции: фиксирует итог,
+// списывает средства победителя, освобождает резерв проигравших и закрывает аукцион.
+// Если лучшему участнику не хватает средств, победителем становится следующий по цене;
+// если оплатить не может никто, аукцион рассчитывается как непроданный.
+// Повторный вызов для рассчитанного аукциона ничего не меняет и возвращает false.
+func (s *AuctionService) SettleAuction(ctx context.Context, auctionID int) (domain.Settlement, bool, error) {
+	var (
+		settlement domain.Settlement
+		created    bool
+	)
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Блокировка строки аукциона закрепляет его за этим обработчиком: остальные экземпляры
+		// сервиса пропускают аукцион, а не ждут окончания расчёта
+		auction, err := s.auctionRepo.TryGetForUpdate(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.settlementRepo.GetByAuctionID(ctx, auctionID)
+		if err == nil {
+			settlement = existing
+			return nil
+		}
+		if !errors.Is(err, domain.ErrSettlementNotFound) {
+			return err
+		}
+		if auction.CancelledAt != nil {
+			return domain.ErrAuctionCancelled
+		}
+		// Время закрытия могло быть перенесено после того, как аукцион попал в очередь
+		if auction.ClosedAt == nil || auction.ClosedAt.After(time.Now()) {
+			return domain.ErrAuctionNotClosed
+		}
+
+		bids, err := s.bidRepo.GetBidsByAuctionID(ctx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		settlement = domain.Settlement{
+			AuctionID: auctionID,
+			SettledAt: time.Now(),
+		}
+		winningBid, defaulted, err := s.chooseWinningBid(ctx, bids)
+		if err != nil {
+			return err
+		}
+		if winningBid != nil {
+			lot, err := s.lotRepo.GetLotByAuctionID(ctx, auctionID)
+			if err != nil {
+				return err
+			}
+			settlement.WinnerID = &winningBid.UserID
+			settlement.WinningBidID = &winningBid.BidID
+			settlement.Price = winningBid.Price
+			settlement.SellerID = &lot.UserID
+			settlement.Fee = s.commission.Fee(winningBid.Price)
+			for _, bid := range bids {
+				if bid.UserID != winningBid.UserID && !slices.Contains(settlement.Losers, bid.UserID) {
+					settlement.Losers = append(settlement.Losers, bid.UserID)
+				}
+			}
+		}
+		if len(defaulted) > 0 {
+			logging.FromContext(ctx).Warn("bidders cannot pay for auction", "auction_id", auctionID, "bidder_ids", defaulted)
+		}
+
+		created, err = s.settlementRepo.Create(ctx, settlement)
+		if err != nil {
+			return err
+		}
+		if !created {
+			settlement, err = s.settlementRepo.GetByAuctionID(ctx, auctionID)
+			return err
+		}
+
+		err = s.enqueue(ctx, domain.OutboxAuctionSettled, domain.AuctionSettledEvent{
+			AuctionID: auctionID,
+			WinnerID:  settlement.WinnerID,
+			Price:     settlement.Price,
+			SettledAt: settlement.SettledAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !settlement.Sold() {
+			return s.audit(ctx, domain.AuditAuctionSettled, domain.EntityAuction, auctionID,
+				settlementSnapshot{}, settlementSnapshot{Defaulted: defaulted})
+		}
+
+		if err := s.balance.DeductBalance(ctx, *settlement.WinnerID, settlement.Price); err != nil {
+			return fmt.Errorf("failed to charge winner %d: %w", *settlement.WinnerID, err)
+		}
+		if err := s.balance.PayoutSeller(ctx, *settlement.SellerID, settlement.SellerPayout()); err != nil {
+			return fmt.Errorf("failed to pay seller %d: %w", *settlement.SellerID, err)
+		}
+		if err := s.transactions.Record(ctx, settlement.Transactions()...); err != nil {
+			return err
+		}
+
+		for _, loserID := range settlement.Losers {
+			losingBid, err := s.bidRepo.GetUserBid(ctx, auctionID, loserID)
+			if err != nil {
+				return err
+			}
+
+			if err := s.balance.RefundBalance(ctx, loserID, losingBid.Price); err != nil {
+				return fmt.Errorf("failed to refund loser %d: %w", loserID, err)
+			}
+		}
+
+		if err := s.auctionRepo.CloseAuction(ctx, auctionID, *settlement.WinnerID); err != nil {
+			return err
+		}
+
+		// Уведомления уходят через outbox только после фиксации расчёта
+		err = s.enqueue(ctx, domain.OutboxAuctionWon, domain.AuctionResultEvent{
+			AuctionID: auctionID,
+			UserID:    *settlement.WinnerID,
+			Price:     settlement.Price,
+		})
+		if err != nil {
+			return err
+		}
+		for _, loserID := range settlement.Losers {
+			err := s.enqueue(ctx, domain.OutboxAuctionLost, domain.AuctionResultEvent{
+				AuctionID: auctionID,
+				UserID:    loserID,
+				Price:     settlement.Price,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.audit(ctx, domain.AuditAuctionSettled, domain.EntityAuction, auctionID,
+			settlementSnapshot{},
+			settlementSnapshot{WinnerID: settlement.WinnerID, Price: settlement.Price, Losers: settlement.Losers, Fee: settlement.Fee, Defaulted: defaulted})
+	})
 	if err != nil {
-		return err
+		return domain.Settlement{}, false, err
 	}
 
-	if err := s.balance.DeductBalance(ctx, winnerID, winningBid.Price); err != nil {
-		return err
+	if created {
+		s.metrics.auctionSettled(settlement)
+	}
+	return settlement, created, nil
+}
+
+// chooseWinningBid выбирает лучшую ставку участника, которому хватает средств на её оплату.
+// Балансы участников блокируются до конца транзакции расчёта, чтобы проверенные средства
+// не ушли на вывод. Возвращает также участников, пропущенных из-за нехватки средств.
+func (s *AuctionService) chooseWinningBid(ctx context.Context, bids []domain.Bid) (*domain.Bid, []int, error) {
+	var defaulted []int
+	for _, bid := range domain.RankBidders(bids) {
+		balance, err := s.userRepo.GetBalanceForUpdate(ctx, bid.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64Value(balance) >= bid.Price {
+			return &bid, defaulted, nil
+		}
+		defaulted = append(defaulted, bid.UserID)
 	}
+	return nil, defaulted, nil
+}
+
+func (s *AuctionService) GetNewAuctions(ctx context.Context) ([]domain.Auction, error) {
+	return s.auctionRepo.GetNewAuctions(ctx)
+}
 
-	for _, loserID := range losers {
-		losingBid, err := s.bidRepo.GetUserBid(ctx, auctionID, loserID)
+// NotifyUsersAboutNewAuctions объявляет новые аукционы одной рассылкой, когда самый старый из
+// них ждёт дольше digestWindow. Аукционы отмечаются объявленными в одной транзакции с записью
+// события в outbox, поэтому каждый попадает ровно в одну рассылку. Отменённые и закрытые
+// к этому моменту аукционы отмечаются, но не объявляются.
+func (s *AuctionService) NotifyUsersAboutNewAuctions(ctx context.Context, digestWindow time.Duration) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		auctions, err := s.auctionRepo.ClaimUnannounced(ctx, now.Add(-digestWindow))
 		if err != nil {
 			return err
 		}
 
-		if err := s.balance.RefundBalance(ctx, loserID, losingBid.Price); err != nil {
-			return err
+		var event domain.AuctionsAnnouncedEvent
+		for _, auction := range auctions {
+			if auction.IsOpen(now) {
+				event.AuctionIDs = append(event.AuctionIDs, auction.AuctionID)
+			}
+		}
+		if len(event.AuctionIDs) == 0 {
+			return nil
 		}
+		slices.Sort(event.AuctionIDs)
+
+		return s.enqueue(ctx, domain.OutboxAuctionsAnnounced, event)
+	})
+}
+
+func (s *AuctionService) ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	return s.notifications.ListInbox(ctx, userID, unreadOnly, limit, offset)
+}
+
+func (s *AuctionService) MarkNotificationRead(ctx context.Context, userID int, notificationID int64) error {
+	return s.notifications.MarkRead(ctx, userID, notificationID)
+}
+
+func (s *AuctionService) UpdateNotificationSettings(ctx context.Context, userID int, settings domain.NotificationSettings) error {
+	if err := domain.ValidateNotificationSettings(settings); err != nil {
+		return err
 	}
+	return s.userRepo.UpdateNotificationSettings(ctx, userID, settings)
+}
 
-	return s.auctionRepo.CloseAuction(ctx, auctionID, winnerID)
+func (s *AuctionService) GetNotificationPreferences(ctx context.Context, userID int) (domain.NotificationPreferences, error) {
+	return s.preferences.Get(ctx, userID)
 }
 
-func (s *AuctionService) NotifyAuctionResults(ctx context.Context, auctionID, winnerID int, losers []int) error {
-	err := s.notify.NotifyUser(ctx, winnerID, fmt.Sprintf("Вы победили в аукционе %d", auctionID))
+// UpdateNotificationPreferences сохраняет настройки уведомлений. Канал webhook для события
+// можно выбрать, только если у пользователя задан адрес webhook.
+func (s *AuctionService) UpdateNotificationPreferences(ctx context.Context, preferences domain.NotificationPreferences) error {
+	user, err := s.userRepo.GetUser(ctx, preferences.UserID)
 	if err != nil {
 		return err
 	}
+	if err := domain.ValidateNotificationPreferences(preferences, user.WebhookURL); err != nil {
+		return err
+	}
+	return s.preferences.Save(ctx, preferences)
+}
+
+// CreateWebhookSubscription создаёт подписку и возвращает её вместе с секретом. Пустой
+// секрет генерируется.
+func (s *AuctionService) CreateWebhookSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	if err := domain.ValidateWebhookSubscription(subscription); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if subscription.Secret == "" {
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			return domain.WebhookSubscription{}, err
+		}
+		subscription.Secret = secret
+	}
+	subscription.Active = true
+	subscription.CreatedAt = time.Now()
 
-	for _, loserID := range losers {
-		err := s.notify.NotifyUser(ctx, loserID, fmt.Sprintf("Вы проиграли в аукционе %d", auctionID))
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription.SubscriptionID, err = s.webhookRepo.CreateSubscription(ctx, subscription)
 		if err != nil {
 			return err
 		}
+		return s.audit(ctx, domain.AuditWebhookCreated, domain.EntityWebhook, subscription.SubscriptionID,
+			nil, webhookSubscriptionSnapshot(subscription))
+	})
+	if err != nil {
+		return domain.WebhookSubscription{}, err
 	}
+	return subscription, nil
+}
 
-	return nil
+func (s *AuctionService) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.webhookRepo.ListSubscriptions(ctx)
 }
 
-func (s *AuctionService) GetNewAuctions(ctx context.Context) ([]domain.Auction, error) {
-	return s.auctionRepo.GetNewAuctions(ctx)
+func (s *AuctionService) DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := s.webhookRepo.GetSubscription(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if err := s.webhookRepo.DeleteSubscription(ctx, subscriptionID); err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditWebhookDeleted, domain.EntityWebhook, subscriptionID,
+			webhookSubscriptionSnapshot(subscription), nil)
+	})
 }
 
-func (s *AuctionService) NotifyUsersAboutNewAuctions(ctx context.Context) error {
-	newAuctions, err := s.auctionRepo.GetNewAuctions(ctx)
-	if err != nil {
-		return err
-	}
+func (s *AuctionService) EnableWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := s.webhookRepo.GetSubscription(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if err := s.webhookRepo.EnableSubscription(ctx, subscriptionID); err != nil {
+			return err
+		}
+		enabled := subscription
+		enabled.Active, enabled.ConsecutiveFailures = true, 0
+		return s.audit(ctx, domain.AuditWebhookEnabled, domain.EntityWebhook, subscriptionID,
+			webhookSubscriptionSnapshot(subscription), webhookSubscriptionSnapshot(enabled))
+	})
+}
+
+func (s *AuctionService) ListWebhookDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	return s.webhookRepo.ListDeliveries(ctx, subscriptionID, limit, offset)
+}
 
-	if len(newAuctions) == 0 {
+// RemindAuctionsEndingSoon записывает напоминания участникам и наблюдателям аукционов,
+// закрывающихся в ближайшие lead. Аукцион отмечается в одной транзакции с записью
+// напоминаний, поэтому каждый участник получает напоминание один раз.
+func (s *AuctionService) RemindAuctionsEndingSoon(ctx context.Context, lead time.Duration) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		auctions, err := s.auctionRepo.ClaimEndingBefore(ctx, time.Now().Add(lead))
+		if err != nil {
+			return err
+		}
+
+		for _, auction := range auctions {
+			bidders, err := s.bidRepo.GetBidders(ctx, auction.AuctionID)
+			if err != nil {
+				return err
+			}
+			watchers, err := s.watchRepo.GetWatchers(ctx, auction.AuctionID)
+			if err != nil {
+				return err
+			}
+
+			recipients := append(bidders, watchers...)
+			slices.Sort(recipients)
+			for _, userID := range slices.Compact(recipients) {
+				err := s.enqueue(ctx, domain.OutboxAuctionEndingSoon, domain.EndingSoonEvent{
+					AuctionID: auction.AuctionID,
+					UserID:    userID,
+					ClosedAt:  *auction.ClosedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
 		return nil
+	})
+}
+
+// WatchAuction подписывает пользователя на напоминания о завершении аукциона
+func (s *AuctionService) WatchAuction(ctx context.Context, userID, auctionID int) error {
+	if _, err := s.auctionRepo.GetByID(ctx, auctionID); err != nil {
+		return err
 	}
+	return s.watchRepo.Watch(ctx, auctionID, userID)
+}
 
-	return s.notify.NotifyAllUsersAboutNewAuctions(ctx, newAuctions)
+func (s *AuctionService) UnwatchAuction(ctx context.Context, userID, auctionID int) error {
+	return s.watchRepo.Unwatch(ctx, auctionID, userID)
 }
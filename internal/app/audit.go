@@ -0,0 +1,164 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/request"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// audit записывает событие журнала аудита. Вызывается внутри транзакции изменения,
+// чтобы событие и изменение фиксировались вместе.
+func (s *AuctionService) audit(ctx context.Context, action domain.AuditAction, entityType string, entityID int, before, after any) error {
+	event := domain.AuditEvent{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		CreatedAt:  time.Now(),
+	}
+
+	if user, ok := auth.UserFromContext(ctx); ok {
+		event.ActorID = &user.ID
+	}
+	info := request.InfoFromContext(ctx)
+	event.RequestID = info.ID
+	event.ClientIP = info.ClientIP
+
+	var err error
+	if event.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if event.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+
+	if err := s.auditRepo.Append(ctx, event); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit snapshot: %w", err)
+	}
+	return b, nil
+}
+
+// Снимки состояния сущностей для полей before/after журнала аудита
+
+type lotAuditSnapshot struct {
+	Title      string     `json:"title"`
+	StartPrice int        `json:"start_price"`
+	Step       int        `json:"step"`
+	SellerID   int        `json:"seller_id"`
+	AuctionID  int        `json:"auction_id"`
+	ClosedAt   *time.Time `json:"closed_at"`
+}
+
+func lotSnapshot(lot domain.Lot) lotAuditSnapshot {
+	return lotAuditSnapshot{
+		Title:      lot.Title,
+		StartPrice: lot.StartPrice,
+		Step:       lot.Step,
+		SellerID:   lot.UserID,
+		AuctionID:  lot.AuctionID,
+		ClosedAt:   lot.ClosedAt,
+	}
+}
+
+type bidAuditSnapshot struct {
+	BidID     int   `json:"bid_id"`
+	UserID    int   `json:"user_id"`
+	LotID     int   `json:"lot_id"`
+	AuctionID int   `json:"auction_id"`
+	Price     int64 `json:"price"`
+}
+
+func bidSnapshot(bid domain.Bid) bidAuditSnapshot {
+	return bidAuditSnapshot{
+		BidID:     bid.BidID,
+		UserID:    bid.UserID,
+		LotID:     bid.LotID,
+		AuctionID: bid.AuctionID,
+		Price:     bid.Price,
+	}
+}
+
+type balanceSnapshot struct {
+	Balance   int64 `json:"balance"`
+	Amount    int64 `json:"amount,omitempty"`
+	PaymentID *int  `json:"payment_id,omitempty"`
+}
+
+type auctionAuditSnapshot struct {
+	ClosedAt    *time.Time `json:"closed_at"`
+	WinnerID    *int       `json:"winner_id"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+}
+
+func auctionSnapshot(auction domain.Auction) auctionAuditSnapshot {
+	return auctionAuditSnapshot{
+		ClosedAt:    auction.ClosedAt,
+		WinnerID:    auction.WinnerID,
+		CancelledAt: auction.CancelledAt,
+	}
+}
+
+type settlementSnapshot struct {
+	WinnerID *int  `json:"winner_id"`
+	Price    int64 `json:"price,omitempty"`
+	Losers   []int `json:"losers,omitempty"`
+	Fee      int64 `json:"fee,omitempty"`
+	// Defaulted - участники с лучшей ставкой, которым не хватило средств на оплату
+	Defaulted []int `json:"defaulted,omitempty"`
+}
+
+type shillReviewSnapshot struct {
+	Status     domain.ShillReviewStatus `json:"status"`
+	ResolvedBy *int                     `json:"resolved_by,omitempty"`
+}
+
+type withdrawalSnapshot struct {
+	Status            domain.WithdrawalStatus `json:"status"`
+	Amount            int64                   `json:"amount"`
+	ResolvedBy        *int                    `json:"resolved_by,omitempty"`
+	ProviderReference string                  `json:"provider_reference,omitempty"`
+	LastError         string                  `json:"last_error,omitempty"`
+}
+
+func int64Value(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+type paymentSnapshot struct {
+	Status domain.PaymentStatus `json:"status"`
+	Amount int64                `json:"amount"`
+}
+
+// webhookSnapshot не содержит секрета подписки: журнал аудита доступен всем администраторам
+type webhookSnapshot struct {
+	URL                 string               `json:"url"`
+	Events              []domain.OutboxTopic `json:"events"`
+	Active              bool                 `json:"active"`
+	ConsecutiveFailures int                  `json:"consecutive_failures,omitempty"`
+}
+
+func webhookSubscriptionSnapshot(subscription domain.WebhookSubscription) webhookSnapshot {
+	return webhookSnapshot{
+		URL:                 subscription.URL,
+		Events:              subscription.Events,
+		Active:              subscription.Active,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/request"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefillBalanceWritesAuditEvent(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 500
+	service := newFakeService(store)
+
+	ctx := auth.ContextWithUser(context.Background(), auth.User{ID: 1})
+	ctx = request.ContextWithInfo(ctx, request.Info{ID: "req-1", ClientIP: "198.51.100.1"})
+
+	require.NoError(t, service.RefillBalance(ctx, 2, 300))
+
+	require.Len(t, store.state.events, 1)
+	event := store.state.events[0]
+	assert.Equal(t, domain.AuditBalanceRefilled, event.Action)
+	assert.Equal(t, domain.EntityUser, event.EntityType)
+	assert.Equal(t, 2, event.EntityID)
+	assert.Equal(t, 1, *event.ActorID)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Equal(t, "198.51.100.1", event.ClientIP)
+	assert.JSONEq(t, `{"balance": 500}`, string(event.Before))
+	assert.JSONEq(t, `{"balance": 800, "amount": 300}`, string(event.After))
+}
+
+func TestAuditFailureRollsBackMutation(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 500
+	store.failures["audit.append"] = errors.New("disk full")
+	service := newFakeService(store)
+
+	err := service.RefillBalance(context.Background(), 2, 300)
+
+	assert.ErrorContains(t, err, "failed to write audit event")
+	assert.Equal(t, int64(500), store.state.balances[2])
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenVerifierRejectsWeakSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{
+		{name: "empty", secret: "", wantErr: "secret is required"},
+		{name: "example", secret: placeholderAuthSecret, wantErr: "must be changed"},
+		{name: "short", secret: "too-short", wantErr: "at least 32 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenVerifier(Auth{Keys: []AuthKey{{ID: "local", Algorithm: "HS256", Secret: tt.secret}}})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := NewTokenVerifier(Auth{Keys: []AuthKey{{ID: "local", Algorithm: "HS256", Secret: strings.Repeat("k", 32)}}})
+	require.NoError(t, err)
+}
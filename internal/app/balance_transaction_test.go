@@ -0,0 +1,57 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceTransactionHistory(t *testing.T) {
+	store := newWithdrawalStore()
+	store.state.balances[3] = 0
+	service := newFakeService(store)
+	ctx := context.Background()
+
+	require.NoError(t, service.RefillBalance(ctx, 2, 500))
+	_, err := service.RequestWithdrawal(ctx, domain.Withdrawal{UserID: 2, Amount: 200, Destination: "card *1234"})
+	require.NoError(t, err)
+	_, err = service.ResolveWithdrawal(ctx, 1, domain.WithdrawalRejected, 1)
+	require.NoError(t, err)
+	require.NoError(t, service.RefillBalance(ctx, 3, 100))
+
+	transactions, err := service.ListBalanceTransactions(ctx, domain.BalanceTransactionFilter{UserID: 2})
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	assert.Equal(t, []domain.BalanceTransactionType{domain.TransactionRelease, domain.TransactionHold, domain.TransactionRefill},
+		[]domain.BalanceTransactionType{transactions[0].Type, transactions[1].Type, transactions[2].Type})
+	assert.Equal(t, []int64{1500, 1300, 1500},
+		[]int64{transactions[0].BalanceAfter, transactions[1].BalanceAfter, transactions[2].BalanceAfter})
+
+	refills, err := service.ListBalanceTransactions(ctx, domain.BalanceTransactionFilter{
+		UserID: 2,
+		Types:  []domain.BalanceTransactionType{domain.TransactionRefill},
+	})
+	require.NoError(t, err)
+	require.Len(t, refills, 1)
+	assert.Equal(t, int64(500), refills[0].Amount)
+
+	page, err := service.ListBalanceTransactions(ctx, domain.BalanceTransactionFilter{UserID: 2, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, domain.TransactionHold, page[0].Type)
+
+	future := time.Now().Add(time.Hour)
+	later, err := service.ListBalanceTransactions(ctx, domain.BalanceTransactionFilter{UserID: 2, From: &future})
+	require.NoError(t, err)
+	assert.Empty(t, later)
+
+	_, err = service.ListBalanceTransactions(ctx, domain.BalanceTransactionFilter{
+		UserID: 2,
+		Types:  []domain.BalanceTransactionType{"bonus"},
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidTransactionType)
+}
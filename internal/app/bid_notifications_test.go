@@ -0,0 +1,141 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/notify"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxEvents возвращает события темы topic из outbox хранилища
+func outboxEvents[T any](t *testing.T, store *fakeStore, topic domain.OutboxTopic) []T {
+	t.Helper()
+	var events []T
+	for _, message := range store.state.outbox {
+		if message.Topic != topic {
+			continue
+		}
+		var event T
+		require.NoError(t, json.Unmarshal(message.Payload, &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestPlaceBidNotifications(t *testing.T) {
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1}
+	store.state.lots[5] = domain.Lot{LotID: 5, AuctionID: 1, UserID: 10, StartPrice: 50, Step: 10}
+	store.state.balances[2] = 10000
+	store.state.balances[3] = 10000
+	service := newFakeService(store)
+
+	_, err := service.PlaceBid(context.Background(), domain.Bid{LotID: 5, UserID: 2, Price: 100})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.FirstBidEvent{{AuctionID: 1, LotID: 5, SellerID: 10, Price: 100}},
+		outboxEvents[domain.FirstBidEvent](t, store, domain.OutboxLotFirstBid))
+	assert.Empty(t, outboxEvents[domain.OutbidEvent](t, store, domain.OutboxBidOutbid))
+
+	_, err = service.PlaceBid(context.Background(), domain.Bid{LotID: 5, UserID: 3, Price: 150})
+	require.NoError(t, err)
+	// Лидер сам поднимает ставку: уведомлять некого
+	_, err = service.PlaceBid(context.Background(), domain.Bid{LotID: 5, UserID: 3, Price: 200})
+	require.NoError(t, err)
+
+	assert.Len(t, outboxEvents[domain.FirstBidEvent](t, store, domain.OutboxLotFirstBid), 1)
+	assert.Equal(t, []domain.OutbidEvent{{AuctionID: 1, LotID: 5, UserID: 2, Price: 150}},
+		outboxEvents[domain.OutbidEvent](t, store, domain.OutboxBidOutbid))
+}
+
+func TestRemindAuctionsEndingSoon(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(5*time.Minute), now.Add(time.Hour)
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1, ClosedAt: &soon}
+	store.state.auctions[2] = domain.Auction{AuctionID: 2, ClosedAt: &later}
+	store.state.auctions[3] = domain.Auction{AuctionID: 3, ClosedAt: &soon, CancelledAt: &now}
+	store.state.bids = []domain.Bid{
+		{BidID: 1, AuctionID: 1, UserID: 2, Price: 100},
+		{BidID: 2, AuctionID: 1, UserID: 3, Price: 150},
+		{BidID: 3, AuctionID: 1, UserID: 2, Price: 200},
+		{BidID: 4, AuctionID: 2, UserID: 5, Price: 100},
+	}
+	store.state.watchers[1] = []int{3, 4}
+	service := newFakeService(store)
+
+	require.NoError(t, service.RemindAuctionsEndingSoon(context.Background(), 15*time.Minute))
+
+	events := outboxEvents[domain.EndingSoonEvent](t, store, domain.OutboxAuctionEndingSoon)
+	require.Len(t, events, 3)
+	for i, userID := range []int{2, 3, 4} {
+		assert.Equal(t, 1, events[i].AuctionID)
+		assert.Equal(t, userID, events[i].UserID)
+		assert.True(t, events[i].ClosedAt.Equal(soon))
+	}
+
+	// Напоминание отправляется один раз
+	require.NoError(t, service.RemindAuctionsEndingSoon(context.Background(), 15*time.Minute))
+	assert.Len(t, outboxEvents[domain.EndingSoonEvent](t, store, domain.OutboxAuctionEndingSoon), 3)
+}
+
+func TestNotificationSinkBidEvents(t *testing.T) {
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	store := newFakeStore()
+	store.state.lots[5] = domain.Lot{LotID: 5, AuctionID: 1, Title: "Часы"}
+	notifier := &fakeNotifyService{}
+	sink := NewNotificationSink(notifier, &fakeLotRepo{store: store})
+	sink.now = func() time.Time { return now }
+
+	handle := func(topic domain.OutboxTopic, event any) {
+		t.Helper()
+		message, err := domain.NewOutboxMessage(topic, event)
+		require.NoError(t, err)
+		require.NoError(t, sink.Handle(context.Background(), message))
+	}
+	handle(domain.OutboxBidOutbid, domain.OutbidEvent{AuctionID: 1, LotID: 5, UserID: 2, Price: 150})
+	handle(domain.OutboxLotFirstBid, domain.FirstBidEvent{AuctionID: 1, LotID: 5, SellerID: 10, Price: 100})
+	handle(domain.OutboxAuctionEndingSoon, domain.EndingSoonEvent{AuctionID: 1, UserID: 3, ClosedAt: now.Add(4*time.Minute + 10*time.Second)})
+	// Аукцион уже закрылся: напоминание опоздало и не отправляется
+	handle(domain.OutboxAuctionEndingSoon, domain.EndingSoonEvent{AuctionID: 1, UserID: 4, ClosedAt: now.Add(-time.Second)})
+
+	lot := notify.TemplateData{AuctionID: 1, LotID: 5, LotTitle: "Часы"}
+	outbid, firstBid, endingSoon := lot, lot, lot
+	outbid.Price, firstBid.Price, endingSoon.MinutesLeft = 150, 100, 5
+	assert.Equal(t, []sentNotification{{event: domain.EventOutbid, data: outbid}}, notifier.sent[2])
+	assert.Equal(t, []sentNotification{{event: domain.EventFirstBid, data: firstBid}}, notifier.sent[10])
+	assert.Equal(t, []sentNotification{{event: domain.EventEndingSoon, data: endingSoon}}, notifier.sent[3])
+	assert.Empty(t, notifier.sent[4])
+}
+
+func TestNotificationSinkAuctionResults(t *testing.T) {
+	store := newFakeStore()
+	store.state.lots[5] = domain.Lot{LotID: 5, AuctionID: 1, Title: "Часы"}
+	notifier := &fakeNotifyService{}
+	sink := NewNotificationSink(notifier, &fakeLotRepo{store: store})
+
+	for topic, userID := range map[domain.OutboxTopic]int{domain.OutboxAuctionWon: 3, domain.OutboxAuctionLost: 2} {
+		message, err := domain.NewOutboxMessage(topic, domain.AuctionResultEvent{AuctionID: 1, UserID: userID, Price: 300})
+		require.NoError(t, err)
+		require.NoError(t, sink.Handle(context.Background(), message))
+	}
+
+	data := notify.TemplateData{AuctionID: 1, LotID: 5, LotTitle: "Часы", Price: 300}
+	assert.Equal(t, []sentNotification{{event: domain.EventAuctionWon, data: data}}, notifier.sent[3])
+	assert.Equal(t, []sentNotification{{event: domain.EventAuctionLost, data: data}}, notifier.sent[2])
+
+	deferred := domain.NotificationDeferredEvent{UserID: 3, Event: domain.EventAuctionWon, Channels: []domain.NotificationChannel{domain.ChannelEmail}}
+	message, err := domain.NewOutboxMessage(domain.OutboxNotificationDeferred, deferred)
+	require.NoError(t, err)
+	require.NoError(t, sink.Handle(context.Background(), message))
+	assert.Equal(t, []domain.NotificationDeferredEvent{deferred}, notifier.deferred)
+
+	// Без лота уведомление не собрать: сообщение останется в outbox для повтора
+	message, err = domain.NewOutboxMessage(domain.OutboxAuctionWon, domain.AuctionResultEvent{AuctionID: 9, UserID: 3})
+	require.NoError(t, err)
+	assert.ErrorIs(t, sink.Handle(context.Background(), message), domain.ErrLotNotFound)
+}
@@ -1,7 +1,9 @@
 package app
 
 import (
+	"auction/internal/domain"
 	"fmt"
+	"math"
 	"os"
 	"time"
 
@@ -9,11 +11,31 @@ import (
 )
 
 type Config struct {
-	Env              string     `toml:"env"`
-	ConnectionString string     `toml:"-"`
-	Postgres         Postgres   `toml:"postgres"`
-	HTTPServer       HTTPServer `toml:"http_server"`
-	GRPCPort         string     `toml:"grpc_port" env:"GRPC_PORT" env-required:"true"`
+	Env              string        `toml:"env"`
+	ConnectionString string        `toml:"-"`
+	Postgres         Postgres      `toml:"postgres"`
+	HTTPServer       HTTPServer    `toml:"http_server"`
+	GRPCPort         string        `toml:"grpc_port" env:"GRPC_PORT" env-required:"true"`
+	ShutdownTimeout  time.Duration `toml:"shutdown_timeout"`
+	TrustedProxies   []string      `toml:"trusted_proxies"`
+	Auth             Auth          `toml:"auth"`
+	Shill            Shill         `toml:"shill"`
+	Commission       Commission    `toml:"commission"`
+	Payments         Payments      `toml:"payments"`
+	RateLimit        RateLimit     `toml:"rate_limit"`
+	Outbox           Outbox        `toml:"outbox"`
+	Scheduler        Scheduler     `toml:"scheduler"`
+	Tracing          Tracing       `toml:"tracing"`
+	Notify           Notify        `toml:"notify"`
+	Webhooks         Webhooks      `toml:"webhooks"`
+}
+
+// shutdownTimeout - сколько ждать завершения запросов и фоновой работы при остановке
+func (c Config) shutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return c.ShutdownTimeout
 }
 
 type Postgres struct {
@@ -35,6 +57,297 @@ type HTTPServer struct {
 	Port        string        `toml:"port"`
 }
 
+type Auth struct {
+	Issuer   string    `toml:"issuer"`
+	Audience string    `toml:"audience"`
+	Keys     []AuthKey `toml:"keys"`
+}
+
+// AuthKey - ключ проверки подписи JWT: secret для HS256 или public_key_file для RS256
+type AuthKey struct {
+	ID            string `toml:"kid"`
+	Algorithm     string `toml:"algorithm"`
+	Secret        string `toml:"secret"`
+	PublicKeyFile string `toml:"public_key_file"`
+}
+
+// Shill - пороги обнаружения подставных ставок
+type Shill struct {
+	MinBids           int           `toml:"min_bids"`
+	SingleSellerShare float64       `toml:"single_seller_share"`
+	JustBelowShare    float64       `toml:"just_below_share"`
+	NewAccountAge     time.Duration `toml:"new_account_age"`
+}
+
+func (s Shill) Rules() domain.ShillRules {
+	rules := domain.ShillRules{
+		MinBids:           5,
+		SingleSellerShare: 1,
+		JustBelowShare:    0.8,
+		NewAccountAge:     72 * time.Hour,
+	}
+	if s.MinBids > 0 {
+		rules.MinBids = s.MinBids
+	}
+	if s.SingleSellerShare > 0 {
+		rules.SingleSellerShare = s.SingleSellerShare
+	}
+	if s.JustBelowShare > 0 {
+		rules.JustBelowShare = s.JustBelowShare
+	}
+	if s.NewAccountAge > 0 {
+		rules.NewAccountAge = s.NewAccountAge
+	}
+	return rules
+}
+
+// Commission - комиссия площадки с продажи: percent процентов от цены плюс fixed_fee.
+// Тиры из tiers заменяют базовые ставки для продаж от from_price.
+type Commission struct {
+	Percent  float64          `toml:"percent"`
+	FixedFee int64            `toml:"fixed_fee"`
+	Tiers    []CommissionTier `toml:"tiers"`
+}
+
+type CommissionTier struct {
+	FromPrice int64   `toml:"from_price"`
+	Percent   float64 `toml:"percent"`
+	FixedFee  int64   `toml:"fixed_fee"`
+}
+
+// Rules переводит проценты в базисные пункты и проверяет тиры
+func (c Commission) Rules() (domain.Commission, error) {
+	commission := domain.Commission{Tiers: []domain.CommissionTier{
+		{BasisPoints: basisPoints(c.Percent), FixedFee: c.FixedFee},
+	}}
+	for _, tier := range c.Tiers {
+		commission.Tiers = append(commission.Tiers, domain.CommissionTier{
+			FromPrice:   tier.FromPrice,
+			BasisPoints: basisPoints(tier.Percent),
+			FixedFee:    tier.FixedFee,
+		})
+	}
+	if err := domain.ValidateCommission(commission); err != nil {
+		return domain.Commission{}, err
+	}
+	return commission, nil
+}
+
+func basisPoints(percent float64) int64 {
+	return int64(math.Round(percent * 100))
+}
+
+// Payments - внешние платёжные провайдеры. Провайдеры local ничего не переводят
+// и подходят только для разработки, поэтому включаются только вместе с AllowLocal.
+type Payments struct {
+	// Provider принимает оплату пополнений баланса
+	Provider       string `toml:"provider"`
+	PayoutProvider string `toml:"payout_provider"`
+	// AllowLocal разрешает провайдеры local
+	AllowLocal bool `toml:"allow_local"`
+	// CallbackSecret - секрет подписи уведомлений провайдера о результате оплаты
+	CallbackSecret string `toml:"callback_secret"`
+	// PublicURL - внешний адрес REST-шлюза для страницы оплаты и уведомлений провайдера
+	PublicURL string `toml:"public_url"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (p Payments) WithDefaults() Payments {
+	if p.PublicURL == "" {
+		p.PublicURL = "http://localhost:8080"
+	}
+	return p
+}
+
+// RateLimit - ограничения частоты запросов. Ключ Methods - имя RPC-метода, например PlaceBid.
+type RateLimit struct {
+	Methods map[string]MethodRateLimit `toml:"methods"`
+}
+
+// MethodRateLimit - token bucket на пользователя и на IP: *_rate запросов в секунду, *_burst - запас
+type MethodRateLimit struct {
+	UserRate  float64 `toml:"user_rate"`
+	UserBurst int     `toml:"user_burst"`
+	IPRate    float64 `toml:"ip_rate"`
+	IPBurst   int     `toml:"ip_burst"`
+}
+
+// Outbox - параметры доставки событий из outbox
+type Outbox struct {
+	Interval    time.Duration `toml:"interval"`
+	BatchSize   int           `toml:"batch_size"`
+	Lease       time.Duration `toml:"lease"`
+	BaseBackoff time.Duration `toml:"base_backoff"`
+	MaxBackoff  time.Duration `toml:"max_backoff"`
+	MaxAttempts int           `toml:"max_attempts"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (o Outbox) WithDefaults() Outbox {
+	if o.Interval <= 0 {
+		o.Interval = 5 * time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.Lease <= 0 {
+		o.Lease = time.Minute
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = 10 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Hour
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 20
+	}
+	return o
+}
+
+// Webhooks - доставка событий внешним подписчикам. Неудачные попытки повторяются
+// с экспоненциальной задержкой до max_attempts раз, а после disable_after неудач подряд
+// подписка отключается.
+type Webhooks struct {
+	Interval     time.Duration `toml:"interval"`
+	BatchSize    int           `toml:"batch_size"`
+	Lease        time.Duration `toml:"lease"`
+	Timeout      time.Duration `toml:"timeout"`
+	BaseBackoff  time.Duration `toml:"base_backoff"`
+	MaxBackoff   time.Duration `toml:"max_backoff"`
+	MaxAttempts  int           `toml:"max_attempts"`
+	DisableAfter int           `toml:"disable_after"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (w Webhooks) WithDefaults() Webhooks {
+	if w.Interval <= 0 {
+		w.Interval = 5 * time.Second
+	}
+	if w.BatchSize <= 0 {
+		w.BatchSize = 100
+	}
+	if w.Lease <= 0 {
+		w.Lease = time.Minute
+	}
+	if w.Timeout <= 0 {
+		w.Timeout = 10 * time.Second
+	}
+	if w.BaseBackoff <= 0 {
+		w.BaseBackoff = 10 * time.Second
+	}
+	if w.MaxBackoff <= 0 {
+		w.MaxBackoff = time.Hour
+	}
+	if w.MaxAttempts <= 0 {
+		w.MaxAttempts = 10
+	}
+	if w.DisableAfter <= 0 {
+		w.DisableAfter = 20
+	}
+	return w
+}
+
+// Scheduler - параметры закрытия аукционов: очередь в памяти держит аукционы, закрывающиеся
+// в пределах horizon, а раз в sweep_interval она сверяется с базой. Расчёты выполняют
+// concurrency обработчиков, каждый расчёт ограничен settlement_timeout. Новые аукционы
+// объявляются одной рассылкой, когда самый старый из них ждёт дольше digest_window.
+// Напоминание участникам отправляется, когда до закрытия остаётся ending_soon.
+type Scheduler struct {
+	SweepInterval     time.Duration `toml:"sweep_interval"`
+	Horizon           time.Duration `toml:"horizon"`
+	Concurrency       int           `toml:"concurrency"`
+	QueueSize         int           `toml:"queue_size"`
+	SettlementTimeout time.Duration `toml:"settlement_timeout"`
+	DigestWindow      time.Duration `toml:"digest_window"`
+	EndingSoon        time.Duration `toml:"ending_soon"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (s Scheduler) WithDefaults() Scheduler {
+	if s.SweepInterval <= 0 {
+		s.SweepInterval = time.Minute
+	}
+	if s.Horizon <= 0 {
+		s.Horizon = time.Hour
+	}
+	if s.Concurrency <= 0 {
+		s.Concurrency = 4
+	}
+	if s.QueueSize <= 0 {
+		s.QueueSize = 1000
+	}
+	if s.SettlementTimeout <= 0 {
+		s.SettlementTimeout = 30 * time.Second
+	}
+	if s.DigestWindow <= 0 {
+		s.DigestWindow = 15 * time.Minute
+	}
+	if s.EndingSoon <= 0 {
+		s.EndingSoon = 15 * time.Minute
+	}
+	return s
+}
+
+// Tracing - экспорт трассировок: exporter = none, stdout или otlp. Для otlp span'ы
+// отправляются по gRPC на endpoint, insecure отключает TLS. sample_ratio - доля
+// сохраняемых трассировок, начатых сервисом.
+type Tracing struct {
+	Exporter    string  `toml:"exporter"`
+	Endpoint    string  `toml:"endpoint"`
+	Insecure    bool    `toml:"insecure"`
+	ServiceName string  `toml:"service_name"`
+	SampleRatio float64 `toml:"sample_ratio"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (t Tracing) WithDefaults() Tracing {
+	if t.Endpoint == "" {
+		t.Endpoint = "localhost:4317"
+	}
+	if t.ServiceName == "" {
+		t.ServiceName = "auction"
+	}
+	if t.SampleRatio <= 0 {
+		t.SampleRatio = 1
+	}
+	return t
+}
+
+// Notify - каналы доставки уведомлений. Письма отправляются, только если задан smtp.host;
+// лента в приложении и webhook доступны всегда. BaseURL - адрес сайта для ссылок в уведомлениях.
+type Notify struct {
+	BaseURL        string        `toml:"base_url"`
+	SMTP           SMTP          `toml:"smtp"`
+	WebhookTimeout time.Duration `toml:"webhook_timeout"`
+}
+
+type SMTP struct {
+	Host     string        `toml:"host"`
+	Port     int           `toml:"port"`
+	Username string        `toml:"username"`
+	Password string        `toml:"password"`
+	From     string        `toml:"from"`
+	Timeout  time.Duration `toml:"timeout"`
+}
+
+// WithDefaults заполняет незаданные параметры значениями по умолчанию
+func (n Notify) WithDefaults() Notify {
+	if n.SMTP.Port <= 0 {
+		n.SMTP.Port = 587
+	}
+	if n.SMTP.Timeout <= 0 {
+		n.SMTP.Timeout = 10 * time.Second
+	}
+	if n.WebhookTimeout <= 0 {
+		n.WebhookTimeout = 10 * time.Second
+	}
+	if n.BaseURL == "" {
+		n.BaseURL = "http://localhost:8080"
+	}
+	return n
+}
+
 func MustLoad() (*Config, error) {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -0,0 +1,1051 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/notify"
+	"auction/internal/infrastructure/payment"
+	"auction/internal/infrastructure/repo"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fakeState - содержимое хранилища, которое откатывается вместе с транзакцией
+type fakeState struct {
+	balances    map[int]int64
+	auctions    map[int]domain.Auction
+	lots        map[int]domain.Lot
+	bids        []domain.Bid
+	watchers    map[int][]int
+	preferences map[int]domain.NotificationPreferences
+	settlements map[int]domain.Settlement
+	events      []domain.AuditEvent
+	outbox      []domain.OutboxMessage
+	webhooks    map[int]domain.WebhookSubscription
+	deliveries  []domain.WebhookDelivery
+	ledger      []domain.BalanceTransaction
+	withdrawals map[int]domain.Withdrawal
+	payments    map[int]domain.Payment
+}
+
+func (s fakeState) clone() fakeState {
+	return fakeState{
+		balances:    maps.Clone(s.balances),
+		auctions:    maps.Clone(s.auctions),
+		lots:        maps.Clone(s.lots),
+		bids:        slices.Clone(s.bids),
+		watchers:    maps.Clone(s.watchers),
+		preferences: maps.Clone(s.preferences),
+		settlements: maps.Clone(s.settlements),
+		events:      slices.Clone(s.events),
+		outbox:      slices.Clone(s.outbox),
+		webhooks:    maps.Clone(s.webhooks),
+		deliveries:  slices.Clone(s.deliveries),
+		ledger:      slices.Clone(s.ledger),
+		withdrawals: maps.Clone(s.withdrawals),
+		payments:    maps.Clone(s.payments),
+	}
+}
+
+// fakeStore - хранилище в памяти для тестов сервиса. Ошибки шагов задаются в failures.
+type fakeStore struct {
+	mu       sync.Mutex
+	state    fakeState
+	failures map[string]error
+	// webhookURLs - адреса webhook из настроек уведомлений пользователей
+	webhookURLs map[int]string
+	// txMu выполняет транзакции по очереди: откат восстанавливает всё состояние целиком
+	// и не должен затирать изменения параллельной транзакции
+	txMu sync.Mutex
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		state: fakeState{
+			balances:    map[int]int64{},
+			auctions:    map[int]domain.Auction{},
+			lots:        map[int]domain.Lot{},
+			watchers:    map[int][]int{},
+			preferences: map[int]domain.NotificationPreferences{},
+			settlements: map[int]domain.Settlement{},
+			webhooks:    map[int]domain.WebhookSubscription{},
+			withdrawals: map[int]domain.Withdrawal{},
+			payments:    map[int]domain.Payment{},
+		},
+		failures:    map[string]error{},
+		webhookURLs: map[int]string{},
+	}
+}
+
+func (s *fakeStore) fail(step string) error {
+	return s.failures[step]
+}
+
+func (s *fakeStore) repositories() Repositories {
+	return Repositories{
+		Lots:         &fakeLotRepo{store: s},
+		Users:        &fakeUserRepo{store: s},
+		Auctions:     &fakeAuctionRepo{store: s},
+		Bids:         &fakeBidRepo{store: s},
+		Audit:        &fakeAuditRepo{store: s},
+		Settlements:  &fakeSettlementRepo{store: s},
+		Outbox:       &fakeOutboxRepo{store: s},
+		Watches:      &fakeWatchRepo{store: s},
+		Preferences:  &fakePreferencesRepo{store: s},
+		Webhooks:     &fakeWebhookRepo{store: s},
+		Transactions: &fakeTransactionRepo{store: s},
+		Withdrawals:  &fakeWithdrawalRepo{store: s},
+		Payments:     &fakePaymentRepo{store: s},
+		Transactor:   &fakeTransactor{store: s},
+	}
+}
+
+// fakeTransactor восстанавливает состояние хранилища, если функция вернула ошибку
+type fakeTransactor struct {
+	store *fakeStore
+}
+
+// fakeTxKey отмечает контекст, выполняющийся внутри транзакции
+type fakeTxKey struct{}
+
+func (t *fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.store.txMu.Lock()
+	defer t.store.txMu.Unlock()
+
+	t.store.mu.Lock()
+	snapshot := t.store.state.clone()
+	t.store.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, true)); err != nil {
+		t.store.mu.Lock()
+		t.store.state = snapshot
+		t.store.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type fakeUserRepo struct {
+	repo.UserRepository
+	store *fakeStore
+}
+
+func (r *fakeUserRepo) GetUser(_ context.Context, userID int) (domain.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return domain.User{UserID: userID, WebhookURL: r.store.webhookURLs[userID]}, nil
+}
+
+func (r *fakeUserRepo) Debit(_ context.Context, userID int, amount int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("balance.debit"); err != nil {
+		return err
+	}
+	if r.store.state.balances[userID] < amount {
+		return domain.ErrInsufficientFunds
+	}
+	r.store.state.balances[userID] -= amount
+	return nil
+}
+
+func (r *fakeUserRepo) Credit(_ context.Context, userID int, amount int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("balance.credit"); err != nil {
+		return err
+	}
+	if _, ok := r.store.state.balances[userID]; !ok {
+		return domain.ErrUserNotFound
+	}
+	r.store.state.balances[userID] += amount
+	return nil
+}
+
+func (r *fakeUserRepo) GetBalance(_ context.Context, userID int) (*int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	balance, ok := r.store.state.balances[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return &balance, nil
+}
+
+// GetBalanceForUpdate проверяет, что блокировка берётся внутри транзакции: вне её
+// блокировка строки сразу снимается и не защищает проверку остатка
+func (r *fakeUserRepo) GetBalanceForUpdate(ctx context.Context, userID int) (*int64, error) {
+	if ctx.Value(fakeTxKey{}) == nil {
+		return nil, errors.New("balance locked outside of a transaction")
+	}
+	return r.GetBalance(ctx, userID)
+}
+
+type fakeAuctionRepo struct {
+	repo.AuctionRepository
+	store *fakeStore
+}
+
+func (r *fakeAuctionRepo) GetByID(_ context.Context, auctionID int) (domain.Auction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	auction, ok := r.store.state.auctions[auctionID]
+	if !ok {
+		return domain.Auction{}, domain.ErrAuctionNotFound
+	}
+	return auction, nil
+}
+
+func (r *fakeAuctionRepo) TryGetForUpdate(ctx context.Context, auctionID int) (domain.Auction, error) {
+	r.store.mu.Lock()
+	busy := r.store.fail("auction.lock")
+	r.store.mu.Unlock()
+	if busy != nil {
+		return domain.Auction{}, busy
+	}
+	return r.GetByID(ctx, auctionID)
+}
+
+func (r *fakeAuctionRepo) Cancel(_ context.Context, auctionID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	auction := r.store.state.auctions[auctionID]
+	if auction.WinnerID != nil || auction.CancelledAt != nil {
+		return domain.ErrAuctionSettled
+	}
+	now := time.Now()
+	auction.CancelledAt = &now
+	r.store.state.auctions[auctionID] = auction
+	return nil
+}
+
+func (r *fakeAuctionRepo) GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]domain.Auction, error) {
+	return r.GetUnsettledClosingBefore(ctx, time.Now())
+}
+
+func (r *fakeAuctionRepo) GetUnsettledClosingBefore(_ context.Context, until time.Time) ([]domain.Auction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var auctions []domain.Auction
+	for _, auction := range r.store.state.auctions {
+		_, settled := r.store.state.settlements[auction.AuctionID]
+		if auction.ClosedAt != nil && !auction.ClosedAt.After(until) &&
+			auction.WinnerID == nil && auction.CancelledAt == nil && !settled {
+			auctions = append(auctions, auction)
+		}
+	}
+	sort.Slice(auctions, func(i, j int) bool { return auctions[i].ClosedAt.Before(*auctions[j].ClosedAt) })
+	return auctions, nil
+}
+
+func (r *fakeAuctionRepo) CloseAuction(_ context.Context, auctionID, winnerID int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("auction.close"); err != nil {
+		return err
+	}
+	auction := r.store.state.auctions[auctionID]
+	auction.WinnerID = &winnerID
+	r.store.state.auctions[auctionID] = auction
+	return nil
+}
+
+func (r *fakeAuctionRepo) ClaimUnannounced(_ context.Context, createdBefore time.Time) ([]domain.Auction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var pending []int
+	due := false
+	for id, auction := range r.store.state.auctions {
+		if auction.AnnouncedAt == nil {
+			pending = append(pending, id)
+			due = due || !auction.CreatedAt.After(createdBefore)
+		}
+	}
+	if !due {
+		return nil, nil
+	}
+
+	now := time.Now()
+	claimed := make([]domain.Auction, 0, len(pending))
+	for _, id := range pending {
+		auction := r.store.state.auctions[id]
+		auction.AnnouncedAt = &now
+		r.store.state.auctions[id] = auction
+		claimed = append(claimed, auction)
+	}
+	return claimed, nil
+}
+
+func (r *fakeAuctionRepo) ClaimEndingBefore(_ context.Context, until time.Time) ([]domain.Auction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	now := time.Now()
+	var claimed []domain.Auction
+	for id, auction := range r.store.state.auctions {
+		if auction.RemindedAt != nil || auction.CancelledAt != nil || auction.WinnerID != nil ||
+			auction.ClosedAt == nil || !auction.ClosedAt.After(now) || auction.ClosedAt.After(until) {
+			continue
+		}
+		auction.RemindedAt = &now
+		r.store.state.auctions[id] = auction
+		claimed = append(claimed, auction)
+	}
+	return claimed, nil
+}
+
+type fakeLotRepo struct {
+	repo.LotRepository
+	store *fakeStore
+}
+
+func (r *fakeLotRepo) GetLotByID(_ context.Context, id int) (domain.Lot, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	lot, ok := r.store.state.lots[id]
+	if !ok {
+		return domain.Lot{}, domain.ErrLotNotFound
+	}
+	return lot, nil
+}
+
+func (r *fakeLotRepo) GetLotByAuctionID(_ context.Context, auctionID int) (domain.Lot, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, lot := range r.store.state.lots {
+		if lot.AuctionID == auctionID {
+			return lot, nil
+		}
+	}
+	return domain.Lot{}, domain.ErrLotNotFound
+}
+
+func (r *fakeLotRepo) GetUserBids(_ context.Context, userID int) ([]domain.Bid, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var bids []domain.Bid
+	for _, bid := range r.store.state.bids {
+		if bid.UserID == userID {
+			bids = append(bids, bid)
+		}
+	}
+	return bids, nil
+}
+
+func (r *fakeLotRepo) PlaceBid(_ context.Context, bid domain.Bid) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	bid.BidID = len(r.store.state.bids) + 1
+	bid.CreatedAt = time.Now()
+	r.store.state.bids = append(r.store.state.bids, bid)
+	return bid.BidID, nil
+}
+
+type fakePreferencesRepo struct {
+	repo.NotificationPreferencesRepository
+	store *fakeStore
+}
+
+func (r *fakePreferencesRepo) Get(_ context.Context, userID int) (domain.NotificationPreferences, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if preferences, ok := r.store.state.preferences[userID]; ok {
+		return preferences, nil
+	}
+	return domain.NotificationPreferences{UserID: userID}, nil
+}
+
+func (r *fakePreferencesRepo) Save(_ context.Context, preferences domain.NotificationPreferences) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.state.preferences[preferences.UserID] = preferences
+	return nil
+}
+
+type fakeWatchRepo struct {
+	repo.WatchRepository
+	store *fakeStore
+}
+
+func (r *fakeWatchRepo) GetWatchers(_ context.Context, auctionID int) ([]int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return slices.Clone(r.store.state.watchers[auctionID]), nil
+}
+
+type fakeBidRepo struct {
+	repo.BidRepository
+	store *fakeStore
+}
+
+func (r *fakeBidRepo) GetBidsByAuctionID(_ context.Context, auctionID int) ([]domain.Bid, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var bids []domain.Bid
+	for _, bid := range r.store.state.bids {
+		if bid.AuctionID == auctionID {
+			bids = append(bids, bid)
+		}
+	}
+	return bids, nil
+}
+
+func (r *fakeBidRepo) GetWinningBid(ctx context.Context, auctionID, winnerID int) (domain.Bid, error) {
+	return r.GetUserBid(ctx, auctionID, winnerID)
+}
+
+func (r *fakeBidRepo) GetUserBid(ctx context.Context, auctionID, userID int) (domain.Bid, error) {
+	bids, _ := r.GetBidsByAuctionID(ctx, auctionID)
+	var best domain.Bid
+	for _, bid := range bids {
+		if bid.UserID == userID && bid.Price > best.Price {
+			best = bid
+		}
+	}
+	return best, nil
+}
+
+func (r *fakeBidRepo) GetTopBid(ctx context.Context, auctionID int) (*domain.Bid, error) {
+	bids, _ := r.GetBidsByAuctionID(ctx, auctionID)
+	var top *domain.Bid
+	for i := range bids {
+		if top == nil || bids[i].Price > top.Price {
+			top = &bids[i]
+		}
+	}
+	return top, nil
+}
+
+func (r *fakeBidRepo) GetBidders(ctx context.Context, auctionID int) ([]int, error) {
+	bids, _ := r.GetBidsByAuctionID(ctx, auctionID)
+	var userIDs []int
+	for _, bid := range bids {
+		if !slices.Contains(userIDs, bid.UserID) {
+			userIDs = append(userIDs, bid.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+type fakeSettlementRepo struct {
+	store *fakeStore
+}
+
+func (r *fakeSettlementRepo) Create(_ context.Context, settlement domain.Settlement) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("settlement.create"); err != nil {
+		return false, err
+	}
+	if _, ok := r.store.state.settlements[settlement.AuctionID]; ok {
+		return false, nil
+	}
+	r.store.state.settlements[settlement.AuctionID] = settlement
+	return true, nil
+}
+
+func (r *fakeSettlementRepo) GetByAuctionID(_ context.Context, auctionID int) (domain.Settlement, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	settlement, ok := r.store.state.settlements[auctionID]
+	if !ok {
+		return domain.Settlement{}, domain.ErrSettlementNotFound
+	}
+	return settlement, nil
+}
+
+// auditActions возвращает действия событий аудита по порядку записи
+func auditActions(events []domain.AuditEvent) []domain.AuditAction {
+	actions := make([]domain.AuditAction, 0, len(events))
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+type fakeAuditRepo struct {
+	store *fakeStore
+}
+
+func (r *fakeAuditRepo) Append(_ context.Context, event domain.AuditEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("audit.append"); err != nil {
+		return err
+	}
+	r.store.state.events = append(r.store.state.events, event)
+	return nil
+}
+
+func (r *fakeAuditRepo) List(_ context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var events []domain.AuditEvent
+	for _, event := range r.store.state.events {
+		if filter.EntityType != "" && event.EntityType != filter.EntityType {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].CreatedAt.After(events[j].CreatedAt) })
+	return events, nil
+}
+
+type fakeOutboxRepo struct {
+	store *fakeStore
+}
+
+func (r *fakeOutboxRepo) Enqueue(_ context.Context, messages ...domain.OutboxMessage) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("outbox.enqueue"); err != nil {
+		return err
+	}
+	for _, message := range messages {
+		message.ID = int64(len(r.store.state.outbox) + 1)
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = time.Now()
+		}
+		r.store.state.outbox = append(r.store.state.outbox, message)
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) Claim(_ context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	now := time.Now()
+	var claimed []domain.OutboxMessage
+	for i, message := range r.store.state.outbox {
+		if len(claimed) == limit {
+			break
+		}
+		if message.DeliveredAt != nil || message.FailedAt != nil || message.NextAttemptAt.After(now) {
+			continue
+		}
+		r.store.state.outbox[i].NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, r.store.state.outbox[i])
+	}
+	return claimed, nil
+}
+
+func (r *fakeOutboxRepo) update(id int64, fn func(message *domain.OutboxMessage)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for i := range r.store.state.outbox {
+		if r.store.state.outbox[i].ID == id {
+			r.store.state.outbox[i].Attempts++
+			fn(&r.store.state.outbox[i])
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkDelivered(_ context.Context, id int64) error {
+	return r.update(id, func(message *domain.OutboxMessage) {
+		now := time.Now()
+		message.DeliveredAt = &now
+	})
+}
+
+func (r *fakeOutboxRepo) MarkFailed(_ context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	return r.update(id, func(message *domain.OutboxMessage) {
+		message.LastError = lastError
+		message.NextAttemptAt = nextAttemptAt
+	})
+}
+
+func (r *fakeOutboxRepo) MarkDead(_ context.Context, id int64, lastError string) error {
+	return r.update(id, func(message *domain.OutboxMessage) {
+		now := time.Now()
+		message.LastError = lastError
+		message.FailedAt = &now
+	})
+}
+
+type fakeWebhookRepo struct {
+	repo.WebhookRepository
+	store *fakeStore
+}
+
+func (r *fakeWebhookRepo) CreateSubscription(_ context.Context, subscription domain.WebhookSubscription) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	subscription.SubscriptionID = len(r.store.state.webhooks) + 1
+	r.store.state.webhooks[subscription.SubscriptionID] = subscription
+	return subscription.SubscriptionID, nil
+}
+
+func (r *fakeWebhookRepo) ListDeliveries(_ context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.state.webhooks[subscriptionID]; !ok {
+		return nil, domain.ErrWebhookSubscriptionNotFound
+	}
+	var deliveries []domain.WebhookDelivery
+	for _, delivery := range slices.Backward(r.store.state.deliveries) {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	deliveries = deliveries[min(offset, len(deliveries)):]
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func (r *fakeWebhookRepo) GetSubscription(_ context.Context, id int) (domain.WebhookSubscription, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	subscription, ok := r.store.state.webhooks[id]
+	if !ok {
+		return domain.WebhookSubscription{}, domain.ErrWebhookSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (r *fakeWebhookRepo) DeleteSubscription(_ context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.state.webhooks[id]; !ok {
+		return domain.ErrWebhookSubscriptionNotFound
+	}
+	delete(r.store.state.webhooks, id)
+	return nil
+}
+
+func (r *fakeWebhookRepo) EnableSubscription(_ context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	subscription, ok := r.store.state.webhooks[id]
+	if !ok {
+		return domain.ErrWebhookSubscriptionNotFound
+	}
+	subscription.Active, subscription.ConsecutiveFailures, subscription.DisabledAt = true, 0, nil
+	r.store.state.webhooks[id] = subscription
+	return nil
+}
+
+func (r *fakeWebhookRepo) GetActiveSubscriptions(_ context.Context, event domain.OutboxTopic) ([]domain.WebhookSubscription, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var subscriptions []domain.WebhookSubscription
+	for _, id := range slices.Sorted(maps.Keys(r.store.state.webhooks)) {
+		subscription := r.store.state.webhooks[id]
+		if subscription.Active && slices.Contains(subscription.Events, event) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *fakeWebhookRepo) EnqueueDeliveries(_ context.Context, deliveries ...domain.WebhookDelivery) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, delivery := range deliveries {
+		duplicate := slices.ContainsFunc(r.store.state.deliveries, func(d domain.WebhookDelivery) bool {
+			return d.SubscriptionID == delivery.SubscriptionID && d.OutboxMessageID == delivery.OutboxMessageID
+		})
+		if duplicate {
+			continue
+		}
+		delivery.DeliveryID = int64(len(r.store.state.deliveries) + 1)
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.NextAttemptAt = time.Now()
+		r.store.state.deliveries = append(r.store.state.deliveries, delivery)
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepo) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	now := time.Now()
+	var claimed []domain.WebhookDelivery
+	for i, delivery := range r.store.state.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		subscription := r.store.state.webhooks[delivery.SubscriptionID]
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) || !subscription.Active {
+			continue
+		}
+		r.store.state.deliveries[i].NextAttemptAt = now.Add(lease)
+		delivery.Subscription = &subscription
+		claimed = append(claimed, delivery)
+	}
+	return claimed, nil
+}
+
+func (r *fakeWebhookRepo) MarkDelivered(_ context.Context, id int64, responseStatus int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	delivery := &r.store.state.deliveries[id-1]
+	now := time.Now()
+	delivery.Status, delivery.ResponseStatus, delivery.DeliveredAt = domain.WebhookDeliveryDelivered, responseStatus, &now
+	delivery.Attempts++
+
+	subscription := r.store.state.webhooks[delivery.SubscriptionID]
+	subscription.ConsecutiveFailures = 0
+	r.store.state.webhooks[delivery.SubscriptionID] = subscription
+	return nil
+}
+
+func (r *fakeWebhookRepo) MarkFailed(_ context.Context, id int64, responseStatus int, lastError string, nextAttemptAt *time.Time, disableAfter int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	delivery := &r.store.state.deliveries[id-1]
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.LastError = responseStatus, lastError
+	if nextAttemptAt != nil {
+		delivery.NextAttemptAt = *nextAttemptAt
+	} else {
+		delivery.Status = domain.WebhookDeliveryFailed
+	}
+
+	subscription := r.store.state.webhooks[delivery.SubscriptionID]
+	subscription.ConsecutiveFailures++
+	if subscription.Active && subscription.ConsecutiveFailures >= disableAfter {
+		now := time.Now()
+		subscription.Active, subscription.DisabledAt = false, &now
+	}
+	r.store.state.webhooks[delivery.SubscriptionID] = subscription
+	return subscription.ConsecutiveFailures, nil
+}
+
+// fakeBalanceService работает с балансами хранилища, как payment.BalanceService с базой
+type fakeBalanceService struct {
+	store *fakeStore
+	users *fakeUserRepo
+}
+
+func (b *fakeBalanceService) DeductBalance(ctx context.Context, userID int, amount int64) error {
+	return b.users.Debit(ctx, userID, amount)
+}
+
+func (b *fakeBalanceService) RefundBalance(_ context.Context, _ int, _ int64) error {
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+	return b.store.fail("balance.refund")
+}
+
+func (b *fakeBalanceService) PayoutSeller(ctx context.Context, sellerID int, amount int64) error {
+	return b.users.Credit(ctx, sellerID, amount)
+}
+
+type fakeTransactionRepo struct {
+	store *fakeStore
+}
+
+func (r *fakeTransactionRepo) Record(_ context.Context, transactions ...domain.BalanceTransaction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("ledger.record"); err != nil {
+		return err
+	}
+	domain.FillBalanceAfter(transactions, r.store.state.balances)
+	for _, transaction := range transactions {
+		transaction.TransactionID = int64(len(r.store.state.ledger) + 1)
+		r.store.state.ledger = append(r.store.state.ledger, transaction)
+	}
+	return nil
+}
+
+func (r *fakeTransactionRepo) List(_ context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var transactions []domain.BalanceTransaction
+	for _, transaction := range slices.Backward(r.store.state.ledger) {
+		if filter.UserID != 0 && transaction.UserID != filter.UserID ||
+			len(filter.Types) > 0 && !slices.Contains(filter.Types, transaction.Type) ||
+			filter.From != nil && transaction.CreatedAt.Before(*filter.From) ||
+			filter.To != nil && !transaction.CreatedAt.Before(*filter.To) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	transactions = transactions[min(filter.Offset, len(transactions)):]
+	if filter.Limit > 0 {
+		transactions = transactions[:min(filter.Limit, len(transactions))]
+	}
+	return transactions, nil
+}
+
+type fakeWithdrawalRepo struct {
+	store *fakeStore
+}
+
+func (r *fakeWithdrawalRepo) Create(_ context.Context, withdrawal domain.Withdrawal) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail("withdrawal.create"); err != nil {
+		return 0, err
+	}
+	withdrawal.WithdrawalID = len(r.store.state.withdrawals) + 1
+	r.store.state.withdrawals[withdrawal.WithdrawalID] = withdrawal
+	return withdrawal.WithdrawalID, nil
+}
+
+func (r *fakeWithdrawalRepo) GetForUpdate(_ context.Context, withdrawalID int) (domain.Withdrawal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	withdrawal, ok := r.store.state.withdrawals[withdrawalID]
+	if !ok {
+		return domain.Withdrawal{}, domain.ErrWithdrawalNotFound
+	}
+	return withdrawal, nil
+}
+
+func (r *fakeWithdrawalRepo) Resolve(_ context.Context, withdrawalID int, status domain.WithdrawalStatus, resolvedBy int) error {
+	return r.update("withdrawal.resolve", withdrawalID, func(w *domain.Withdrawal) {
+		now := time.Now()
+		w.Status, w.ResolvedAt, w.ResolvedBy = status, &now, &resolvedBy
+	})
+}
+
+func (r *fakeWithdrawalRepo) StartPayout(_ context.Context, withdrawalID int, from domain.WithdrawalStatus) error {
+	return r.transition("withdrawal.processing", withdrawalID, from, func(w *domain.Withdrawal) {
+		now := time.Now()
+		w.Status, w.PayoutStartedAt = domain.WithdrawalProcessing, &now
+	})
+}
+
+func (r *fakeWithdrawalRepo) MarkPaid(_ context.Context, withdrawalID int, providerReference string) error {
+	return r.transition("withdrawal.paid", withdrawalID, domain.WithdrawalProcessing, func(w *domain.Withdrawal) {
+		now := time.Now()
+		w.Status, w.ProviderReference, w.LastError, w.PaidAt = domain.WithdrawalPaid, providerReference, "", &now
+	})
+}
+
+func (r *fakeWithdrawalRepo) MarkPayoutFailed(_ context.Context, withdrawalID int, lastError string) error {
+	return r.transition("withdrawal.failed", withdrawalID, domain.WithdrawalProcessing, func(w *domain.Withdrawal) {
+		w.Status, w.LastError = domain.WithdrawalApproved, lastError
+	})
+}
+
+// transition изменяет заявку, только если она в статусе from, как условный UPDATE репозитория
+func (r *fakeWithdrawalRepo) transition(step string, withdrawalID int, from domain.WithdrawalStatus, fn func(w *domain.Withdrawal)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail(step); err != nil {
+		return err
+	}
+	withdrawal, ok := r.store.state.withdrawals[withdrawalID]
+	if !ok || withdrawal.Status != from {
+		return domain.ErrWithdrawalResolved
+	}
+	fn(&withdrawal)
+	r.store.state.withdrawals[withdrawalID] = withdrawal
+	return nil
+}
+
+func (r *fakeWithdrawalRepo) update(step string, withdrawalID int, fn func(w *domain.Withdrawal)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail(step); err != nil {
+		return err
+	}
+	withdrawal, ok := r.store.state.withdrawals[withdrawalID]
+	if !ok {
+		return domain.ErrWithdrawalNotFound
+	}
+	fn(&withdrawal)
+	r.store.state.withdrawals[withdrawalID] = withdrawal
+	return nil
+}
+
+func (r *fakeWithdrawalRepo) List(_ context.Context, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var withdrawals []domain.Withdrawal
+	for _, id := range slices.Backward(slices.Sorted(maps.Keys(r.store.state.withdrawals))) {
+		withdrawal := r.store.state.withdrawals[id]
+		if (filter.UserID == 0 || withdrawal.UserID == filter.UserID) && (filter.Status == "" || withdrawal.Status == filter.Status) {
+			withdrawals = append(withdrawals, withdrawal)
+		}
+	}
+	return withdrawals, nil
+}
+
+// fakePayoutProvider запоминает выплаты и возвращает err, если она задана
+type fakePayoutProvider struct {
+	err   error
+	calls []int
+	keys  []string
+	// ctxErr - ошибка контекста выплаты в момент вызова
+	ctxErr error
+	// during вызывается во время выплаты, пока заявка в статусе processing
+	during func()
+}
+
+func (p *fakePayoutProvider) Payout(ctx context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (string, error) {
+	p.calls = append(p.calls, withdrawal.WithdrawalID)
+	p.keys = append(p.keys, idempotencyKey)
+	p.ctxErr = ctx.Err()
+	if p.during != nil {
+		p.during()
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return fmt.Sprintf("fake-%d", withdrawal.WithdrawalID), nil
+}
+
+type fakePaymentRepo struct {
+	store *fakeStore
+}
+
+func (r *fakePaymentRepo) Create(_ context.Context, payment domain.Payment) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	payment.PaymentID = len(r.store.state.payments) + 1
+	r.store.state.payments[payment.PaymentID] = payment
+	return payment.PaymentID, nil
+}
+
+func (r *fakePaymentRepo) SetIntent(_ context.Context, paymentID int, providerPaymentID, checkoutURL string) error {
+	return r.update("payment.intent", paymentID, func(p *domain.Payment) {
+		p.ProviderPaymentID, p.CheckoutURL = providerPaymentID, checkoutURL
+	})
+}
+
+func (r *fakePaymentRepo) Get(_ context.Context, paymentID int) (domain.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	payment, ok := r.store.state.payments[paymentID]
+	if !ok {
+		return domain.Payment{}, domain.ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+func (r *fakePaymentRepo) GetByProviderIDForUpdate(_ context.Context, provider, providerPaymentID string) (domain.Payment, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, payment := range r.store.state.payments {
+		if payment.Provider == provider && payment.ProviderPaymentID == providerPaymentID {
+			return payment, nil
+		}
+	}
+	return domain.Payment{}, domain.ErrPaymentNotFound
+}
+
+func (r *fakePaymentRepo) Confirm(_ context.Context, paymentID int, status domain.PaymentStatus) error {
+	return r.update("payment.confirm", paymentID, func(p *domain.Payment) {
+		now := time.Now()
+		p.Status, p.ConfirmedAt = status, &now
+	})
+}
+
+func (r *fakePaymentRepo) update(step string, paymentID int, fn func(p *domain.Payment)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if err := r.store.fail(step); err != nil {
+		return err
+	}
+	payment, ok := r.store.state.payments[paymentID]
+	if !ok {
+		return domain.ErrPaymentNotFound
+	}
+	fn(&payment)
+	r.store.state.payments[paymentID] = payment
+	return nil
+}
+
+// sentNotification - уведомление, переданное fakeNotifyService
+type sentNotification struct {
+	event domain.NotificationEvent
+	data  notify.TemplateData
+}
+
+// fakeNotifyService запоминает уведомления вместо отправки
+type fakeNotifyService struct {
+	notify.NotifyService
+	sent      map[int][]sentNotification
+	announced [][]notify.AuctionSummary
+	deferred  []domain.NotificationDeferredEvent
+}
+
+func (n *fakeNotifyService) NotifyUser(_ context.Context, userID int, event domain.NotificationEvent, data notify.TemplateData) error {
+	if n.sent == nil {
+		n.sent = map[int][]sentNotification{}
+	}
+	n.sent[userID] = append(n.sent[userID], sentNotification{event: event, data: data})
+	return nil
+}
+
+func (n *fakeNotifyService) NotifyAllUsersAboutNewAuctions(_ context.Context, auctions []notify.AuctionSummary) error {
+	n.announced = append(n.announced, auctions)
+	return nil
+}
+
+func (n *fakeNotifyService) DeliverDeferred(_ context.Context, deferred domain.NotificationDeferredEvent) error {
+	n.deferred = append(n.deferred, deferred)
+	return nil
+}
+
+// newTestPaymentProvider создаёт локального провайдера, уведомления которого тесты
+// передают сервису напрямую
+func newTestPaymentProvider() *payment.LocalPaymentProvider {
+	return payment.NewLocalPaymentProvider("secret", "http://localhost:8080", http.DefaultClient, discardLogger())
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeServiceDeps - зависимости сервиса, которые тесты могут заменить опциями newFakeService
+type fakeServiceDeps struct {
+	payouts    payment.PayoutProvider
+	payments   payment.PaymentProvider
+	commission domain.Commission
+}
+
+type fakeServiceOption func(deps *fakeServiceDeps)
+
+func withPayouts(payouts payment.PayoutProvider) fakeServiceOption {
+	return func(deps *fakeServiceDeps) { deps.payouts = payouts }
+}
+
+func withPayments(payments payment.PaymentProvider) fakeServiceOption {
+	return func(deps *fakeServiceDeps) { deps.payments = payments }
+}
+
+func withCommission(commission domain.Commission) fakeServiceOption {
+	return func(deps *fakeServiceDeps) { deps.commission = commission }
+}
+
+func newFakeService(store *fakeStore, opts ...fakeServiceOption) *AuctionService {
+	deps := fakeServiceDeps{payouts: &fakePayoutProvider{}, payments: newTestPaymentProvider()}
+	for _, opt := range opts {
+		opt(&deps)
+	}
+	repos := store.repositories()
+	balance := &fakeBalanceService{store: store, users: repos.Users.(*fakeUserRepo)}
+	return NewAuctionService(repos, nil, balance, deps.payouts, deps.payments, deps.commission, nil, nil)
+}
+
+// fakeShillRepo отдаёт заданную статистику ставок и запоминает записи очереди проверки
+type fakeShillRepo struct {
+	repo.ShillRepository
+	stats   map[int]domain.BidderStats
+	reviews []domain.ShillReview
+}
+
+func (r *fakeShillRepo) GetBidderStats(_ context.Context, userID int) (domain.BidderStats, error) {
+	stats, ok := r.stats[userID]
+	if !ok {
+		return domain.BidderStats{}, domain.ErrUserNotFound
+	}
+	return stats, nil
+}
+
+func (r *fakeShillRepo) CreateReview(_ context.Context, review domain.ShillReview) error {
+	r.reviews = append(r.reviews, review)
+	return nil
+}
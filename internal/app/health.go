@@ -0,0 +1,251 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-pg/migrations/v8"
+	"github.com/go-pg/pg/v10"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthCheck - проверка готовности одного компонента
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ComponentStatus - состояние компонента в ответе /readyz
+type ComponentStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthReport - ответ /readyz
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// healthMethods доступны без токена: их вызывают оркестратор и балансировщик
+var healthMethods = []string{
+	healthpb.Health_Check_FullMethodName,
+	healthpb.Health_Watch_FullMethodName,
+}
+
+// Health проверяет готовность компонентов сервиса. Результат отдаётся на /readyz и
+// периодически публикуется в стандартном сервисе grpc.health.v1.
+type Health struct {
+	checks   []HealthCheck
+	grpc     *health.Server
+	interval time.Duration
+	timeout  time.Duration
+	logger   *slog.Logger
+	// status - последнее опубликованное состояние, меняется только в publish
+	status healthpb.HealthCheckResponse_ServingStatus
+
+	shuttingDown atomic.Bool
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+}
+
+func NewHealth(logger *slog.Logger, checks ...HealthCheck) *Health {
+	h := &Health{
+		checks:   checks,
+		grpc:     health.NewServer(),
+		interval: 5 * time.Second,
+		timeout:  2 * time.Second,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+	// До первой проверки сервис не готов
+	h.grpc.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return h
+}
+
+// GRPCServer возвращает реализацию grpc.health.v1 для регистрации на gRPC-сервере
+func (h *Health) GRPCServer() healthpb.HealthServer {
+	return h.grpc
+}
+
+// Check выполняет все проверки параллельно
+func (h *Health) Check(ctx context.Context) HealthReport {
+	if h.shuttingDown.Load() {
+		return HealthReport{Status: statusUnavailable, Components: map[string]ComponentStatus{
+			"app": {Status: statusUnavailable, Error: "shutting down"},
+		}}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = HealthReport{Status: statusOK, Components: make(map[string]ComponentStatus, len(h.checks))}
+	)
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			status := ComponentStatus{Status: statusOK}
+			if err := check.Check(ctx); err != nil {
+				status = ComponentStatus{Status: statusUnavailable, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = status
+			if status.Status != statusOK {
+				report.Status = statusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// LivenessHandler отвечает 200, пока процесс способен обрабатывать HTTP-запросы
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, HealthReport{Status: statusOK})
+	})
+}
+
+// ReadinessHandler отвечает 200, если все компоненты готовы, иначе 503
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealthReport(w, h.Check(r.Context()))
+	})
+}
+
+func writeHealthReport(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != statusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// Start запускает периодическое обновление статуса grpc.health.v1
+func (h *Health) Start() {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			h.publish()
+			select {
+			case <-ticker.C:
+			case <-h.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+func (h *Health) publish() {
+	status := healthpb.HealthCheckResponse_SERVING
+	report := h.Check(context.Background())
+	if report.Status != statusOK {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	h.grpc.SetServingStatus("", status)
+
+	// В журнал попадают только смены состояния
+	if status != h.status {
+		h.status = status
+		if status == healthpb.HealthCheckResponse_SERVING {
+			h.logger.Info("service is ready")
+		} else {
+			h.logger.Warn("service is not ready", "components", report.Components)
+		}
+	}
+}
+
+// Shutdown переводит сервис в неготовое состояние, чтобы балансировщик перестал
+// направлять в него запросы. Вызывается в начале остановки.
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+	h.grpc.Shutdown()
+}
+
+func (h *Health) Stop(ctx context.Context) error {
+	h.stopOnce.Do(func() { close(h.stopCh) })
+	h.wg.Wait()
+	return nil
+}
+
+// PostgresCheck проверяет соединение с базой
+func PostgresCheck(db *pg.DB) HealthCheck {
+	return HealthCheck{Name: "postgres", Check: db.Ping}
+}
+
+// MigrationsCheck проверяет, что применены все встроенные миграции
+func MigrationsCheck(db *pg.DB) HealthCheck {
+	return HealthCheck{Name: "migrations", Check: func(ctx context.Context) error {
+		want, err := latestMigrationVersion()
+		if err != nil {
+			return err
+		}
+		got, err := migrations.NewCollection().Version(db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if got < want {
+			return fmt.Errorf("schema version %d, want %d", got, want)
+		}
+		return nil
+	}}
+}
+
+// WorkerCheck проверяет, что цикл обработчика аукционов отрабатывал недавно
+func WorkerCheck(worker *AuctionWorker) HealthCheck {
+	return HealthCheck{Name: "auction_worker", Check: func(context.Context) error {
+		last := worker.LastTick()
+		if last.IsZero() {
+			return fmt.Errorf("worker has not started")
+		}
+		if since := time.Since(last); since > worker.StaleAfter() {
+			return fmt.Errorf("last tick %s ago", since.Round(time.Second))
+		}
+		return nil
+	}}
+}
+
+func latestMigrationVersion() (int64, error) {
+	entries, err := MigrationFS.ReadDir("migrations")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration name %q", entry.Name())
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
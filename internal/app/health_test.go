@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func okCheck(name string) HealthCheck {
+	return HealthCheck{Name: name, Check: func(context.Context) error { return nil }}
+}
+
+func TestReadinessReportsComponents(t *testing.T) {
+	health := NewHealth(discardLogger(),
+		okCheck("postgres"),
+		HealthCheck{Name: "migrations", Check: func(context.Context) error { return errors.New("schema version 5, want 6") }},
+	)
+
+	rec := httptest.NewRecorder()
+	health.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{
+		"status": "unavailable",
+		"components": {
+			"postgres": {"status": "ok"},
+			"migrations": {"status": "unavailable", "error": "schema version 5, want 6"}
+		}
+	}`, rec.Body.String())
+}
+
+func TestReadinessOK(t *testing.T) {
+	health := NewHealth(discardLogger(), okCheck("postgres"))
+
+	rec := httptest.NewRecorder()
+	health.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status": "ok", "components": {"postgres": {"status": "ok"}}}`, rec.Body.String())
+}
+
+func TestGRPCHealthFollowsReadiness(t *testing.T) {
+	health := NewHealth(discardLogger(), okCheck("postgres"))
+	ctx := context.Background()
+
+	resp, err := health.GRPCServer().Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	health.publish()
+	resp, err = health.GRPCServer().Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// При остановке сервис сразу перестаёт быть готовым, живым остаётся
+	health.Shutdown()
+	resp, err = health.GRPCServer().Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	rec := httptest.NewRecorder()
+	health.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	health.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWorkerCheck(t *testing.T) {
+	worker := NewAuctionWorker(&fakeWorkerService{settled: map[int]time.Time{}}, NewClosingSchedule(), nil,
+		Scheduler{SweepInterval: 10 * time.Millisecond}, discardLogger(), nil)
+	check := WorkerCheck(worker)
+
+	assert.ErrorContains(t, check.Check(context.Background()), "not started")
+
+	worker.Start()
+	require.Eventually(t, func() bool { return check.Check(context.Background()) == nil }, time.Second, time.Millisecond)
+
+	require.NoError(t, worker.Stop(context.Background()))
+	time.Sleep(worker.StaleAfter() + 10*time.Millisecond)
+	assert.ErrorContains(t, check.Check(context.Background()), "last tick")
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	entries, err := MigrationFS.ReadDir("migrations")
+	require.NoError(t, err)
+
+	version, err := latestMigrationVersion()
+	require.NoError(t, err)
+	assert.EqualValues(t, len(entries), version)
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics - бизнес-показатели и показатели обработчиков. Методы безопасно вызывать
+// у nil, тогда показатели не собираются.
+type Metrics struct {
+	lotsCreated     prometheus.Counter
+	bids            *prometheus.CounterVec
+	settlements     *prometheus.CounterVec
+	refunds         prometheus.Counter
+	volume          prometheus.Counter
+	pendingAuctions prometheus.Gauge
+	inFlight        prometheus.Gauge
+	lastTick        prometheus.Gauge
+	settleDuration  prometheus.Histogram
+	settleErrors    prometheus.Counter
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		lotsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "auction_lots_created_total",
+			Help: "Number of lots created.",
+		}),
+		bids: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auction_bids_total",
+			Help: "Number of bids by result and rejection reason.",
+		}, []string{"result", "reason"}),
+		settlements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auction_settlements_total",
+			Help: "Number of settled auctions by outcome (sold, unsold).",
+		}, []string{"outcome"}),
+		refunds: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "auction_refunds_total",
+			Help: "Number of refunds issued to losing bidders.",
+		}),
+		volume: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "auction_volume_total",
+			Help: "Total price of sold lots.",
+		}),
+		pendingAuctions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "auction_worker_pending_auctions",
+			Help: "Closed auctions waiting in the settlement queue.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "auction_worker_settlements_in_flight",
+			Help: "Settlements currently being processed.",
+		}),
+		lastTick: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "auction_worker_last_tick_timestamp_seconds",
+			Help: "Unix time of the last auction worker loop iteration.",
+		}),
+		settleDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "auction_worker_settlement_duration_seconds",
+			Help:    "Time to settle one auction.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		settleErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "auction_worker_settlement_errors_total",
+			Help: "Number of failed settlement attempts.",
+		}),
+	}
+	reg.MustRegister(m.lotsCreated, m.bids, m.settlements, m.refunds, m.volume,
+		m.pendingAuctions, m.inFlight, m.lastTick, m.settleDuration, m.settleErrors)
+	return m
+}
+
+func (m *Metrics) lotCreated() {
+	if m == nil {
+		return
+	}
+	m.lotsCreated.Inc()
+}
+
+func (m *Metrics) bidPlaced(err error) {
+	if m == nil {
+		return
+	}
+	if err == nil {
+		m.bids.WithLabelValues("accepted", "").Inc()
+		return
+	}
+	m.bids.WithLabelValues("rejected", bidRejectionReason(err)).Inc()
+}
+
+func bidRejectionReason(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBidAmount):
+		return "invalid_amount"
+	case errors.Is(err, domain.ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, domain.ErrSelfBid):
+		return "self_bid"
+	case errors.Is(err, domain.ErrLotNotFound):
+		return "lot_not_found"
+	case errors.Is(err, domain.ErrAuctionCancelled):
+		return "auction_cancelled"
+	default:
+		return "error"
+	}
+}
+
+func (m *Metrics) auctionSettled(settlement domain.Settlement) {
+	if m == nil {
+		return
+	}
+	if !settlement.Sold() {
+		m.settlements.WithLabelValues("unsold").Inc()
+		return
+	}
+	m.settlements.WithLabelValues("sold").Inc()
+	m.volume.Add(float64(settlement.Price))
+	m.refunds.Add(float64(len(settlement.Losers)))
+}
+
+func (m *Metrics) queued(delta int) {
+	if m == nil {
+		return
+	}
+	m.pendingAuctions.Add(float64(delta))
+}
+
+func (m *Metrics) settlementStarted() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Inc()
+}
+
+func (m *Metrics) settlementFinished(duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.inFlight.Dec()
+	m.settleDuration.Observe(duration.Seconds())
+	if err != nil {
+		m.settleErrors.Inc()
+	}
+}
+
+func (m *Metrics) workerTicked() {
+	if m == nil {
+		return
+	}
+	m.lastTick.SetToCurrentTime()
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsSettlement(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	service := newFakeService(newSettlementStore())
+	service.metrics = metrics
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	// Повторный расчёт ничего не меняет и не должен учитываться
+	_, _, err = service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.settlements.WithLabelValues("sold")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.settlements.WithLabelValues("unsold")))
+	assert.Equal(t, 150.0, testutil.ToFloat64(metrics.volume))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.refunds))
+}
+
+func TestMetricsUnsoldAuction(t *testing.T) {
+	closedAt := time.Now().Add(-time.Minute)
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1, ClosedAt: &closedAt}
+	metrics := NewMetrics(prometheus.NewRegistry())
+	service := newFakeService(store)
+	service.metrics = metrics
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.settlements.WithLabelValues("unsold")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.volume))
+}
+
+func TestMetricsBids(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	metrics.bidPlaced(nil)
+	metrics.bidPlaced(nil)
+	metrics.bidPlaced(domain.ErrInsufficientFunds)
+	metrics.bidPlaced(fmt.Errorf("validate: %w", domain.ErrSelfBid))
+	metrics.bidPlaced(errors.New("connection reset"))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.bids.WithLabelValues("accepted", "")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.bids.WithLabelValues("rejected", "insufficient_funds")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.bids.WithLabelValues("rejected", "self_bid")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.bids.WithLabelValues("rejected", "error")))
+}
+
+func TestMetricsWorker(t *testing.T) {
+	release := make(chan struct{})
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(3),
+		settle: func(ctx context.Context, _ int) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	cfg := Scheduler{SweepInterval: time.Hour, Concurrency: 1}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, cfg, discardLogger(), metrics)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.inFlight) == 1 && testutil.ToFloat64(metrics.pendingAuctions) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(metrics.lastTick), 5)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.inFlight) == 0 && testutil.ToFloat64(metrics.pendingAuctions) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() == "auction_worker_settlement_duration_seconds" {
+			assert.Equal(t, uint64(3), family.GetMetric()[0].GetHistogram().GetSampleCount())
+		}
+	}
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.settleErrors))
+}
+
+func TestMetricsWorkerTicksOnlyOnSuccess(t *testing.T) {
+	service := &fakeWorkerService{settled: map[int]time.Time{}, loadErr: errors.New("database is down")}
+	metrics := NewMetrics(prometheus.NewRegistry())
+	cfg := Scheduler{SweepInterval: 10 * time.Millisecond, Concurrency: 1}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, cfg, discardLogger(), metrics)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	time.Sleep(5 * cfg.SweepInterval)
+	assert.True(t, worker.LastTick().IsZero())
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.lastTick))
+
+	service.setLoadErr(nil)
+	require.Eventually(t, func() bool { return !worker.LastTick().IsZero() }, time.Second, 5*time.Millisecond)
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(metrics.lastTick), 5)
+}
+
+func TestMetricsNil(t *testing.T) {
+	var metrics *Metrics
+
+	assert.NotPanics(t, func() {
+		metrics.lotCreated()
+		metrics.bidPlaced(nil)
+		metrics.auctionSettled(domain.Settlement{})
+		metrics.queued(1)
+		metrics.settlementStarted()
+		metrics.settlementFinished(time.Second, nil)
+		metrics.workerTicked()
+	})
+}
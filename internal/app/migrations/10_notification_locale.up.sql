@@ -0,0 +1,5 @@
+ALTER TABLE "user" ADD COLUMN "locale" varchar(8) NOT NULL DEFAULT 'ru';
+ALTER TABLE "user" ADD CONSTRAINT "chk_user_locale" CHECK ("locale" IN ('ru', 'en'));
+
+-- Уведомления, записанные до появления колонки, остаются без события
+ALTER TABLE "notification" ADD COLUMN "event" varchar(32);
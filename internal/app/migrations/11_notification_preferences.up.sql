@@ -0,0 +1,18 @@
+CREATE TABLE "notification_preferences" (
+                                            "user_id" int4 NOT NULL,
+                                            "disabled_events" text[] NOT NULL DEFAULT '{}',
+                                            -- Каналы отдельных событий: {"outbid": ["webhook"]}
+                                            "event_channels" jsonb NOT NULL DEFAULT '{}',
+                                            "quiet_start_minute" int2,
+                                            "quiet_end_minute" int2,
+                                            "timezone" varchar(64) NOT NULL DEFAULT 'UTC',
+                                            "updated_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                            PRIMARY KEY("user_id"),
+                                            CONSTRAINT "chk_notification_preferences_quiet_hours" CHECK (
+                                                ("quiet_start_minute" IS NULL) = ("quiet_end_minute" IS NULL)
+                                                AND "quiet_start_minute" BETWEEN 0 AND 1439
+                                                AND "quiet_end_minute" BETWEEN 0 AND 1439
+                                            )
+);
+
+ALTER TABLE "notification_preferences" ADD CONSTRAINT "fk_notification_preferences_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
@@ -0,0 +1,34 @@
+CREATE TABLE "webhook_subscription" (
+                                        "id" serial4 NOT NULL,
+                                        "url" text NOT NULL,
+                                        "events" text[] NOT NULL,
+                                        "secret" text NOT NULL,
+                                        "active" bool NOT NULL DEFAULT true,
+                                        "consecutive_failures" int4 NOT NULL DEFAULT 0,
+                                        "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                        "disabled_at" TIMESTAMPTZ,
+                                        PRIMARY KEY("id")
+);
+
+CREATE TABLE "webhook_delivery" (
+                                    "id" bigserial NOT NULL,
+                                    "subscription_id" int4 NOT NULL,
+                                    "outbox_message_id" int8 NOT NULL,
+                                    "event" varchar(64) NOT NULL,
+                                    "payload" jsonb NOT NULL,
+                                    "status" varchar(16) NOT NULL DEFAULT 'pending',
+                                    "attempts" int4 NOT NULL DEFAULT 0,
+                                    "response_status" int4,
+                                    "last_error" text,
+                                    "next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                    "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                    "delivered_at" TIMESTAMPTZ,
+                                    PRIMARY KEY("id"),
+                                    CONSTRAINT "chk_webhook_delivery_status" CHECK ("status" IN ('pending', 'delivered', 'failed'))
+);
+
+ALTER TABLE "webhook_delivery" ADD CONSTRAINT "fk_webhook_delivery_subscription" FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscription" ("id") ON DELETE CASCADE;
+
+-- Повторная обработка события outbox не создаёт вторую доставку
+CREATE UNIQUE INDEX idx_webhook_delivery_message ON webhook_delivery (subscription_id, outbox_message_id);
+CREATE INDEX idx_webhook_delivery_pending ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
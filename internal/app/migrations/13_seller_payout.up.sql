@@ -0,0 +1,18 @@
+ALTER TABLE "settlement" ADD COLUMN "seller_id" int4;
+ALTER TABLE "settlement" ADD COLUMN "fee" int8 NOT NULL DEFAULT 0;
+ALTER TABLE "settlement" ADD CONSTRAINT "fk_settlement_seller" FOREIGN KEY ("seller_id") REFERENCES "user" ("id") ON DELETE SET NULL;
+
+CREATE TABLE "balance_transaction" (
+                                       "id" bigserial NOT NULL,
+                                       "user_id" int4 NOT NULL,
+                                       "type" varchar(32) NOT NULL,
+                                       "amount" int8 NOT NULL,
+                                       "auction_id" int4,
+                                       "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                       PRIMARY KEY("id")
+);
+
+ALTER TABLE "balance_transaction" ADD CONSTRAINT "fk_balance_transaction_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "balance_transaction" ADD CONSTRAINT "fk_balance_transaction_auction" FOREIGN KEY ("auction_id") REFERENCES "auction" ("id") ON DELETE SET NULL;
+
+CREATE INDEX "balance_transaction_user_idx" ON "balance_transaction" ("user_id", "created_at");
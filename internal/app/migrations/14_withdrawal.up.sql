@@ -0,0 +1,25 @@
+CREATE TABLE "withdrawal" (
+                              "id" serial4 NOT NULL,
+                              "user_id" int4 NOT NULL,
+                              "amount" int8 NOT NULL,
+                              "destination" text NOT NULL,
+                              "status" varchar(16) NOT NULL DEFAULT 'pending',
+                              "provider_reference" text,
+                              "last_error" text,
+                              "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                              "resolved_at" TIMESTAMPTZ,
+                              "resolved_by" int4,
+                              "paid_at" TIMESTAMPTZ,
+                              PRIMARY KEY("id"),
+                              CONSTRAINT "withdrawal_amount_check" CHECK ("amount" > 0),
+                              CONSTRAINT "withdrawal_status_check" CHECK ("status" IN ('pending', 'approved', 'paid', 'rejected'))
+);
+
+ALTER TABLE "withdrawal" ADD CONSTRAINT "fk_withdrawal_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "withdrawal" ADD CONSTRAINT "fk_withdrawal_resolved_by" FOREIGN KEY ("resolved_by") REFERENCES "user" ("id") ON DELETE SET NULL;
+
+CREATE INDEX "withdrawal_user_idx" ON "withdrawal" ("user_id", "created_at");
+CREATE INDEX "withdrawal_status_idx" ON "withdrawal" ("status", "created_at");
+
+ALTER TABLE "balance_transaction" ADD COLUMN "withdrawal_id" int4;
+ALTER TABLE "balance_transaction" ADD CONSTRAINT "fk_balance_transaction_withdrawal" FOREIGN KEY ("withdrawal_id") REFERENCES "withdrawal" ("id") ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+ALTER TABLE "balance_transaction" ADD COLUMN "balance_after" int8;
+
+-- Баланс после уже записанных движений восстанавливается от текущего баланса
+UPDATE "balance_transaction" t
+SET "balance_after" = COALESCE(u."balance", 0) - COALESCE((
+    SELECT sum(l."amount") FROM "balance_transaction" l
+    WHERE l."user_id" = t."user_id" AND l."id" > t."id"
+), 0)
+FROM "user" u
+WHERE u."id" = t."user_id";
+
+ALTER TABLE "balance_transaction" ALTER COLUMN "balance_after" SET NOT NULL;
+
+CREATE INDEX "balance_transaction_user_type_idx" ON "balance_transaction" ("user_id", "type", "created_at");
@@ -0,0 +1,23 @@
+CREATE TABLE "payment" (
+                           "id" serial4 NOT NULL,
+                           "user_id" int4 NOT NULL,
+                           "amount" int8 NOT NULL,
+                           "provider" varchar(32) NOT NULL,
+                           "provider_payment_id" text,
+                           "checkout_url" text,
+                           "status" varchar(16) NOT NULL DEFAULT 'pending',
+                           "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                           "confirmed_at" TIMESTAMPTZ,
+                           PRIMARY KEY("id"),
+                           CONSTRAINT "payment_amount_check" CHECK ("amount" > 0),
+                           CONSTRAINT "payment_status_check" CHECK ("status" IN ('pending', 'succeeded', 'failed'))
+);
+
+ALTER TABLE "payment" ADD CONSTRAINT "fk_payment_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+
+-- Уведомление провайдера находит платёж по идентификатору намерения
+CREATE UNIQUE INDEX "payment_provider_idx" ON "payment" ("provider", "provider_payment_id");
+CREATE INDEX "payment_user_idx" ON "payment" ("user_id", "created_at");
+
+ALTER TABLE "balance_transaction" ADD COLUMN "payment_id" int4;
+ALTER TABLE "balance_transaction" ADD CONSTRAINT "fk_balance_transaction_payment" FOREIGN KEY ("payment_id") REFERENCES "payment" ("id") ON DELETE SET NULL;
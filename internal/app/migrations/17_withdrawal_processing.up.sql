@@ -0,0 +1,3 @@
+-- Выплата одобренной заявки выполняется в статусе processing, чтобы её не отправили дважды
+ALTER TABLE "withdrawal" DROP CONSTRAINT "withdrawal_status_check";
+ALTER TABLE "withdrawal" ADD CONSTRAINT "withdrawal_status_check" CHECK ("status" IN ('pending', 'approved', 'processing', 'paid', 'rejected'));
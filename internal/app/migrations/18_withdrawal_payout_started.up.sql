@@ -0,0 +1,2 @@
+-- Время начала выплаты: заявку, зависшую в processing, администратор одобряет повторно
+ALTER TABLE "withdrawal" ADD COLUMN "payout_started_at" TIMESTAMPTZ;
@@ -0,0 +1,7 @@
+ALTER TABLE "user" ADD COLUMN "roles" text[] NOT NULL DEFAULT '{bidder}';
+
+UPDATE "user" SET "roles" = '{admin,seller,bidder}' WHERE "id" = 1;
+
+ALTER TABLE "auction" ADD COLUMN "cancelled_at" TIMESTAMPTZ;
+
+CREATE INDEX idx_lots_auction_id ON lot (auction_id);
@@ -0,0 +1,35 @@
+-- Время регистрации существующих пользователей неизвестно: берётся первое действие пользователя,
+-- а без действий - начало эпохи, чтобы правило new_account не считало старые аккаунты новыми
+ALTER TABLE "user" ADD COLUMN "created_at" TIMESTAMPTZ;
+UPDATE "user" u SET "created_at" = COALESCE(
+    LEAST(
+        (SELECT MIN(b.created_at) FROM "bid" b WHERE b.user_id = u.id),
+        (SELECT MIN(l.created_at) FROM "lot" l WHERE l.user_id = u.id)
+    ),
+    'epoch'
+);
+ALTER TABLE "user" ALTER COLUMN "created_at" SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE "user" ALTER COLUMN "created_at" SET NOT NULL;
+
+CREATE TABLE "shill_review" (
+                                "id" int4 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+                                "user_id" int4 NOT NULL,
+                                "seller_id" int4,
+                                "lot_id" int4,
+                                "reason" varchar(32) NOT NULL,
+                                "details" text NOT NULL,
+                                "status" varchar(16) NOT NULL DEFAULT 'pending',
+                                "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                "resolved_at" TIMESTAMPTZ,
+                                "resolved_by" int4,
+                                PRIMARY KEY("id")
+);
+
+ALTER TABLE "shill_review" ADD CONSTRAINT "fk_shill_review_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+ALTER TABLE "shill_review" ADD CONSTRAINT "fk_shill_review_seller" FOREIGN KEY ("seller_id") REFERENCES "user" ("id") ON DELETE SET NULL;
+ALTER TABLE "shill_review" ADD CONSTRAINT "fk_shill_review_lot" FOREIGN KEY ("lot_id") REFERENCES "lot" ("id") ON DELETE SET NULL;
+ALTER TABLE "shill_review" ADD CONSTRAINT "fk_shill_review_resolved_by" FOREIGN KEY ("resolved_by") REFERENCES "user" ("id") ON DELETE SET NULL;
+
+-- Один открытый флаг на пользователя и причину
+CREATE UNIQUE INDEX idx_shill_review_pending ON shill_review (user_id, reason) WHERE status = 'pending';
+CREATE INDEX idx_shill_review_status_created_at ON shill_review (status, created_at);
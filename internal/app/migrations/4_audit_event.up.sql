@@ -0,0 +1,27 @@
+CREATE TABLE "audit_event" (
+                               "id" int8 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+                               "actor_id" int4,
+                               "action" varchar(64) NOT NULL,
+                               "entity_type" varchar(32) NOT NULL,
+                               "entity_id" int4 NOT NULL,
+                               "before" jsonb,
+                               "after" jsonb,
+                               "request_id" varchar(128),
+                               "client_ip" varchar(64),
+                               "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                               PRIMARY KEY("id")
+);
+
+CREATE INDEX idx_audit_event_entity ON audit_event (entity_type, entity_id, created_at);
+CREATE INDEX idx_audit_event_created_at ON audit_event (created_at);
+
+-- Журнал только дополняется: изменение и удаление записей запрещены
+CREATE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_event_append_only
+    BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
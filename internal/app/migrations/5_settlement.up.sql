@@ -0,0 +1,17 @@
+CREATE TABLE "settlement" (
+                              "auction_id" int4 NOT NULL,
+                              "winner_id" int4,
+                              "winning_bid_id" int4,
+                              "price" int8 NOT NULL DEFAULT 0,
+                              "losers" int4[] NOT NULL DEFAULT '{}',
+                              "settled_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                              PRIMARY KEY("auction_id")
+);
+
+ALTER TABLE "settlement" ADD CONSTRAINT "fk_settlement_auction" FOREIGN KEY ("auction_id") REFERENCES "auction" ("id") ON DELETE CASCADE;
+ALTER TABLE "settlement" ADD CONSTRAINT "fk_settlement_winner" FOREIGN KEY ("winner_id") REFERENCES "user" ("id") ON DELETE SET NULL;
+ALTER TABLE "settlement" ADD CONSTRAINT "fk_settlement_winning_bid" FOREIGN KEY ("winning_bid_id") REFERENCES "bid" ("id") ON DELETE SET NULL;
+
+-- Аукционы, завершённые до появления таблицы, считаются рассчитанными
+INSERT INTO "settlement" ("auction_id", "winner_id", "settled_at")
+SELECT "id", "winner_id", "closed_at" FROM "auction" WHERE "winner_id" IS NOT NULL;
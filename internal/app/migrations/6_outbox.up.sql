@@ -0,0 +1,15 @@
+CREATE TABLE "outbox" (
+                          "id" int8 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+                          "topic" varchar(64) NOT NULL,
+                          "payload" jsonb NOT NULL,
+                          "attempts" int4 NOT NULL DEFAULT 0,
+                          "last_error" text,
+                          "next_attempt_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                          "delivered_at" TIMESTAMPTZ,
+                          "failed_at" TIMESTAMPTZ,
+                          PRIMARY KEY("id")
+);
+
+-- Очередь на доставку: недоставленные и не отброшенные после исчерпания попыток
+CREATE INDEX idx_outbox_pending ON outbox (next_attempt_at, id) WHERE delivered_at IS NULL AND failed_at IS NULL;
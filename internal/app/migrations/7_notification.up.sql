@@ -0,0 +1,22 @@
+ALTER TABLE "user" ADD COLUMN "notification_channels" text[] NOT NULL DEFAULT '{inbox}';
+ALTER TABLE "user" ADD COLUMN "webhook_url" text;
+
+CREATE TABLE "notification" (
+                                "id" int8 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+                                "user_id" int4 NOT NULL,
+                                "channel" varchar(16) NOT NULL,
+                                "subject" text NOT NULL,
+                                "body" text NOT NULL,
+                                "status" varchar(16) NOT NULL,
+                                "error" text,
+                                "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                "read_at" TIMESTAMPTZ,
+                                PRIMARY KEY("id"),
+                                CHECK ("channel" IN ('email', 'webhook', 'inbox')),
+                                CHECK ("status" IN ('sent', 'failed'))
+);
+
+ALTER TABLE "notification" ADD CONSTRAINT "fk_notification_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+
+-- Лента уведомлений пользователя в приложении
+CREATE INDEX idx_notification_inbox ON notification (user_id, id DESC) WHERE channel = 'inbox';
@@ -0,0 +1,6 @@
+ALTER TABLE "auction" ADD COLUMN "announced_at" TIMESTAMPTZ;
+
+-- Аукционы, созданные до появления колонки, повторно не объявляются
+UPDATE "auction" SET "announced_at" = "created_at";
+
+CREATE INDEX idx_auctions_unannounced ON auction (created_at) WHERE announced_at IS NULL;
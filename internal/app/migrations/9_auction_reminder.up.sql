@@ -0,0 +1,16 @@
+ALTER TABLE "auction" ADD COLUMN "reminded_at" TIMESTAMPTZ;
+
+CREATE TABLE "auction_watch" (
+                                 "auction_id" int4 NOT NULL,
+                                 "user_id" int4 NOT NULL,
+                                 "created_at" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                 PRIMARY KEY("auction_id", "user_id")
+);
+
+ALTER TABLE "auction_watch" ADD CONSTRAINT "fk_auction_watch_auction" FOREIGN KEY ("auction_id") REFERENCES "auction" ("id") ON DELETE CASCADE;
+ALTER TABLE "auction_watch" ADD CONSTRAINT "fk_auction_watch_user" FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE;
+
+CREATE INDEX idx_auction_watch_user_id ON auction_watch (user_id);
+
+-- Аукционы, ожидающие напоминания о завершении
+CREATE INDEX idx_auctions_unreminded ON auction (closed_at) WHERE reminded_at IS NULL AND cancelled_at IS NULL;
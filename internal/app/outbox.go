@@ -0,0 +1,312 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/notify"
+	"auction/internal/infrastructure/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// EventSink - получатель событий из outbox. Доставка выполняется не менее одного раза:
+// после ошибки любого получателя сообщение повторно передаётся всем получателям.
+type EventSink interface {
+	Handle(ctx context.Context, message domain.OutboxMessage) error
+}
+
+// enqueue записывает событие в outbox. Вызывается внутри транзакции изменения.
+func (s *AuctionService) enqueue(ctx context.Context, topic domain.OutboxTopic, event any) error {
+	message, err := domain.NewOutboxMessage(topic, event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox event: %w", err)
+	}
+	if err := s.outboxRepo.Enqueue(ctx, message); err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+// OutboxDispatcher периодически забирает сообщения из outbox и передаёт их получателям.
+// Неудачные попытки повторяются с экспоненциальной задержкой.
+type OutboxDispatcher struct {
+	outbox repo.OutboxRepository
+	sinks  []EventSink
+	cfg    Outbox
+	logger *slog.Logger
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewOutboxDispatcher(outbox repo.OutboxRepository, cfg Outbox, logger *slog.Logger, sinks ...EventSink) *OutboxDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboxDispatcher{
+		outbox: outbox,
+		sinks:  sinks,
+		cfg:    cfg.WithDefaults(),
+		logger: logger,
+		stopCh: make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (d *OutboxDispatcher) Start() {
+	d.logger.Info("outbox dispatcher started")
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run()
+	}()
+}
+
+// Stop дожидается доставки текущей пачки, а по дедлайну ctx прерывает её. Недоставленные
+// сообщения вернутся в очередь по истечении аренды. Повторный вызов безопасен.
+func (d *OutboxDispatcher) Stop(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+
+	d.logger.Info("outbox dispatcher stopped")
+	return err
+}
+
+func (d *OutboxDispatcher) run() {
+	for {
+		select {
+		case <-time.After(d.cfg.Interval):
+			// Полная пачка означает, что в очереди могут остаться готовые сообщения
+			for d.Dispatch(d.ctx) == d.cfg.BatchSize {
+				select {
+				case <-d.stopCh:
+					return
+				default:
+				}
+			}
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+// Dispatch доставляет одну пачку сообщений и возвращает её размер
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) int {
+	messages, err := d.outbox.Claim(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		d.logger.Error("failed to claim outbox messages", "error", err)
+		return 0
+	}
+
+	for i, message := range messages {
+		select {
+		case <-d.stopCh:
+			// Остаток пачки вернётся в очередь по истечении аренды
+			return i
+		default:
+		}
+		d.deliver(ctx, message)
+	}
+	return len(messages)
+}
+
+func (d *OutboxDispatcher) deliver(ctx context.Context, message domain.OutboxMessage) {
+	logger := d.logger.With("outbox_message_id", message.ID, "topic", message.Topic)
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.Handle(ctx, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := d.outbox.MarkDelivered(ctx, message.ID); err != nil {
+			logger.Error("failed to mark outbox message delivered", "error", err)
+		}
+		return
+	}
+
+	deliveryErr := errors.Join(errs...).Error()
+	if message.Attempts+1 >= d.cfg.MaxAttempts {
+		logger.Warn("outbox message dropped", "attempts", message.Attempts+1, "error", deliveryErr)
+		if err := d.outbox.MarkDead(ctx, message.ID, deliveryErr); err != nil {
+			logger.Error("failed to mark outbox message dead", "error", err)
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(d.backoff(message.Attempts))
+	if err := d.outbox.MarkFailed(ctx, message.ID, deliveryErr, nextAttemptAt); err != nil {
+		logger.Error("failed to mark outbox message failed", "error", err)
+	}
+}
+
+// backoff возвращает задержку перед следующей попыткой: BaseBackoff, удваиваемый
+// после каждой неудачи, но не больше MaxBackoff
+func (d *OutboxDispatcher) backoff(attempts int) time.Duration {
+	return exponentialBackoff(d.cfg.BaseBackoff, d.cfg.MaxBackoff, attempts)
+}
+
+func exponentialBackoff(base, maxDelay time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// NotificationSink отправляет пользователям уведомления о событиях аукциона: итогах, перебитых
+// ставках, первой ставке на лот, скором завершении и новых аукционах, а также уведомления,
+// отложенные до конца тихих часов. Название лота для шаблонов берётся из базы.
+type NotificationSink struct {
+	notify notify.NotifyService
+	lots   repo.LotRepository
+	now    func() time.Time
+}
+
+func NewNotificationSink(notify notify.NotifyService, lots repo.LotRepository) *NotificationSink {
+	return &NotificationSink{notify: notify, lots: lots, now: time.Now}
+}
+
+func (n *NotificationSink) Handle(ctx context.Context, message domain.OutboxMessage) error {
+	switch message.Topic {
+	case domain.OutboxAuctionWon, domain.OutboxAuctionLost:
+		return n.auctionResult(ctx, message)
+	case domain.OutboxBidOutbid:
+		return n.outbid(ctx, message)
+	case domain.OutboxLotFirstBid:
+		return n.firstBid(ctx, message)
+	case domain.OutboxAuctionEndingSoon:
+		return n.endingSoon(ctx, message)
+	case domain.OutboxAuctionsAnnounced:
+		return n.announce(ctx, message)
+	case domain.OutboxNotificationDeferred:
+		var event domain.NotificationDeferredEvent
+		if err := decodePayload(message, &event); err != nil {
+			return err
+		}
+		return n.notify.DeliverDeferred(ctx, event)
+	default:
+		return nil
+	}
+}
+
+func decodePayload(message domain.OutboxMessage, event any) error {
+	if err := json.Unmarshal(message.Payload, event); err != nil {
+		return fmt.Errorf("invalid %s payload: %w", message.Topic, err)
+	}
+	return nil
+}
+
+// lotData заполняет данные шаблона лотом аукциона
+func (n *NotificationSink) lotData(ctx context.Context, auctionID int) (notify.TemplateData, error) {
+	lot, err := n.lots.GetLotByAuctionID(ctx, auctionID)
+	if err != nil {
+		return notify.TemplateData{}, fmt.Errorf("failed to get lot of auction %d: %w", auctionID, err)
+	}
+	return notify.TemplateData{AuctionID: auctionID, LotID: lot.LotID, LotTitle: lot.Title}, nil
+}
+
+func (n *NotificationSink) auctionResult(ctx context.Context, message domain.OutboxMessage) error {
+	var event domain.AuctionResultEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+	data, err := n.lotData(ctx, event.AuctionID)
+	if err != nil {
+		return err
+	}
+	data.Price = event.Price
+
+	notificationEvent := domain.EventAuctionLost
+	if message.Topic == domain.OutboxAuctionWon {
+		notificationEvent = domain.EventAuctionWon
+	}
+	return n.notify.NotifyUser(ctx, event.UserID, notificationEvent, data)
+}
+
+func (n *NotificationSink) outbid(ctx context.Context, message domain.OutboxMessage) error {
+	var event domain.OutbidEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+	data, err := n.lotData(ctx, event.AuctionID)
+	if err != nil {
+		return err
+	}
+	data.Price = event.Price
+	return n.notify.NotifyUser(ctx, event.UserID, domain.EventOutbid, data)
+}
+
+func (n *NotificationSink) firstBid(ctx context.Context, message domain.OutboxMessage) error {
+	var event domain.FirstBidEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+	data, err := n.lotData(ctx, event.AuctionID)
+	if err != nil {
+		return err
+	}
+	data.Price = event.Price
+	return n.notify.NotifyUser(ctx, event.SellerID, domain.EventFirstBid, data)
+}
+
+// endingSoon напоминает о завершении аукциона. Напоминание, доставка которого задержалась
+// до закрытия аукциона, не отправляется.
+func (n *NotificationSink) endingSoon(ctx context.Context, message domain.OutboxMessage) error {
+	var event domain.EndingSoonEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+
+	left := event.ClosedAt.Sub(n.now())
+	if left <= 0 {
+		return nil
+	}
+	data, err := n.lotData(ctx, event.AuctionID)
+	if err != nil {
+		return err
+	}
+	data.MinutesLeft = int(math.Ceil(left.Minutes()))
+	return n.notify.NotifyUser(ctx, event.UserID, domain.EventEndingSoon, data)
+}
+
+// announce рассылает всем пользователям список новых аукционов
+func (n *NotificationSink) announce(ctx context.Context, message domain.OutboxMessage) error {
+	var event domain.AuctionsAnnouncedEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+
+	auctions := make([]notify.AuctionSummary, len(event.AuctionIDs))
+	for i, auctionID := range event.AuctionIDs {
+		lot, err := n.lots.GetLotByAuctionID(ctx, auctionID)
+		if err != nil {
+			return fmt.Errorf("failed to get lot of auction %d: %w", auctionID, err)
+		}
+		auctions[i] = notify.AuctionSummary{AuctionID: auctionID, LotTitle: lot.Title, StartPrice: int64(lot.StartPrice)}
+	}
+	return n.notify.NotifyAllUsersAboutNewAuctions(ctx, auctions)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSink struct {
+	err      error
+	received []domain.OutboxMessage
+}
+
+func (s *fakeSink) Handle(_ context.Context, message domain.OutboxMessage) error {
+	s.received = append(s.received, message)
+	return s.err
+}
+
+func newTestDispatcher(store *fakeStore, sinks ...EventSink) *OutboxDispatcher {
+	cfg := Outbox{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second, MaxAttempts: 3}
+	return NewOutboxDispatcher(&fakeOutboxRepo{store: store}, cfg, discardLogger(), sinks...)
+}
+
+func enqueueResult(t *testing.T, store *fakeStore, topic domain.OutboxTopic, userID int) {
+	message, err := domain.NewOutboxMessage(topic, domain.AuctionResultEvent{AuctionID: 1, UserID: userID})
+	require.NoError(t, err)
+	require.NoError(t, (&fakeOutboxRepo{store: store}).Enqueue(context.Background(), message))
+}
+
+func TestOutboxDispatcherDeliversToAllSinks(t *testing.T) {
+	store := newFakeStore()
+	enqueueResult(t, store, domain.OutboxAuctionWon, 3)
+	enqueueResult(t, store, domain.OutboxAuctionLost, 2)
+	first, second := &fakeSink{}, &fakeSink{}
+	dispatcher := newTestDispatcher(store, first, second)
+
+	assert.Equal(t, 2, dispatcher.Dispatch(context.Background()))
+
+	assert.Len(t, first.received, 2)
+	assert.Len(t, second.received, 2)
+	for _, message := range store.state.outbox {
+		assert.NotNil(t, message.DeliveredAt)
+		assert.Equal(t, 1, message.Attempts)
+	}
+
+	// Доставленные сообщения больше не выбираются
+	assert.Zero(t, dispatcher.Dispatch(context.Background()))
+}
+
+func TestOutboxDispatcherRetriesWithBackoff(t *testing.T) {
+	store := newFakeStore()
+	enqueueResult(t, store, domain.OutboxAuctionWon, 3)
+	sink := &fakeSink{err: errors.New("smtp unavailable")}
+	dispatcher := newTestDispatcher(store, sink)
+
+	dispatcher.Dispatch(context.Background())
+
+	message := store.state.outbox[0]
+	assert.Nil(t, message.DeliveredAt)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Equal(t, "smtp unavailable", message.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Second), message.NextAttemptAt, 100*time.Millisecond)
+
+	// До наступления следующей попытки сообщение не выбирается
+	assert.Zero(t, dispatcher.Dispatch(context.Background()))
+
+	sink.err = nil
+	store.state.outbox[0].NextAttemptAt = time.Now()
+	assert.Equal(t, 1, dispatcher.Dispatch(context.Background()))
+	assert.NotNil(t, store.state.outbox[0].DeliveredAt)
+	assert.Len(t, sink.received, 2)
+}
+
+func TestOutboxDispatcherDropsAfterMaxAttempts(t *testing.T) {
+	store := newFakeStore()
+	enqueueResult(t, store, domain.OutboxAuctionWon, 3)
+	dispatcher := newTestDispatcher(store, &fakeSink{err: errors.New("smtp unavailable")})
+
+	for i := 0; i < 3; i++ {
+		store.state.outbox[0].NextAttemptAt = time.Now()
+		dispatcher.Dispatch(context.Background())
+	}
+
+	message := store.state.outbox[0]
+	assert.Equal(t, 3, message.Attempts)
+	assert.NotNil(t, message.FailedAt)
+	assert.Nil(t, message.DeliveredAt)
+
+	store.state.outbox[0].NextAttemptAt = time.Now()
+	assert.Zero(t, dispatcher.Dispatch(context.Background()))
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	dispatcher := newTestDispatcher(newFakeStore())
+
+	assert.Equal(t, time.Second, dispatcher.backoff(0))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(30))
+}
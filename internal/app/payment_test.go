@@ -0,0 +1,286 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/payment"
+	"auction/internal/interfaces/rpc"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmLocalPayment передаёт сервису подписанное уведомление локального провайдера
+func confirmLocalPayment(t *testing.T, service *AuctionService, provider *payment.LocalPaymentProvider,
+	intentID string, status domain.PaymentStatus) (domain.Payment, error) {
+	t.Helper()
+	header, body, err := provider.SignCallback(intentID, status)
+	require.NoError(t, err)
+	callback, err := provider.ParseCallback(header, body)
+	require.NoError(t, err)
+	return service.ConfirmPayment(context.Background(), provider.Name(), callback)
+}
+
+func TestPaymentCreditsBalanceOnlyAfterConfirmation(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+	provider := newTestPaymentProvider()
+	service := newFakeService(store, withPayments(provider))
+
+	created, err := service.CreatePayment(context.Background(), 2, 500)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentPending, created.Status)
+	assert.Equal(t, "local_1", created.ProviderPaymentID)
+	assert.Equal(t, "http://localhost:8080/v1/payments/local/local_1", created.CheckoutURL)
+	assert.Equal(t, int64(100), store.state.balances[2])
+	assert.Empty(t, store.state.ledger)
+
+	confirmed, err := confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentSucceeded, confirmed.Status)
+	assert.Equal(t, int64(600), store.state.balances[2])
+	require.Len(t, store.state.ledger, 1)
+	assert.Equal(t, domain.TransactionRefill, store.state.ledger[0].Type)
+	assert.Equal(t, created.PaymentID, *store.state.ledger[0].PaymentID)
+	require.Len(t, store.state.events, 3)
+	assert.Equal(t, domain.AuditPaymentCreated, store.state.events[0].Action)
+	assert.Equal(t, domain.AuditPaymentConfirmed, store.state.events[1].Action)
+	assert.JSONEq(t, `{"status": "pending", "amount": 500}`, string(store.state.events[1].Before))
+	assert.JSONEq(t, `{"status": "succeeded", "amount": 500}`, string(store.state.events[1].After))
+	assert.JSONEq(t, `{"balance": 600, "amount": 500, "payment_id": 1}`, string(store.state.events[2].After))
+
+	// Повторное уведомление не пополняет баланс второй раз
+	_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), store.state.balances[2])
+	assert.Len(t, store.state.ledger, 1)
+
+	stored, err := service.GetPayment(context.Background(), created.PaymentID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentSucceeded, stored.Status)
+	assert.NotNil(t, stored.ConfirmedAt)
+}
+
+func TestFailedPaymentDoesNotCredit(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+	provider := newTestPaymentProvider()
+	service := newFakeService(store, withPayments(provider))
+	created, err := service.CreatePayment(context.Background(), 2, 500)
+	require.NoError(t, err)
+
+	confirmed, err := confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentFailed)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentFailed, confirmed.Status)
+	assert.Equal(t, int64(100), store.state.balances[2])
+	assert.Empty(t, store.state.ledger)
+	require.Len(t, store.state.events, 2)
+	assert.Equal(t, domain.AuditPaymentConfirmed, store.state.events[1].Action)
+	assert.JSONEq(t, `{"status": "failed", "amount": 500}`, string(store.state.events[1].After))
+
+	// Повторный отказ ничего не меняет
+	_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentFailed)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), store.state.balances[2])
+}
+
+func TestPaymentSucceededAfterFailureCreditsOnce(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+	provider := newTestPaymentProvider()
+	service := newFakeService(store, withPayments(provider))
+	created, err := service.CreatePayment(context.Background(), 2, 500)
+	require.NoError(t, err)
+	_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentFailed)
+	require.NoError(t, err)
+
+	// Провайдер провёл оплату после отказа: деньги списаны, поэтому баланс пополняется
+	confirmed, err := confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PaymentSucceeded, confirmed.Status)
+	assert.Equal(t, int64(600), store.state.balances[2])
+	require.Len(t, store.state.ledger, 1)
+	assert.Equal(t, created.PaymentID, *store.state.ledger[0].PaymentID)
+
+	// Повторы и запоздалый отказ уже ничего не меняют
+	_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+	require.NoError(t, err)
+	_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentFailed)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), store.state.balances[2])
+	assert.Len(t, store.state.ledger, 1)
+	assert.Equal(t, domain.PaymentSucceeded, store.state.payments[created.PaymentID].Status)
+}
+
+func TestConfirmPaymentRejectsMismatchedAmount(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+	service := newFakeService(store)
+	created, err := service.CreatePayment(context.Background(), 2, 500)
+	require.NoError(t, err)
+	initial := store.state.clone()
+
+	_, err = service.ConfirmPayment(context.Background(), "local", domain.PaymentCallback{
+		ProviderPaymentID: created.ProviderPaymentID,
+		Status:            domain.PaymentSucceeded,
+		Amount:            50,
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPaymentAmountMismatch)
+	assert.Equal(t, initial, store.state)
+
+	_, err = service.ConfirmPayment(context.Background(), "other", domain.PaymentCallback{
+		ProviderPaymentID: created.ProviderPaymentID,
+		Status:            domain.PaymentSucceeded,
+		Amount:            500,
+	})
+	assert.ErrorIs(t, err, domain.ErrPaymentNotFound)
+}
+
+func TestConfirmPaymentRollsBackOnFailure(t *testing.T) {
+	steps := []string{"payment.confirm", "balance.credit", "ledger.record", "audit.append"}
+
+	for _, step := range steps {
+		t.Run(step, func(t *testing.T) {
+			store := newFakeStore()
+			store.state.balances[2] = 100
+			provider := newTestPaymentProvider()
+			service := newFakeService(store, withPayments(provider))
+			created, err := service.CreatePayment(context.Background(), 2, 500)
+			require.NoError(t, err)
+			initial := store.state.clone()
+
+			store.failures[step] = errors.New("injected failure")
+			_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+
+			require.ErrorContains(t, err, "injected failure")
+			assert.Equal(t, initial, store.state)
+
+			// Провайдер повторит уведомление, и платёж будет зачислен
+			delete(store.failures, step)
+			_, err = confirmLocalPayment(t, service, provider, created.ProviderPaymentID, domain.PaymentSucceeded)
+			require.NoError(t, err)
+			assert.Equal(t, int64(600), store.state.balances[2])
+		})
+	}
+}
+
+// failingPaymentProvider не может создать намерение оплаты
+type failingPaymentProvider struct {
+	payment.PaymentProvider
+}
+
+func (failingPaymentProvider) Name() string { return "failing" }
+
+func (failingPaymentProvider) CreateIntent(context.Context, domain.Payment) (payment.PaymentIntent, error) {
+	return payment.PaymentIntent{}, errors.New("provider unavailable")
+}
+
+func TestCreatePaymentProviderFailure(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+	service := newFakeService(store, withPayments(failingPaymentProvider{}))
+
+	_, err := service.CreatePayment(context.Background(), 2, 500)
+
+	require.ErrorContains(t, err, "provider unavailable")
+	assert.Equal(t, domain.PaymentFailed, store.state.payments[1].Status)
+	require.Len(t, store.state.events, 2)
+	assert.Equal(t, domain.AuditPaymentConfirmed, store.state.events[1].Action)
+
+	_, err = service.CreatePayment(context.Background(), 2, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidPaymentAmount)
+}
+
+// tokenVerifier принимает токены из списка
+type tokenVerifier map[string]auth.User
+
+func (v tokenVerifier) Verify(token string) (auth.User, error) {
+	user, ok := v[token]
+	if !ok {
+		return auth.User{}, errors.New("invalid token")
+	}
+	return user, nil
+}
+
+func TestLocalCheckoutSendsSignedCallback(t *testing.T) {
+	store := newFakeStore()
+	store.state.balances[2] = 100
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	provider := payment.NewLocalPaymentProvider("secret", server.URL, server.Client(), discardLogger())
+	service := newFakeService(store, withPayments(provider))
+	verifier := tokenVerifier{"owner": {ID: 2}, "stranger": {ID: 3}}
+	mux.Handle("POST "+payment.CallbackPath, rpc.NewPaymentCallbackHandler(service, provider, discardLogger()))
+	mux.Handle(payment.LocalCheckoutPattern, rpc.AuthHTTPHandler(verifier, provider.CheckoutHandler()))
+
+	created, err := service.CreatePayment(context.Background(), 2, 500)
+	require.NoError(t, err)
+
+	// Уведомление с неверной подписью отклоняется
+	resp, err := server.Client().Post(server.URL+payment.CallbackPath, "application/json",
+		strings.NewReader(`{"payment_id": "local_1", "status": "succeeded", "amount": 500}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, int64(100), store.state.balances[2])
+
+	checkout := func(token string) int {
+		req, err := http.NewRequest(http.MethodPost, created.CheckoutURL, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Оплатить платёж может только его владелец
+	assert.Equal(t, http.StatusUnauthorized, checkout(""))
+	assert.Equal(t, http.StatusUnauthorized, checkout("forged"))
+	assert.Equal(t, http.StatusNotFound, checkout("stranger"))
+	assert.Equal(t, int64(100), store.state.balances[2])
+	assert.Equal(t, domain.PaymentPending, store.state.payments[created.PaymentID].Status)
+
+	assert.Equal(t, http.StatusOK, checkout("owner"))
+	assert.Equal(t, int64(600), store.state.balances[2])
+	assert.Equal(t, domain.PaymentSucceeded, store.state.payments[created.PaymentID].Status)
+}
+
+func TestLocalPaymentProvidersRequireOptIn(t *testing.T) {
+	cfg := Payments{Provider: "local", PayoutProvider: "local", CallbackSecret: "secret"}
+
+	// Без явной настройки провайдер не выбирается
+	_, err := NewPaymentProvider(Payments{CallbackSecret: "secret"}, discardLogger())
+	assert.ErrorContains(t, err, "payments.provider is required")
+	_, err = NewPayoutProvider(Payments{}, discardLogger())
+	assert.ErrorContains(t, err, "payments.payout_provider is required")
+
+	_, err = NewPaymentProvider(cfg, discardLogger())
+	assert.ErrorIs(t, err, errLocalPaymentsDisabled)
+	_, err = NewPayoutProvider(cfg, discardLogger())
+	assert.ErrorIs(t, err, errLocalPaymentsDisabled)
+
+	cfg.AllowLocal = true
+	provider, err := NewPaymentProvider(cfg, discardLogger())
+	require.NoError(t, err)
+	assert.Equal(t, "local", provider.Name())
+	_, err = NewPayoutProvider(cfg, discardLogger())
+	require.NoError(t, err)
+
+	// Секрет из примера конфигурации не принимается
+	cfg.CallbackSecret = placeholderCallbackSecret
+	_, err = NewPaymentProvider(cfg, discardLogger())
+	assert.ErrorContains(t, err, "must be changed")
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	store := newFakeStore()
+	service := newFakeService(store)
+	ctx := context.Background()
+
+	preferences, err := service.GetNotificationPreferences(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationPreferences{UserID: 1}, preferences)
+
+	updated := domain.NotificationPreferences{
+		UserID:         1,
+		DisabledEvents: []domain.NotificationEvent{domain.EventNewAuctions},
+		EventChannels:  map[domain.NotificationEvent][]domain.NotificationChannel{domain.EventOutbid: {domain.ChannelEmail}},
+		QuietHours:     &domain.QuietHours{StartMinute: 23 * 60, EndMinute: 7 * 60, Timezone: "Europe/Moscow"},
+	}
+	require.NoError(t, service.UpdateNotificationPreferences(ctx, updated))
+
+	preferences, err = service.GetNotificationPreferences(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, updated, preferences)
+
+	invalid := domain.NotificationPreferences{UserID: 1, DisabledEvents: []domain.NotificationEvent{"lot_created"}}
+	assert.ErrorIs(t, service.UpdateNotificationPreferences(ctx, invalid), domain.ErrInvalidNotificationEvent)
+	assert.Equal(t, updated, store.state.preferences[1])
+
+	// Канал webhook для события требует адреса в настройках уведомлений
+	webhook := domain.NotificationPreferences{
+		UserID:        1,
+		EventChannels: map[domain.NotificationEvent][]domain.NotificationChannel{domain.EventOutbid: {domain.ChannelWebhook}},
+	}
+	assert.ErrorIs(t, service.UpdateNotificationPreferences(ctx, webhook), domain.ErrInvalidWebhookURL)
+	assert.Equal(t, updated, store.state.preferences[1])
+
+	store.webhookURLs[1] = "https://example.com/hook"
+	require.NoError(t, service.UpdateNotificationPreferences(ctx, webhook))
+	assert.Equal(t, webhook, store.state.preferences[1])
+}
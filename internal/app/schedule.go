@@ -0,0 +1,121 @@
+package app
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// ClosingSchedule - очередь ближайших закрытий аукционов в памяти. Сервис обновляет её
+// при создании и отмене аукционов, обработчик закрывает аукционы точно в срок.
+type ClosingSchedule struct {
+	mu      sync.Mutex
+	heap    closingHeap
+	entries map[int]*closingEntry
+	changed chan struct{}
+}
+
+type closingEntry struct {
+	auctionID int
+	closeAt   time.Time
+	index     int
+}
+
+func NewClosingSchedule() *ClosingSchedule {
+	return &ClosingSchedule{
+		entries: make(map[int]*closingEntry),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// Set добавляет аукцион в очередь или переносит время его закрытия
+func (s *ClosingSchedule) Set(auctionID int, closeAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[auctionID]; ok {
+		entry.closeAt = closeAt
+		heap.Fix(&s.heap, entry.index)
+	} else {
+		entry := &closingEntry{auctionID: auctionID, closeAt: closeAt}
+		heap.Push(&s.heap, entry)
+		s.entries[auctionID] = entry
+	}
+	s.notify()
+}
+
+// Remove убирает аукцион из очереди, например после отмены
+func (s *ClosingSchedule) Remove(auctionID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[auctionID]; ok {
+		heap.Remove(&s.heap, entry.index)
+		delete(s.entries, auctionID)
+		s.notify()
+	}
+}
+
+// Next возвращает время ближайшего закрытия
+func (s *ClosingSchedule) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.heap) == 0 {
+		return time.Time{}, false
+	}
+	return s.heap[0].closeAt, true
+}
+
+// PopDue извлекает аукционы, время закрытия которых наступило к now
+func (s *ClosingSchedule) PopDue(now time.Time) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []int
+	for len(s.heap) > 0 && !s.heap[0].closeAt.After(now) {
+		entry := heap.Pop(&s.heap).(*closingEntry)
+		delete(s.entries, entry.auctionID)
+		due = append(due, entry.auctionID)
+	}
+	return due
+}
+
+// Changed сигнализирует, что ближайшее закрытие могло измениться
+func (s *ClosingSchedule) Changed() <-chan struct{} {
+	return s.changed
+}
+
+func (s *ClosingSchedule) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+type closingHeap []*closingEntry
+
+func (h closingHeap) Len() int { return len(h) }
+
+func (h closingHeap) Less(i, j int) bool { return h[i].closeAt.Before(h[j].closeAt) }
+
+func (h closingHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *closingHeap) Push(x any) {
+	entry := x.(*closingEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *closingHeap) Pop() any {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
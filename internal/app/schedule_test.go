@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClosingScheduleOrder(t *testing.T) {
+	base := time.Now()
+	schedule := NewClosingSchedule()
+	schedule.Set(1, base.Add(3*time.Second))
+	schedule.Set(2, base.Add(1*time.Second))
+	schedule.Set(3, base.Add(2*time.Second))
+
+	next, ok := schedule.Next()
+	require.True(t, ok)
+	assert.Equal(t, base.Add(time.Second), next)
+
+	// Перенос закрытия и отмена
+	schedule.Set(1, base.Add(500*time.Millisecond))
+	schedule.Remove(3)
+
+	assert.Empty(t, schedule.PopDue(base))
+	assert.Equal(t, []int{1}, schedule.PopDue(base.Add(time.Second-time.Nanosecond)))
+	assert.Equal(t, []int{2}, schedule.PopDue(base.Add(10*time.Second)))
+
+	_, ok = schedule.Next()
+	assert.False(t, ok)
+}
+
+func TestClosingScheduleSignalsChanges(t *testing.T) {
+	schedule := NewClosingSchedule()
+	schedule.Set(1, time.Now())
+	schedule.Set(2, time.Now())
+
+	select {
+	case <-schedule.Changed():
+	default:
+		t.Fatal("expected change signal")
+	}
+	select {
+	case <-schedule.Changed():
+		t.Fatal("signals must be coalesced")
+	default:
+	}
+}
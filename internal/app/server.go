@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+// Server - сетевой сервер приложения. Serve блокируется до остановки, Shutdown перестаёт
+// принимать новые запросы и ждёт завершения текущих не дольше дедлайна ctx.
+type Server interface {
+	Serve() error
+	Shutdown(ctx context.Context) error
+}
+
+type grpcServer struct {
+	server *grpc.Server
+	addr   string
+	log    *slog.Logger
+}
+
+func newGRPCServer(server *grpc.Server, addr string, log *slog.Logger) *grpcServer {
+	return &grpcServer{server: server, addr: addr, log: log}
+}
+
+func (s *grpcServer) Serve() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.log.Info("starting gRPC server", "addr", lis.Addr().String())
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown дожидается текущих вызовов, а по дедлайну обрывает их
+func (s *grpcServer) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+type httpServer struct {
+	server *http.Server
+	// cancel закрывает соединение шлюза с gRPC-сервером
+	cancel context.CancelFunc
+	log    *slog.Logger
+}
+
+func newHTTPServer(server *http.Server, cancel context.CancelFunc, log *slog.Logger) *httpServer {
+	return &httpServer{server: server, cancel: cancel, log: log}
+}
+
+func (s *httpServer) Serve() error {
+	lis, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.log.Info("starting HTTP/REST gateway", "addr", lis.Addr().String())
+	if err := s.server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	defer s.cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		// Оставшиеся соединения закрываются принудительно
+		_ = s.server.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSettlementStore() *fakeStore {
+	closedAt := time.Now().Add(-time.Minute)
+	store := newFakeStore()
+	store.state.balances = map[int]int64{2: 1000, 3: 1000, 4: 1000, 10: 0}
+	store.state.auctions[1] = domain.Auction{AuctionID: 1, ClosedAt: &closedAt}
+	store.state.lots[5] = domain.Lot{LotID: 5, AuctionID: 1, UserID: 10}
+	store.state.bids = []domain.Bid{
+		{BidID: 1, AuctionID: 1, UserID: 2, Price: 100},
+		{BidID: 2, AuctionID: 1, UserID: 3, Price: 120},
+		{BidID: 3, AuctionID: 1, UserID: 2, Price: 130},
+		{BidID: 4, AuctionID: 1, UserID: 4, Price: 140},
+		{BidID: 5, AuctionID: 1, UserID: 3, Price: 150},
+	}
+	return store
+}
+
+func TestSettleAuction(t *testing.T) {
+	store := newSettlementStore()
+	service := newFakeService(store)
+
+	settlement, created, err := service.SettleAuction(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 3, *settlement.WinnerID)
+	assert.Equal(t, 5, *settlement.WinningBidID)
+	assert.Equal(t, int64(150), settlement.Price)
+	assert.ElementsMatch(t, []int{2, 4}, settlement.Losers)
+
+	assert.Equal(t, map[int]int64{2: 1000, 3: 850, 4: 1000, 10: 150}, store.state.balances)
+	assert.Equal(t, 3, *store.state.auctions[1].WinnerID)
+	assert.Contains(t, store.state.settlements, 1)
+	require.Len(t, store.state.events, 1)
+	assert.Equal(t, domain.AuditAuctionSettled, store.state.events[0].Action)
+
+	require.Len(t, store.state.outbox, 4)
+	assert.Equal(t, domain.OutboxAuctionSettled, store.state.outbox[0].Topic)
+	var settled domain.AuctionSettledEvent
+	require.NoError(t, json.Unmarshal(store.state.outbox[0].Payload, &settled))
+	assert.Equal(t, 3, *settled.WinnerID)
+	assert.Equal(t, int64(150), settled.Price)
+	assert.Equal(t, domain.OutboxAuctionWon, store.state.outbox[1].Topic)
+	assert.JSONEq(t, `{"auction_id": 1, "user_id": 3, "price": 150}`, string(store.state.outbox[1].Payload))
+	assert.Equal(t, domain.OutboxAuctionLost, store.state.outbox[2].Topic)
+	assert.JSONEq(t, `{"auction_id": 1, "user_id": 2, "price": 150}`, string(store.state.outbox[2].Payload))
+	assert.Equal(t, domain.OutboxAuctionLost, store.state.outbox[3].Topic)
+	assert.JSONEq(t, `{"auction_id": 1, "user_id": 4, "price": 150}`, string(store.state.outbox[3].Payload))
+}
+
+func TestSettleAuctionIsIdempotent(t *testing.T) {
+	store := newSettlementStore()
+	service := newFakeService(store)
+
+	first, created, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	second, created, err := service.SettleAuction(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int64(850), store.state.balances[3])
+	assert.Len(t, store.state.events, 1)
+	assert.Len(t, store.state.outbox, 4)
+}
+
+func TestSettleAuctionRollsBackOnFailure(t *testing.T) {
+	steps := []string{"settlement.create", "balance.debit", "balance.credit", "ledger.record", "balance.refund", "auction.close", "outbox.enqueue", "audit.append"}
+
+	for _, step := range steps {
+		t.Run(step, func(t *testing.T) {
+			store := newSettlementStore()
+			initial := store.state.clone()
+			service := newFakeService(store)
+
+			store.failures[step] = errors.New("injected failure")
+			_, _, err := service.SettleAuction(context.Background(), 1)
+
+			require.ErrorContains(t, err, "injected failure")
+			assert.Equal(t, initial, store.state)
+
+			// После устранения сбоя аукцион рассчитывается ровно один раз
+			delete(store.failures, step)
+			_, created, err := service.SettleAuction(context.Background(), 1)
+			require.NoError(t, err)
+			assert.True(t, created)
+			assert.Equal(t, int64(850), store.state.balances[3])
+		})
+	}
+}
+
+func TestSettleAuctionPaysSellerMinusCommission(t *testing.T) {
+	store := newSettlementStore()
+	commission := domain.Commission{Tiers: []domain.CommissionTier{{BasisPoints: 1000, FixedFee: 5}}}
+	service := newFakeService(store, withCommission(commission))
+
+	settlement, _, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 10, *settlement.SellerID)
+	assert.Equal(t, int64(20), settlement.Fee)
+	assert.Equal(t, int64(130), settlement.SellerPayout())
+	assert.Equal(t, int64(850), store.state.balances[3])
+	assert.Equal(t, int64(130), store.state.balances[10])
+
+	auctionID := 1
+	at := settlement.SettledAt
+	assert.Equal(t, []domain.BalanceTransaction{
+		{TransactionID: 1, UserID: 3, Type: domain.TransactionCharge, Amount: -150, AuctionID: &auctionID, BalanceAfter: 850, CreatedAt: at},
+		{TransactionID: 2, UserID: 10, Type: domain.TransactionPayout, Amount: 150, AuctionID: &auctionID, BalanceAfter: 150, CreatedAt: at},
+		{TransactionID: 3, UserID: 10, Type: domain.TransactionFee, Amount: -20, AuctionID: &auctionID, BalanceAfter: 130, CreatedAt: at},
+	}, store.state.ledger)
+
+	stored, err := service.GetSettlement(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, settlement, stored)
+}
+
+func TestSettleAuctionWithoutBids(t *testing.T) {
+	store := newSettlementStore()
+	store.state.bids = nil
+	service := newFakeService(store)
+
+	settlement, created, err := service.SettleAuction(context.Background(), 1)
+
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.False(t, settlement.Sold())
+	assert.Nil(t, store.state.auctions[1].WinnerID)
+	assert.Equal(t, map[int]int64{2: 1000, 3: 1000, 4: 1000, 10: 0}, store.state.balances)
+	assert.Empty(t, store.state.ledger)
+}
+
+func TestSettleAuctionWinnerCannotPay(t *testing.T) {
+	store := newSettlementStore()
+	store.state.balances[3] = 100
+	service := newFakeService(store)
+
+	settlement, created, err := service.SettleAuction(context.Background(), 1)
+
+	// Лот достаётся следующему по цене участнику
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 4, *settlement.WinnerID)
+	assert.Equal(t, 4, *settlement.WinningBidID)
+	assert.Equal(t, int64(140), settlement.Price)
+	assert.Equal(t, []int{2, 3}, settlement.Losers)
+	assert.Equal(t, map[int]int64{2: 1000, 3: 100, 4: 860, 10: 140}, store.state.balances)
+	assert.Equal(t, 4, *store.state.auctions[1].WinnerID)
+	require.Len(t, store.state.events, 1)
+	assert.JSONEq(t, `{"winner_id": 4, "price": 140, "losers": [2, 3], "defaulted": [3]}`, string(store.state.events[0].After))
+}
+
+func TestSettleAuctionNobodyCanPay(t *testing.T) {
+	store := newSettlementStore()
+	store.state.balances = map[int]int64{2: 10, 3: 10, 4: 10, 10: 0}
+	service := newFakeService(store)
+
+	settlement, created, err := service.SettleAuction(context.Background(), 1)
+
+	// Аукцион рассчитывается как непроданный и больше не попадает в очередь расчёта
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.False(t, settlement.Sold())
+	assert.Contains(t, store.state.settlements, 1)
+	assert.Nil(t, store.state.auctions[1].WinnerID)
+	assert.Equal(t, map[int]int64{2: 10, 3: 10, 4: 10, 10: 0}, store.state.balances)
+	assert.Empty(t, store.state.ledger)
+	require.Len(t, store.state.events, 1)
+	assert.JSONEq(t, `{"winner_id": null, "defaulted": [3, 4, 2]}`, string(store.state.events[0].After))
+	require.Len(t, store.state.outbox, 1)
+	assert.Equal(t, domain.OutboxAuctionSettled, store.state.outbox[0].Topic)
+
+	_, created, err = service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	assert.False(t, created)
+}
+
+func TestSettleCancelledAuction(t *testing.T) {
+	store := newSettlementStore()
+	auction := store.state.auctions[1]
+	cancelledAt := time.Now()
+	auction.CancelledAt = &cancelledAt
+	store.state.auctions[1] = auction
+	service := newFakeService(store)
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrAuctionCancelled)
+	assert.Empty(t, store.state.settlements)
+}
+
+func TestCancelSettledAuction(t *testing.T) {
+	store := newSettlementStore()
+	store.state.bids = nil
+	service := newFakeService(store)
+	settlement, _, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	require.Nil(t, settlement.WinnerID)
+
+	// Непроданный аукцион рассчитан без победителя, но отменить его уже нельзя
+	err = service.CancelAuction(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrAuctionSettled)
+	assert.Nil(t, store.state.auctions[1].CancelledAt)
+}
+
+func TestCancelAuctionDuringSettlement(t *testing.T) {
+	store := newSettlementStore()
+	service := newFakeService(store)
+	store.failures["auction.lock"] = domain.ErrAuctionBusy
+
+	err := service.CancelAuction(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrAuctionBusy)
+	assert.Nil(t, store.state.auctions[1].CancelledAt)
+
+	delete(store.failures, "auction.lock")
+	require.NoError(t, service.CancelAuction(context.Background(), 1))
+	assert.NotNil(t, store.state.auctions[1].CancelledAt)
+	require.Len(t, store.state.events, 1)
+	assert.Equal(t, domain.AuditAuctionCancelled, store.state.events[0].Action)
+}
+
+func TestSettleAuctionBeforeClosing(t *testing.T) {
+	store := newSettlementStore()
+	auction := store.state.auctions[1]
+	closedAt := time.Now().Add(time.Minute)
+	auction.ClosedAt = &closedAt
+	store.state.auctions[1] = auction
+	service := newFakeService(store)
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrAuctionNotClosed)
+	assert.Empty(t, store.state.settlements)
+}
+
+func TestSettleAuctionClaimedByAnotherWorker(t *testing.T) {
+	store := newSettlementStore()
+	store.failures["auction.lock"] = domain.ErrAuctionBusy
+	service := newFakeService(store)
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+
+	assert.ErrorIs(t, err, domain.ErrAuctionBusy)
+	assert.Empty(t, store.state.settlements)
+	assert.Equal(t, int64(1000), store.state.balances[3])
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/repo"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ShillDetector ищет признаки подставных ставок и добавляет подозрительных участников
+// в очередь ручной проверки. Получает события bid.placed из outbox, чтобы запросы
+// статистики не замедляли приём ставок.
+type ShillDetector struct {
+	repo  repo.ShillRepository
+	rules domain.ShillRules
+}
+
+func NewShillDetector(repo repo.ShillRepository, rules domain.ShillRules) *ShillDetector {
+	return &ShillDetector{
+		repo:  repo,
+		rules: rules,
+	}
+}
+
+// Handle проверяет автора новой ставки и участника, чью ставку она перебила
+func (d *ShillDetector) Handle(ctx context.Context, message domain.OutboxMessage) error {
+	if message.Topic != domain.OutboxBidPlaced {
+		return nil
+	}
+	var event domain.BidPlacedEvent
+	if err := decodePayload(message, &event); err != nil {
+		return err
+	}
+
+	raisesPrice := event.OutbidUserID != nil
+	if err := d.inspectUser(ctx, event.UserID, event, raisesPrice); err != nil {
+		return err
+	}
+
+	// Перебитая ставка могла оказаться очередной ставкой "чуть ниже"
+	if raisesPrice {
+		return d.inspectUser(ctx, *event.OutbidUserID, event, false)
+	}
+	return nil
+}
+
+func (d *ShillDetector) inspectUser(ctx context.Context, userID int, event domain.BidPlacedEvent, raisesPrice bool) error {
+	stats, err := d.repo.GetBidderStats(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load bid stats of user %d: %w", userID, err)
+	}
+
+	for _, signal := range domain.DetectShillBidding(stats, d.rules, raisesPrice, time.Now()) {
+		review := domain.ShillReview{
+			UserID:    userID,
+			SellerID:  &event.SellerID,
+			LotID:     &event.LotID,
+			Reason:    signal.Reason,
+			Details:   signal.Details,
+			Status:    domain.ShillReviewPending,
+			CreatedAt: time.Now(),
+		}
+		if signal.Reason == domain.ShillSingleSeller {
+			review.SellerID = &stats.TopSellerID
+		}
+
+		// Повторная доставка события не дублирует открытую запись: CreateReview её пропускает
+		if err := d.repo.CreateReview(ctx, review); err != nil {
+			return fmt.Errorf("failed to flag user %d as %s: %w", userID, signal.Reason, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShillDetectorInspectsPlacedBids(t *testing.T) {
+	store := newFakeStore()
+	store.state.auctions[1] = domain.Auction{AuctionID: 1}
+	store.state.lots[5] = domain.Lot{LotID: 5, AuctionID: 1, UserID: 10, StartPrice: 50, Step: 10}
+	store.state.balances[2] = 10000
+	store.state.balances[3] = 10000
+	service := newFakeService(store)
+
+	_, err := service.PlaceBid(context.Background(), domain.Bid{LotID: 5, UserID: 2, Price: 100})
+	require.NoError(t, err)
+	_, err = service.PlaceBid(context.Background(), domain.Bid{LotID: 5, UserID: 3, Price: 110})
+	require.NoError(t, err)
+
+	events := outboxEvents[domain.BidPlacedEvent](t, store, domain.OutboxBidPlaced)
+	require.Len(t, events, 2)
+	assert.Nil(t, events[0].OutbidUserID)
+	assert.Equal(t, 10, events[1].SellerID)
+	require.NotNil(t, events[1].OutbidUserID)
+	assert.Equal(t, 2, *events[1].OutbidUserID)
+
+	shill := &fakeShillRepo{stats: map[int]domain.BidderStats{
+		// Новый аккаунт поднимает цену
+		3: {UserID: 3, AccountCreatedAt: time.Now().Add(-time.Hour), TotalBids: 1, TopSellerID: 10, TopSellerBids: 1},
+		// Перебитый участник ставит только на лоты одного продавца
+		2: {UserID: 2, AccountCreatedAt: time.Now().Add(-time.Hour), TotalBids: 6, TopSellerID: 10, TopSellerBids: 6},
+	}}
+	detector := NewShillDetector(shill, domain.ShillRules{MinBids: 5, SingleSellerShare: 1, JustBelowShare: 0.8, NewAccountAge: 72 * time.Hour})
+	// Первую ставку пропускаем: проверяется вторая и события других тем
+	require.Equal(t, domain.OutboxBidPlaced, store.state.outbox[0].Topic)
+	for _, message := range store.state.outbox[1:] {
+		require.NoError(t, detector.Handle(context.Background(), message))
+	}
+
+	require.Len(t, shill.reviews, 2)
+	assert.Equal(t, 3, shill.reviews[0].UserID)
+	assert.Equal(t, domain.ShillNewAccount, shill.reviews[0].Reason)
+	assert.Equal(t, 5, *shill.reviews[0].LotID)
+	assert.Equal(t, 2, shill.reviews[1].UserID)
+	assert.Equal(t, domain.ShillSingleSeller, shill.reviews[1].Reason)
+
+	// Ошибка статистики возвращается, чтобы outbox повторил доставку
+	delete(shill.stats, 2)
+	err = detector.Handle(context.Background(), store.state.outbox[0])
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
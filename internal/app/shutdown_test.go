@@ -0,0 +1,158 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lifecycle записывает порядок остановки компонентов
+type lifecycle struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *lifecycle) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *lifecycle) recorded() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+type fakeServer struct {
+	name     string
+	log      *lifecycle
+	serveErr error
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+func newFakeServer(name string, log *lifecycle) *fakeServer {
+	return &fakeServer{name: name, log: log, stopped: make(chan struct{})}
+}
+
+func (s *fakeServer) Serve() error {
+	if s.serveErr != nil {
+		return s.serveErr
+	}
+	<-s.stopped
+	return nil
+}
+
+func (s *fakeServer) Shutdown(context.Context) error {
+	s.log.record(s.name + " shutdown")
+	s.once.Do(func() { close(s.stopped) })
+	return nil
+}
+
+type fakeLifecycleWorker struct {
+	name    string
+	log     *lifecycle
+	stopFor time.Duration
+}
+
+func (w *fakeLifecycleWorker) Start() {
+	w.log.record(w.name + " start")
+}
+
+func (w *fakeLifecycleWorker) Stop(ctx context.Context) error {
+	select {
+	case <-time.After(w.stopFor):
+	case <-ctx.Done():
+		w.log.record(w.name + " cancelled")
+		return ctx.Err()
+	}
+	w.log.record(w.name + " stop")
+	return nil
+}
+
+type fakeCloser struct {
+	log *lifecycle
+	err error
+}
+
+func (c fakeCloser) Close() error {
+	c.log.record("db close")
+	return c.err
+}
+
+func newLifecycleApp(events *lifecycle, timeout time.Duration, servers ...Server) *App {
+	return &App{
+		Cfg:     Config{ShutdownTimeout: timeout},
+		Log:     discardLogger(),
+		servers: servers,
+		workers: []Worker{&fakeLifecycleWorker{name: "worker", log: events, stopFor: 10 * time.Millisecond}},
+		closers: []closer{{name: "database", Closer: fakeCloser{log: events}}},
+	}
+}
+
+func TestAppShutdownSequence(t *testing.T) {
+	events := &lifecycle{}
+	app := newLifecycleApp(events, time.Second, newFakeServer("http", events), newFakeServer("grpc", events))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- app.run(ctx) }()
+
+	require.Eventually(t, func() bool { return len(events.recorded()) == 1 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("run did not return after shutdown signal")
+	}
+	assert.Equal(t, []string{"worker start", "http shutdown", "grpc shutdown", "worker stop", "db close"}, events.recorded())
+}
+
+func TestAppShutdownOnServerError(t *testing.T) {
+	events := &lifecycle{}
+	failing := newFakeServer("http", events)
+	failing.serveErr = errors.New("address already in use")
+	app := newLifecycleApp(events, time.Second, failing, newFakeServer("grpc", events))
+
+	err := app.run(context.Background())
+
+	assert.ErrorContains(t, err, "address already in use")
+	assert.Equal(t, "db close", events.recorded()[len(events.recorded())-1])
+}
+
+func TestAppShutdownDeadline(t *testing.T) {
+	events := &lifecycle{}
+	app := newLifecycleApp(events, 20*time.Millisecond, newFakeServer("grpc", events))
+	app.workers = []Worker{&fakeLifecycleWorker{name: "worker", log: events, stopFor: time.Hour}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	require.NoError(t, app.run(ctx))
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, []string{"worker start", "grpc shutdown", "worker cancelled", "db close"}, events.recorded())
+}
+
+func TestAppShutdownLogsFailedCloser(t *testing.T) {
+	events := &lifecycle{}
+	var buf bytes.Buffer
+	app := newLifecycleApp(events, time.Second)
+	app.Log = slog.New(slog.NewTextHandler(&buf, nil))
+	app.closers = []closer{{name: "tracing exporter", Closer: fakeCloser{log: events, err: errors.New("boom")}}}
+
+	app.shutdown()
+
+	assert.Contains(t, buf.String(), `msg="failed to close tracing exporter" error=boom`)
+}
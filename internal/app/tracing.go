@@ -0,0 +1,337 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "auction/internal/app"
+
+// tracer берётся из глобального провайдера при каждом вызове, чтобы учитывать провайдер,
+// установленный после создания сервиса
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// endSpan отмечает ошибку в span и завершает его
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// NewSpanExporter создаёт экспортёр по cfg.Exporter: otlp отправляет span'ы в коллектор
+// по gRPC, stdout пишет их в out. Для none возвращает nil - трассировка выключена.
+func NewSpanExporter(ctx context.Context, cfg Tracing, out io.Writer) (sdktrace.SpanExporter, error) {
+	cfg = cfg.WithDefaults()
+	switch cfg.Exporter {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(out))
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.Exporter)
+	}
+}
+
+// NewTracerProvider отправляет span'ы в exporter пакетами с долей сэмплирования cfg.SampleRatio.
+// Решение родительского span'а из входящего запроса сохраняется.
+func NewTracerProvider(cfg Tracing, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	cfg = cfg.WithDefaults()
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+	)
+}
+
+// TracedAuctionService оборачивает каждый метод сервиса в span
+type TracedAuctionService struct {
+	next domain.AuctionService
+}
+
+var _ domain.AuctionService = (*TracedAuctionService)(nil)
+
+func NewTracedAuctionService(next domain.AuctionService) *TracedAuctionService {
+	return &TracedAuctionService{next: next}
+}
+
+func (s *TracedAuctionService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "AuctionService."+method, trace.WithAttributes(attrs...))
+}
+
+func (s *TracedAuctionService) CreateLot(ctx context.Context, lot domain.Lot) (int, error) {
+	ctx, span := s.start(ctx, "CreateLot", attribute.Int("user.id", lot.UserID))
+	lotID, err := s.next.CreateLot(ctx, lot)
+	span.SetAttributes(attribute.Int("lot.id", lotID))
+	endSpan(span, err)
+	return lotID, err
+}
+
+func (s *TracedAuctionService) RefillBalance(ctx context.Context, userID int, amount int64) error {
+	ctx, span := s.start(ctx, "RefillBalance", attribute.Int("user.id", userID))
+	err := s.next.RefillBalance(ctx, userID, amount)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) PlaceBid(ctx context.Context, bid domain.Bid) (int, error) {
+	ctx, span := s.start(ctx, "PlaceBid", attribute.Int("lot.id", bid.LotID), attribute.Int("user.id", bid.UserID))
+	bidID, err := s.next.PlaceBid(ctx, bid)
+	span.SetAttributes(attribute.Int("bid.id", bidID))
+	endSpan(span, err)
+	return bidID, err
+}
+
+func (s *TracedAuctionService) CancelAuction(ctx context.Context, auctionID int) error {
+	ctx, span := s.start(ctx, "CancelAuction", attribute.Int("auction.id", auctionID))
+	err := s.next.CancelAuction(ctx, auctionID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) ListShillReviews(ctx context.Context, status domain.ShillReviewStatus, limit, offset int) ([]domain.ShillReview, error) {
+	ctx, span := s.start(ctx, "ListShillReviews")
+	reviews, err := s.next.ListShillReviews(ctx, status, limit, offset)
+	endSpan(span, err)
+	return reviews, err
+}
+
+func (s *TracedAuctionService) ResolveShillReview(ctx context.Context, reviewID int, status domain.ShillReviewStatus, adminID int) error {
+	ctx, span := s.start(ctx, "ResolveShillReview", attribute.Int("review.id", reviewID))
+	err := s.next.ResolveShillReview(ctx, reviewID, status, adminID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	ctx, span := s.start(ctx, "ListAuditEvents")
+	events, err := s.next.ListAuditEvents(ctx, filter)
+	endSpan(span, err)
+	return events, err
+}
+
+func (s *TracedAuctionService) GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]domain.Auction, error) {
+	ctx, span := s.start(ctx, "GetCompletedAuctionsWithoutWinner")
+	auctions, err := s.next.GetCompletedAuctionsWithoutWinner(ctx)
+	endSpan(span, err)
+	return auctions, err
+}
+
+func (s *TracedAuctionService) GetUnsettledClosingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error) {
+	ctx, span := s.start(ctx, "GetUnsettledClosingBefore")
+	auctions, err := s.next.GetUnsettledClosingBefore(ctx, until)
+	endSpan(span, err)
+	return auctions, err
+}
+
+func (s *TracedAuctionService) GetBidsByAuctionID(ctx context.Context, auctionID int) ([]domain.Bid, error) {
+	ctx, span := s.start(ctx, "GetBidsByAuctionID", attribute.Int("auction.id", auctionID))
+	bids, err := s.next.GetBidsByAuctionID(ctx, auctionID)
+	endSpan(span, err)
+	return bids, err
+}
+
+func (s *TracedAuctionService) SettleAuction(ctx context.Context, auctionID int) (domain.Settlement, bool, error) {
+	ctx, span := s.start(ctx, "SettleAuction", attribute.Int("auction.id", auctionID))
+	settlement, created, err := s.next.SettleAuction(ctx, auctionID)
+	span.SetAttributes(attribute.Bool("settlement.created", created))
+	endSpan(span, err)
+	return settlement, created, err
+}
+
+func (s *TracedAuctionService) GetSettlement(ctx context.Context, auctionID int) (domain.Settlement, error) {
+	ctx, span := s.start(ctx, "GetSettlement", attribute.Int("auction.id", auctionID))
+	settlement, err := s.next.GetSettlement(ctx, auctionID)
+	endSpan(span, err)
+	return settlement, err
+}
+
+func (s *TracedAuctionService) RequestWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) (int, error) {
+	ctx, span := s.start(ctx, "RequestWithdrawal", attribute.Int("user.id", withdrawal.UserID))
+	withdrawalID, err := s.next.RequestWithdrawal(ctx, withdrawal)
+	span.SetAttributes(attribute.Int("withdrawal.id", withdrawalID))
+	endSpan(span, err)
+	return withdrawalID, err
+}
+
+func (s *TracedAuctionService) ResolveWithdrawal(ctx context.Context, withdrawalID int, resolution domain.WithdrawalStatus, adminID int) (domain.Withdrawal, error) {
+	ctx, span := s.start(ctx, "ResolveWithdrawal", attribute.Int("withdrawal.id", withdrawalID),
+		attribute.String("withdrawal.resolution", string(resolution)))
+	withdrawal, err := s.next.ResolveWithdrawal(ctx, withdrawalID, resolution, adminID)
+	span.SetAttributes(attribute.String("withdrawal.status", string(withdrawal.Status)))
+	endSpan(span, err)
+	return withdrawal, err
+}
+
+func (s *TracedAuctionService) ListWithdrawals(ctx context.Context, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
+	ctx, span := s.start(ctx, "ListWithdrawals", attribute.Int("user.id", filter.UserID))
+	withdrawals, err := s.next.ListWithdrawals(ctx, filter)
+	endSpan(span, err)
+	return withdrawals, err
+}
+
+func (s *TracedAuctionService) ListBalanceTransactions(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error) {
+	ctx, span := s.start(ctx, "ListBalanceTransactions", attribute.Int("user.id", filter.UserID))
+	transactions, err := s.next.ListBalanceTransactions(ctx, filter)
+	endSpan(span, err)
+	return transactions, err
+}
+
+func (s *TracedAuctionService) CreatePayment(ctx context.Context, userID int, amount int64) (domain.Payment, error) {
+	ctx, span := s.start(ctx, "CreatePayment", attribute.Int("user.id", userID))
+	payment, err := s.next.CreatePayment(ctx, userID, amount)
+	span.SetAttributes(attribute.Int("payment.id", payment.PaymentID))
+	endSpan(span, err)
+	return payment, err
+}
+
+func (s *TracedAuctionService) ConfirmPayment(ctx context.Context, provider string, callback domain.PaymentCallback) (domain.Payment, error) {
+	ctx, span := s.start(ctx, "ConfirmPayment", attribute.String("payment.provider", provider),
+		attribute.String("payment.status", string(callback.Status)))
+	payment, err := s.next.ConfirmPayment(ctx, provider, callback)
+	span.SetAttributes(attribute.Int("payment.id", payment.PaymentID))
+	endSpan(span, err)
+	return payment, err
+}
+
+func (s *TracedAuctionService) GetPayment(ctx context.Context, paymentID int) (domain.Payment, error) {
+	ctx, span := s.start(ctx, "GetPayment", attribute.Int("payment.id", paymentID))
+	payment, err := s.next.GetPayment(ctx, paymentID)
+	endSpan(span, err)
+	return payment, err
+}
+
+func (s *TracedAuctionService) DetermineWinner(ctx context.Context, bids []domain.Bid) (int, []int, error) {
+	ctx, span := s.start(ctx, "DetermineWinner")
+	winnerID, losers, err := s.next.DetermineWinner(ctx, bids)
+	endSpan(span, err)
+	return winnerID, losers, err
+}
+
+func (s *TracedAuctionService) GetNewAuctions(ctx context.Context) ([]domain.Auction, error) {
+	ctx, span := s.start(ctx, "GetNewAuctions")
+	auctions, err := s.next.GetNewAuctions(ctx)
+	endSpan(span, err)
+	return auctions, err
+}
+
+func (s *TracedAuctionService) NotifyUsersAboutNewAuctions(ctx context.Context, digestWindow time.Duration) error {
+	ctx, span := s.start(ctx, "NotifyUsersAboutNewAuctions")
+	err := s.next.NotifyUsersAboutNewAuctions(ctx, digestWindow)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	ctx, span := s.start(ctx, "ListNotifications", attribute.Int("user.id", userID))
+	notifications, err := s.next.ListNotifications(ctx, userID, unreadOnly, limit, offset)
+	endSpan(span, err)
+	return notifications, err
+}
+
+func (s *TracedAuctionService) MarkNotificationRead(ctx context.Context, userID int, notificationID int64) error {
+	ctx, span := s.start(ctx, "MarkNotificationRead", attribute.Int("user.id", userID), attribute.Int64("notification.id", notificationID))
+	err := s.next.MarkNotificationRead(ctx, userID, notificationID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) UpdateNotificationSettings(ctx context.Context, userID int, settings domain.NotificationSettings) error {
+	ctx, span := s.start(ctx, "UpdateNotificationSettings", attribute.Int("user.id", userID))
+	err := s.next.UpdateNotificationSettings(ctx, userID, settings)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) GetNotificationPreferences(ctx context.Context, userID int) (domain.NotificationPreferences, error) {
+	ctx, span := s.start(ctx, "GetNotificationPreferences", attribute.Int("user.id", userID))
+	preferences, err := s.next.GetNotificationPreferences(ctx, userID)
+	endSpan(span, err)
+	return preferences, err
+}
+
+func (s *TracedAuctionService) UpdateNotificationPreferences(ctx context.Context, preferences domain.NotificationPreferences) error {
+	ctx, span := s.start(ctx, "UpdateNotificationPreferences", attribute.Int("user.id", preferences.UserID))
+	err := s.next.UpdateNotificationPreferences(ctx, preferences)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) CreateWebhookSubscription(ctx context.Context, subscription domain.WebhookSubscription) (domain.WebhookSubscription, error) {
+	ctx, span := s.start(ctx, "CreateWebhookSubscription")
+	created, err := s.next.CreateWebhookSubscription(ctx, subscription)
+	span.SetAttributes(attribute.Int("webhook.subscription.id", created.SubscriptionID))
+	endSpan(span, err)
+	return created, err
+}
+
+func (s *TracedAuctionService) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	ctx, span := s.start(ctx, "ListWebhookSubscriptions")
+	subscriptions, err := s.next.ListWebhookSubscriptions(ctx)
+	endSpan(span, err)
+	return subscriptions, err
+}
+
+func (s *TracedAuctionService) DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	ctx, span := s.start(ctx, "DeleteWebhookSubscription", attribute.Int("webhook.subscription.id", subscriptionID))
+	err := s.next.DeleteWebhookSubscription(ctx, subscriptionID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) EnableWebhookSubscription(ctx context.Context, subscriptionID int) error {
+	ctx, span := s.start(ctx, "EnableWebhookSubscription", attribute.Int("webhook.subscription.id", subscriptionID))
+	err := s.next.EnableWebhookSubscription(ctx, subscriptionID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) ListWebhookDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	ctx, span := s.start(ctx, "ListWebhookDeliveries", attribute.Int("webhook.subscription.id", subscriptionID))
+	deliveries, err := s.next.ListWebhookDeliveries(ctx, subscriptionID, limit, offset)
+	endSpan(span, err)
+	return deliveries, err
+}
+
+func (s *TracedAuctionService) RemindAuctionsEndingSoon(ctx context.Context, lead time.Duration) error {
+	ctx, span := s.start(ctx, "RemindAuctionsEndingSoon")
+	err := s.next.RemindAuctionsEndingSoon(ctx, lead)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) WatchAuction(ctx context.Context, userID, auctionID int) error {
+	ctx, span := s.start(ctx, "WatchAuction", attribute.Int("user.id", userID), attribute.Int("auction.id", auctionID))
+	err := s.next.WatchAuction(ctx, userID, auctionID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAuctionService) UnwatchAuction(ctx context.Context, userID, auctionID int) error {
+	ctx, span := s.start(ctx, "UnwatchAuction", attribute.Int("user.id", userID), attribute.Int("auction.id", auctionID))
+	err := s.next.UnwatchAuction(ctx, userID, auctionID)
+	endSpan(span, err)
+	return err
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans подменяет глобальный провайдер провайдером, сохраняющим span'ы в памяти
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	otel.SetTracerProvider(provider)
+	return exporter
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) (tracetest.SpanStub, bool) {
+	for _, span := range spans {
+		if span.Name == name {
+			return span, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func TestTracedAuctionService(t *testing.T) {
+	exporter := recordSpans(t)
+	service := NewTracedAuctionService(newFakeService(newSettlementStore()))
+
+	_, _, err := service.SettleAuction(context.Background(), 1)
+	require.NoError(t, err)
+	_, _, err = service.SettleAuction(context.Background(), 99)
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "AuctionService.SettleAuction", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.Int("auction.id", 1))
+	assert.Contains(t, spans[0].Attributes, attribute.Bool("settlement.created", true))
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	assert.Contains(t, spans[1].Attributes, attribute.Int("auction.id", 99))
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Len(t, spans[1].Events, 1)
+	assert.Equal(t, "exception", spans[1].Events[0].Name)
+}
+
+func TestAuctionWorkerSettlementSpan(t *testing.T) {
+	exporter := recordSpans(t)
+	service := &fakeWorkerService{settled: map[int]time.Time{}, completed: completedAuctions(1)}
+	worker := NewAuctionWorker(NewTracedAuctionService(service), NewClosingSchedule(), nil,
+		Scheduler{SweepInterval: time.Hour}, discardLogger(), nil)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	var settle, settleAuction tracetest.SpanStub
+	require.Eventually(t, func() bool {
+		var ok1, ok2 bool
+		settle, ok1 = findSpan(exporter.GetSpans(), "AuctionWorker.settle")
+		settleAuction, ok2 = findSpan(exporter.GetSpans(), "AuctionService.SettleAuction")
+		return ok1 && ok2
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Contains(t, settle.Attributes, attribute.Int("auction.id", 1))
+	assert.Equal(t, settle.SpanContext.TraceID(), settleAuction.SpanContext.TraceID())
+	assert.Equal(t, settle.SpanContext.SpanID(), settleAuction.Parent.SpanID())
+}
+
+func TestNewSpanExporter(t *testing.T) {
+	exporter, err := NewSpanExporter(context.Background(), Tracing{Exporter: "none"}, io.Discard)
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	_, err = NewSpanExporter(context.Background(), Tracing{Exporter: "jaeger"}, io.Discard)
+	assert.ErrorContains(t, err, `unknown exporter "jaeger"`)
+
+	var out bytes.Buffer
+	exporter, err = NewSpanExporter(context.Background(), Tracing{Exporter: "stdout"}, &out)
+	require.NoError(t, err)
+	provider := NewTracerProvider(Tracing{ServiceName: "auction-test"}, exporter)
+	_, span := provider.Tracer("test").Start(context.Background(), "AuctionService.PlaceBid")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Contains(t, out.String(), `"Name":"AuctionService.PlaceBid"`)
+	assert.Contains(t, out.String(), "auction-test")
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/repo"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// webhookPayload - тело запроса к подписчику
+type webhookPayload struct {
+	Event      domain.OutboxTopic `json:"event"`
+	OccurredAt time.Time          `json:"occurred_at"`
+	Data       json.RawMessage    `json:"data"`
+}
+
+// WebhookSink ставит события outbox, на которые есть подписки, в очередь доставки подписчикам.
+// Отправкой занимается WebhookDispatcher, поэтому недоступный подписчик не задерживает
+// остальных получателей outbox.
+type WebhookSink struct {
+	webhooks repo.WebhookRepository
+}
+
+func NewWebhookSink(webhooks repo.WebhookRepository) *WebhookSink {
+	return &WebhookSink{webhooks: webhooks}
+}
+
+func (s *WebhookSink) Handle(ctx context.Context, message domain.OutboxMessage) error {
+	if !slices.Contains(domain.WebhookEvents, message.Topic) {
+		return nil
+	}
+
+	subscriptions, err := s.webhooks.GetActiveSubscriptions(ctx, message.Topic)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(webhookPayload{Event: message.Topic, OccurredAt: message.CreatedAt, Data: message.Payload})
+	if err != nil {
+		return err
+	}
+	deliveries := make([]domain.WebhookDelivery, len(subscriptions))
+	for i, subscription := range subscriptions {
+		deliveries[i] = domain.WebhookDelivery{
+			SubscriptionID:  subscription.SubscriptionID,
+			OutboxMessageID: message.ID,
+			Event:           message.Topic,
+			Payload:         payload,
+		}
+	}
+	return s.webhooks.EnqueueDeliveries(ctx, deliveries...)
+}
+
+// WebhookSender отправляет доставку подписчику и возвращает код ответа
+type WebhookSender interface {
+	Send(ctx context.Context, delivery domain.WebhookDelivery) (int, error)
+}
+
+// WebhookDispatcher периодически отправляет подписчикам готовые доставки. Неудачные попытки
+// повторяются с экспоненциальной задержкой, а подписка отключается после DisableAfter
+// неудач подряд.
+type WebhookDispatcher struct {
+	webhooks repo.WebhookRepository
+	sender   WebhookSender
+	cfg      Webhooks
+	logger   *slog.Logger
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewWebhookDispatcher(webhooks repo.WebhookRepository, sender WebhookSender, cfg Webhooks, logger *slog.Logger) *WebhookDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		webhooks: webhooks,
+		sender:   sender,
+		cfg:      cfg.WithDefaults(),
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (d *WebhookDispatcher) Start() {
+	d.logger.Info("webhook dispatcher started")
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for {
+			select {
+			case <-time.After(d.cfg.Interval):
+				for d.Dispatch(d.ctx) == d.cfg.BatchSize {
+					select {
+					case <-d.stopCh:
+						return
+					default:
+					}
+				}
+			case <-d.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ведёт себя как OutboxDispatcher.Stop: неотправленные доставки вернутся в очередь
+// по истечении аренды
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+
+	d.logger.Info("webhook dispatcher stopped")
+	return err
+}
+
+// Dispatch отправляет одну пачку доставок и возвращает её размер
+func (d *WebhookDispatcher) Dispatch(ctx context.Context) int {
+	deliveries, err := d.webhooks.ClaimDeliveries(ctx, d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		d.logger.Error("failed to claim webhook deliveries", "error", err)
+		return 0
+	}
+
+	for i, delivery := range deliveries {
+		select {
+		case <-d.stopCh:
+			return i
+		default:
+		}
+		d.send(ctx, delivery)
+	}
+	return len(deliveries)
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery domain.WebhookDelivery) {
+	logger := d.logger.With("webhook_delivery_id", delivery.DeliveryID, "subscription_id", delivery.SubscriptionID)
+
+	sendCtx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	status, sendErr := d.sender.Send(sendCtx, delivery)
+	cancel()
+
+	if sendErr == nil {
+		if err := d.webhooks.MarkDelivered(ctx, delivery.DeliveryID, status); err != nil {
+			logger.Error("failed to mark webhook delivered", "error", err)
+		}
+		return
+	}
+
+	var nextAttemptAt *time.Time
+	if delivery.Attempts+1 < d.cfg.MaxAttempts {
+		next := time.Now().Add(exponentialBackoff(d.cfg.BaseBackoff, d.cfg.MaxBackoff, delivery.Attempts))
+		nextAttemptAt = &next
+	}
+	failures, err := d.webhooks.MarkFailed(ctx, delivery.DeliveryID, status, sendErr.Error(), nextAttemptAt, d.cfg.DisableAfter)
+	if err != nil {
+		logger.Error("failed to mark webhook failed", "error", err)
+		return
+	}
+	logger.Warn("webhook not delivered", "attempts", delivery.Attempts+1, "status", status, "error", sendErr)
+	if failures == d.cfg.DisableAfter {
+		logger.Warn("webhook subscription disabled", "consecutive_failures", failures)
+	}
+}
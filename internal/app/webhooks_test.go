@@ -0,0 +1,207 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/webhook"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	store := newFakeStore()
+	service := newFakeService(store)
+
+	subscription, err := service.CreateWebhookSubscription(context.Background(), domain.WebhookSubscription{
+		URL:    "https://partner.example.com/hooks",
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, subscription.SubscriptionID)
+	assert.Len(t, subscription.Secret, 64)
+	assert.True(t, store.state.webhooks[1].Active)
+	assert.Equal(t, subscription.Secret, store.state.webhooks[1].Secret)
+
+	_, err = service.CreateWebhookSubscription(context.Background(), domain.WebhookSubscription{
+		URL:    "https://partner.example.com/hooks",
+		Events: []domain.OutboxTopic{domain.OutboxAuctionWon},
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidWebhookEvent)
+	assert.Len(t, store.state.webhooks, 1)
+
+	// Журнал аудита не раскрывает секрет подписки
+	require.Len(t, store.state.events, 1)
+	assert.Equal(t, domain.AuditWebhookCreated, store.state.events[0].Action)
+	assert.JSONEq(t, `{"url": "https://partner.example.com/hooks", "events": ["lot.created"], "active": true}`,
+		string(store.state.events[0].After))
+}
+
+func TestWebhookSubscriptionChangesAreAudited(t *testing.T) {
+	store := newFakeStore()
+	service := newFakeService(store)
+	disabledAt := time.Now()
+	store.state.webhooks[1] = domain.WebhookSubscription{SubscriptionID: 1, URL: "https://partner.example.com/hooks",
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated}, Secret: "secret", ConsecutiveFailures: 3, DisabledAt: &disabledAt}
+
+	require.NoError(t, service.EnableWebhookSubscription(context.Background(), 1))
+	require.NoError(t, service.DeleteWebhookSubscription(context.Background(), 1))
+	assert.ErrorIs(t, service.DeleteWebhookSubscription(context.Background(), 1), domain.ErrWebhookSubscriptionNotFound)
+
+	assert.Empty(t, store.state.webhooks)
+	require.Len(t, store.state.events, 2)
+	enabled, deleted := store.state.events[0], store.state.events[1]
+	assert.Equal(t, domain.AuditWebhookEnabled, enabled.Action)
+	assert.JSONEq(t, `{"url": "https://partner.example.com/hooks", "events": ["lot.created"], "active": false, "consecutive_failures": 3}`,
+		string(enabled.Before))
+	assert.JSONEq(t, `{"url": "https://partner.example.com/hooks", "events": ["lot.created"], "active": true}`,
+		string(enabled.After))
+	assert.Equal(t, domain.AuditWebhookDeleted, deleted.Action)
+	assert.Equal(t, 1, deleted.EntityID)
+	assert.Nil(t, deleted.After)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	store := newFakeStore()
+	service := newFakeService(store)
+	store.state.webhooks[1] = domain.WebhookSubscription{SubscriptionID: 1, Active: true, Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	store.state.webhooks[2] = domain.WebhookSubscription{SubscriptionID: 2, Active: true, Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	require.NoError(t, (&fakeWebhookRepo{store: store}).EnqueueDeliveries(context.Background(),
+		domain.WebhookDelivery{SubscriptionID: 1, OutboxMessageID: 1, Event: domain.OutboxLotCreated},
+		domain.WebhookDelivery{SubscriptionID: 1, OutboxMessageID: 2, Event: domain.OutboxLotCreated},
+	))
+
+	deliveries, err := service.ListWebhookDeliveries(context.Background(), 1, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, int64(2), deliveries[0].DeliveryID)
+
+	// Пустой журнал существующей подписки отличается от неизвестной подписки
+	deliveries, err = service.ListWebhookDeliveries(context.Background(), 2, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	_, err = service.ListWebhookDeliveries(context.Background(), 3, 10, 0)
+	assert.ErrorIs(t, err, domain.ErrWebhookSubscriptionNotFound)
+}
+
+func TestWebhookSinkEnqueuesSubscribedEvents(t *testing.T) {
+	store := newFakeStore()
+	store.state.webhooks[1] = domain.WebhookSubscription{SubscriptionID: 1, Active: true,
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	store.state.webhooks[2] = domain.WebhookSubscription{SubscriptionID: 2, Active: true,
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated, domain.OutboxBidPlaced}}
+	store.state.webhooks[3] = domain.WebhookSubscription{SubscriptionID: 3,
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	sink := NewWebhookSink(&fakeWebhookRepo{store: store})
+
+	handle := func(id int64, topic domain.OutboxTopic, event any) {
+		t.Helper()
+		message, err := domain.NewOutboxMessage(topic, event)
+		require.NoError(t, err)
+		message.ID = id
+		require.NoError(t, sink.Handle(context.Background(), message))
+	}
+	handle(1, domain.OutboxLotCreated, domain.LotCreatedEvent{LotID: 5, AuctionID: 1, Title: "Часы"})
+	handle(2, domain.OutboxBidPlaced, domain.BidPlacedEvent{LotID: 5, AuctionID: 1, UserID: 2, Price: 100})
+	// Повторная доставка сообщения outbox не дублирует доставку подписчику
+	handle(2, domain.OutboxBidPlaced, domain.BidPlacedEvent{LotID: 5, AuctionID: 1, UserID: 2, Price: 100})
+	// На итоги аукциона для пользователей подписаться нельзя
+	handle(3, domain.OutboxAuctionWon, domain.AuctionResultEvent{AuctionID: 1, UserID: 2})
+
+	deliveries := store.state.deliveries
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, []int{1, 2, 2}, []int{deliveries[0].SubscriptionID, deliveries[1].SubscriptionID, deliveries[2].SubscriptionID})
+	assert.Equal(t, domain.OutboxBidPlaced, deliveries[2].Event)
+	assert.Equal(t, int64(2), deliveries[2].OutboxMessageID)
+
+	var payload struct {
+		Event string                 `json:"event"`
+		Data  domain.LotCreatedEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+	assert.Equal(t, "lot.created", payload.Event)
+	assert.Equal(t, domain.LotCreatedEvent{LotID: 5, AuctionID: 1, Title: "Часы"}, payload.Data)
+}
+
+func newTestWebhookDispatcher(store *fakeStore) *WebhookDispatcher {
+	cfg := Webhooks{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second, MaxAttempts: 3, DisableAfter: 2}
+	return NewWebhookDispatcher(&fakeWebhookRepo{store: store}, webhook.NewSender(http.DefaultClient), cfg, discardLogger())
+}
+
+func TestWebhookDispatcherSendsSignedRequests(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify("secret", r.Header, body, time.Now(), time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = append(received, r.Header.Get(webhook.HeaderEvent)+" "+string(body))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	store := newFakeStore()
+	store.state.webhooks[1] = domain.WebhookSubscription{SubscriptionID: 1, URL: server.URL, Secret: "secret", Active: true,
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	require.NoError(t, (&fakeWebhookRepo{store: store}).EnqueueDeliveries(context.Background(), domain.WebhookDelivery{
+		SubscriptionID: 1, OutboxMessageID: 1, Event: domain.OutboxLotCreated, Payload: json.RawMessage(`{"event":"lot.created"}`),
+	}))
+	dispatcher := newTestWebhookDispatcher(store)
+
+	assert.Equal(t, 1, dispatcher.Dispatch(context.Background()))
+	assert.Equal(t, []string{`lot.created {"event":"lot.created"}`}, received)
+	delivery := store.state.deliveries[0]
+	assert.Equal(t, domain.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusAccepted, delivery.ResponseStatus)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// Доставленные события больше не отправляются
+	assert.Zero(t, dispatcher.Dispatch(context.Background()))
+}
+
+func TestWebhookDispatcherDisablesFailingSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := newFakeStore()
+	store.state.webhooks[1] = domain.WebhookSubscription{SubscriptionID: 1, URL: server.URL, Secret: "secret", Active: true,
+		Events: []domain.OutboxTopic{domain.OutboxLotCreated}}
+	repo := &fakeWebhookRepo{store: store}
+	require.NoError(t, repo.EnqueueDeliveries(context.Background(), domain.WebhookDelivery{
+		SubscriptionID: 1, OutboxMessageID: 1, Event: domain.OutboxLotCreated, Payload: json.RawMessage(`{}`),
+	}))
+	dispatcher := newTestWebhookDispatcher(store)
+
+	assert.Equal(t, 1, dispatcher.Dispatch(context.Background()))
+	delivery := store.state.deliveries[0]
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+	assert.WithinDuration(t, time.Now().Add(time.Second), delivery.NextAttemptAt, 100*time.Millisecond)
+	assert.True(t, store.state.webhooks[1].Active)
+
+	store.state.deliveries[0].NextAttemptAt = time.Now()
+	assert.Equal(t, 1, dispatcher.Dispatch(context.Background()))
+	subscription := store.state.webhooks[1]
+	assert.False(t, subscription.Active)
+	assert.NotNil(t, subscription.DisabledAt)
+	assert.Equal(t, 2, subscription.ConsecutiveFailures)
+
+	// Пока подписка отключена, доставки не отправляются, после включения - продолжаются
+	store.state.deliveries[0].NextAttemptAt = time.Now()
+	assert.Zero(t, dispatcher.Dispatch(context.Background()))
+	require.NoError(t, repo.EnableSubscription(context.Background(), 1))
+	assert.Equal(t, 1, dispatcher.Dispatch(context.Background()))
+	// Третья попытка последняя
+	assert.Equal(t, domain.WebhookDeliveryFailed, store.state.deliveries[0].Status)
+	assert.Equal(t, 3, store.state.deliveries[0].Attempts)
+}
@@ -0,0 +1,285 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWithdrawalStore() *fakeStore {
+	store := newFakeStore()
+	store.state.balances = map[int]int64{2: 1000}
+	store.state.bids = []domain.Bid{{BidID: 1, AuctionID: 1, UserID: 2, Price: 300}}
+	return store
+}
+
+func TestRequestWithdrawalHoldsAmount(t *testing.T) {
+	store := newWithdrawalStore()
+	service := newFakeService(store)
+
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, withdrawalID)
+	assert.Equal(t, int64(400), store.state.balances[2])
+	assert.Equal(t, domain.WithdrawalPending, store.state.withdrawals[1].Status)
+	require.Len(t, store.state.ledger, 1)
+	assert.Equal(t, domain.TransactionHold, store.state.ledger[0].Type)
+	assert.Equal(t, int64(-600), store.state.ledger[0].Amount)
+	assert.Equal(t, 1, *store.state.ledger[0].WithdrawalID)
+	require.Len(t, store.state.events, 1)
+	assert.Equal(t, domain.AuditWithdrawalRequested, store.state.events[0].Action)
+}
+
+func TestRequestWithdrawalKeepsReservedFunds(t *testing.T) {
+	store := newWithdrawalStore()
+	initial := store.state.clone()
+	service := newFakeService(store)
+
+	// 300 из 1000 зарезервированы ставкой
+	_, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 701, Destination: "card *1234"})
+
+	assert.ErrorIs(t, err, domain.ErrInsufficientFunds)
+	assert.Equal(t, initial, store.state)
+}
+
+func TestRequestWithdrawalRollsBackOnFailure(t *testing.T) {
+	steps := []string{"balance.debit", "withdrawal.create", "ledger.record", "audit.append"}
+
+	for _, step := range steps {
+		t.Run(step, func(t *testing.T) {
+			store := newWithdrawalStore()
+			initial := store.state.clone()
+			service := newFakeService(store)
+
+			store.failures[step] = errors.New("injected failure")
+			_, err := service.RequestWithdrawal(context.Background(),
+				domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+
+			require.ErrorContains(t, err, "injected failure")
+			assert.Equal(t, initial, store.state)
+		})
+	}
+}
+
+func TestRejectWithdrawalReleasesHold(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+
+	withdrawal, err := service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalRejected, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawalRejected, withdrawal.Status)
+	assert.Equal(t, 1, *withdrawal.ResolvedBy)
+	assert.Equal(t, int64(1000), store.state.balances[2])
+	require.Len(t, store.state.ledger, 2)
+	assert.Equal(t, domain.TransactionRelease, store.state.ledger[1].Type)
+	assert.Equal(t, int64(600), store.state.ledger[1].Amount)
+	assert.Empty(t, payouts.calls)
+
+	// Решение по отклонённой заявке окончательное
+	_, err = service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+	assert.ErrorIs(t, err, domain.ErrWithdrawalResolved)
+}
+
+func TestApproveWithdrawalPaysOut(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+
+	withdrawal, err := service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawalPaid, withdrawal.Status)
+	assert.Equal(t, "fake-1", withdrawal.ProviderReference)
+	assert.Equal(t, withdrawal.Status, store.state.withdrawals[1].Status)
+	assert.NotNil(t, store.state.withdrawals[1].PaidAt)
+	assert.Equal(t, []int{1}, payouts.calls)
+	assert.Equal(t, int64(400), store.state.balances[2])
+	assert.Len(t, store.state.ledger, 1)
+
+	_, err = service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalRejected, 1)
+	assert.ErrorIs(t, err, domain.ErrWithdrawalResolved)
+}
+
+func TestApproveWithdrawalRetriesFailedPayout(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{err: errors.New("provider unavailable")}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+
+	_, err = service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+
+	require.ErrorContains(t, err, "provider unavailable")
+	stored := store.state.withdrawals[withdrawalID]
+	assert.Equal(t, domain.WithdrawalApproved, stored.Status)
+	assert.Equal(t, "provider unavailable", stored.LastError)
+
+	payouts.err = nil
+	withdrawal, err := service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawalPaid, withdrawal.Status)
+	assert.Empty(t, store.state.withdrawals[withdrawalID].LastError)
+	assert.Equal(t, []int{1, 1}, payouts.calls)
+	// Обе попытки идут с одним ключом идемпотентности
+	assert.Equal(t, []string{"withdrawal-1", "withdrawal-1"}, payouts.keys)
+	// Решение записано один раз, каждая попытка выплаты - отдельно
+	assert.Equal(t, []domain.AuditAction{
+		domain.AuditWithdrawalRequested,
+		domain.AuditWithdrawalResolved,
+		domain.AuditWithdrawalPayoutStarted,
+		domain.AuditWithdrawalPayoutFailed,
+		domain.AuditWithdrawalPayoutStarted,
+		domain.AuditWithdrawalPaid,
+	}, auditActions(store.state.events))
+	assert.JSONEq(t, `{"status":"approved","amount":600,"last_error":"provider unavailable"}`,
+		string(store.state.events[3].After))
+	assert.JSONEq(t, `{"status":"paid","amount":600,"provider_reference":"fake-1"}`,
+		string(store.state.events[5].After))
+}
+
+func TestApproveWithdrawalPaysOutAfterRequestCancelled(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+
+	// Клиент отключается, пока провайдер обрабатывает выплату
+	ctx, cancel := context.WithCancel(context.Background())
+	payouts.during = cancel
+	withdrawal, err := service.ResolveWithdrawal(ctx, withdrawalID, domain.WithdrawalApproved, 1)
+
+	require.NoError(t, err)
+	assert.NoError(t, payouts.ctxErr)
+	assert.Equal(t, domain.WithdrawalPaid, withdrawal.Status)
+	assert.Equal(t, domain.WithdrawalPaid, store.state.withdrawals[withdrawalID].Status)
+}
+
+func TestApproveStaleWithdrawalRetriesPayout(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	// Процесс упал после перевода заявки в processing: результат выплаты не записан
+	startedAt := time.Now().Add(-2 * payoutStaleAfter)
+	store.state.withdrawals[1] = domain.Withdrawal{WithdrawalID: 1, UserID: 2, Amount: 600,
+		Status: domain.WithdrawalProcessing, PayoutStartedAt: &startedAt}
+
+	_, err := service.ResolveWithdrawal(context.Background(), 1, domain.WithdrawalRejected, 1)
+	assert.ErrorIs(t, err, domain.ErrWithdrawalPayoutAttempted)
+
+	withdrawal, err := service.ResolveWithdrawal(context.Background(), 1, domain.WithdrawalApproved, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawalPaid, withdrawal.Status)
+	assert.Equal(t, []string{"withdrawal-1"}, payouts.keys)
+	assert.Equal(t, []domain.AuditAction{
+		domain.AuditWithdrawalPayoutStarted,
+		domain.AuditWithdrawalPaid,
+	}, auditActions(store.state.events))
+	assert.JSONEq(t, `{"status":"processing","amount":600}`, string(store.state.events[0].Before))
+}
+
+func TestApproveRecentProcessingWithdrawal(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	startedAt := time.Now().Add(-payoutTimeout)
+	store.state.withdrawals[1] = domain.Withdrawal{WithdrawalID: 1, UserID: 2, Amount: 600,
+		Status: domain.WithdrawalProcessing, PayoutStartedAt: &startedAt}
+
+	// Выплата могла ещё не завершиться: повторять её рано
+	_, err := service.ResolveWithdrawal(context.Background(), 1, domain.WithdrawalApproved, 1)
+
+	assert.ErrorIs(t, err, domain.ErrWithdrawalProcessing)
+	assert.Empty(t, payouts.calls)
+}
+
+func TestRejectWithdrawalAfterFailedPayout(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{err: errors.New("timeout")}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+	_, err = service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+	require.ErrorContains(t, err, "timeout")
+
+	// Провайдер мог провести выплату несмотря на ошибку: вернуть удержание нельзя
+	_, err = service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalRejected, 1)
+
+	assert.ErrorIs(t, err, domain.ErrWithdrawalPayoutAttempted)
+	assert.Equal(t, int64(400), store.state.balances[2])
+	assert.Equal(t, domain.WithdrawalApproved, store.state.withdrawals[withdrawalID].Status)
+}
+
+func TestResolveWithdrawalDuringPayout(t *testing.T) {
+	store := newWithdrawalStore()
+	payouts := &fakePayoutProvider{}
+	service := newFakeService(store, withPayouts(payouts))
+	withdrawalID, err := service.RequestWithdrawal(context.Background(),
+		domain.Withdrawal{UserID: 2, Amount: 600, Destination: "card *1234"})
+	require.NoError(t, err)
+
+	// Второй администратор принимает решение, пока провайдер обрабатывает выплату
+	var concurrent []error
+	payouts.during = func() {
+		assert.Equal(t, domain.WithdrawalProcessing, store.state.withdrawals[withdrawalID].Status)
+		for _, resolution := range []domain.WithdrawalStatus{domain.WithdrawalApproved, domain.WithdrawalRejected} {
+			_, err := service.ResolveWithdrawal(context.Background(), withdrawalID, resolution, 3)
+			concurrent = append(concurrent, err)
+		}
+	}
+	withdrawal, err := service.ResolveWithdrawal(context.Background(), withdrawalID, domain.WithdrawalApproved, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.WithdrawalPaid, withdrawal.Status)
+	require.Len(t, concurrent, 2)
+	assert.ErrorIs(t, concurrent[0], domain.ErrWithdrawalProcessing)
+	assert.ErrorIs(t, concurrent[1], domain.ErrWithdrawalProcessing)
+	assert.Equal(t, []int{withdrawalID}, payouts.calls)
+	assert.Equal(t, int64(400), store.state.balances[2])
+}
+
+func TestListWithdrawals(t *testing.T) {
+	store := newWithdrawalStore()
+	store.state.balances[3] = 500
+	service := newFakeService(store)
+	for _, withdrawal := range []domain.Withdrawal{
+		{UserID: 2, Amount: 100, Destination: "a"},
+		{UserID: 3, Amount: 200, Destination: "b"},
+		{UserID: 2, Amount: 300, Destination: "c"},
+	} {
+		_, err := service.RequestWithdrawal(context.Background(), withdrawal)
+		require.NoError(t, err)
+	}
+	_, err := service.ResolveWithdrawal(context.Background(), 1, domain.WithdrawalRejected, 1)
+	require.NoError(t, err)
+
+	own, err := service.ListWithdrawals(context.Background(), domain.WithdrawalFilter{UserID: 2})
+	require.NoError(t, err)
+	require.Len(t, own, 2)
+	assert.Equal(t, 3, own[0].WithdrawalID)
+
+	pending, err := service.ListWithdrawals(context.Background(), domain.WithdrawalFilter{Status: domain.WithdrawalPending})
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}
@@ -2,101 +2,333 @@ package app
 
 import (
 	"auction/internal/domain"
+	"auction/internal/infrastructure/logging"
 	"context"
-	"log"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Leader - блокировка, которой в каждый момент владеет не больше одного экземпляра сервиса
+type Leader interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// AuctionWorker рассчитывает закрывшиеся аукционы пулом из Concurrency обработчиков.
+// Обработчики нескольких экземпляров работают параллельно: каждый аукцион забирает тот,
+// кто первым заблокировал его строку. Рассылку о новых аукционах выполняет только владелец leader.
 type AuctionWorker struct {
 	service domain.AuctionService
-	logger  *log.Logger
-	stopCh  chan struct{}
+	closing *ClosingSchedule
+	leader  Leader
+	cfg     Scheduler
+	logger  *slog.Logger
+	metrics *Metrics
+
+	jobs   chan int
+	mu     sync.Mutex
+	queued map[int]bool
+
+	// lastTick - время последней успешной итерации цикла в наносекундах Unix, для проверки готовности
+	lastTick atomic.Int64
+	// failing выставляется, если не удался последний проход sweep или расчёт аукциона после него;
+	// пока он выставлен, lastTick не продвигается
+	failing atomic.Bool
+
+	// stopCh закрывается при остановке, ctx отменяется, когда текущую работу надо прервать
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
 }
 
-func NewAuctionWorker(service domain.AuctionService, logger *log.Logger) *AuctionWorker {
+func NewAuctionWorker(service domain.AuctionService, closing *ClosingSchedule, leader Leader, cfg Scheduler, logger *slog.Logger, metrics *Metrics) *AuctionWorker {
+	cfg = cfg.WithDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
 	return &AuctionWorker{
 		service: service,
+		closing: closing,
+		leader:  leader,
+		cfg:     cfg,
 		logger:  logger,
+		metrics: metrics,
+		jobs:    make(chan int, cfg.QueueSize),
+		queued:  make(map[int]bool),
 		stopCh:  make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 }
 
 func (w *AuctionWorker) Start() {
-	w.logger.Println("Auction worker started")
-	go w.run()
+	w.logger.Info("auction worker started")
+	for i := 0; i < w.cfg.Concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.settleLoop()
+		}()
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.run()
+	}()
 }
 
-func (w *AuctionWorker) Stop() {
-	close(w.stopCh)
-	w.logger.Println("Auction worker stopped")
+// Stop перестаёт брать аукционы из очереди и ждёт текущих расчётов. По дедлайну ctx расчёты
+// отменяются и откатываются; их выполнит следующий запуск или другой экземпляр.
+// Повторный вызов безопасен.
+func (w *AuctionWorker) Stop(ctx context.Context) error {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+
+	for len(w.jobs) > 0 {
+		<-w.jobs
+		w.metrics.queued(-1)
+	}
+	if w.leader != nil {
+		if err := w.leader.Release(context.Background()); err != nil {
+			w.logger.Error("failed to release leadership", "error", err)
+		}
+	}
+	w.logger.Info("auction worker stopped")
+	return err
 }
 
 func (w *AuctionWorker) run() {
+	sweep := time.NewTicker(w.cfg.SweepInterval)
+	defer sweep.Stop()
+
+	w.sweep()
 	for {
+		if !w.failing.Load() {
+			w.lastTick.Store(time.Now().UnixNano())
+			w.metrics.workerTicked()
+		}
+
+		// Таймер взводится на ближайшее закрытие и перевзводится при изменении очереди
+		timer := time.NewTimer(w.untilNextClosing())
 		select {
-		case <-time.After(time.Minute):
-			w.processCompletedAuctions()
-			w.processNewAuctions()
+		case <-timer.C:
+			w.processDueAuctions()
+		case <-w.closing.Changed():
+		case <-sweep.C:
+			w.sweep()
 		case <-w.stopCh:
+			timer.Stop()
 			return
 		}
+		timer.Stop()
+	}
+}
+
+// LastTick возвращает время последней успешной итерации цикла обработчика
+func (w *AuctionWorker) LastTick() time.Time {
+	tick := w.lastTick.Load()
+	if tick == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, tick)
+}
+
+// StaleAfter - через сколько после последней итерации цикл считается зависшим.
+// Итерация выполняется не реже раза в SweepInterval.
+func (w *AuctionWorker) StaleAfter() time.Duration {
+	return 3 * w.cfg.SweepInterval
+}
+
+func (w *AuctionWorker) untilNextClosing() time.Duration {
+	next, ok := w.closing.Next()
+	if !ok {
+		return w.cfg.SweepInterval
+	}
+	return max(time.Until(next), 0)
+}
+
+// sweep - страховочный проход: рассчитывает пропущенные аукционы, перечитывает ближайшие
+// закрытия из базы, напоминает о скором завершении и рассылает уведомления о новых аукционах.
+// Проход без ошибок снимает признак failing.
+func (w *AuctionWorker) sweep() {
+	errs := []error{w.processCompletedAuctions(), w.loadClosings(), w.remindEndingSoon()}
+	if w.isLeader() {
+		errs = append(errs, w.processNewAuctions())
+	}
+	w.failing.Store(errors.Join(errs...) != nil)
+}
+
+func (w *AuctionWorker) isLeader() bool {
+	if w.leader == nil {
+		return true
+	}
+	leader, err := w.leader.TryAcquire(w.ctx)
+	if err != nil {
+		w.logger.Error("failed to acquire leadership", "error", err)
+		return false
+	}
+	return leader
+}
+
+func (w *AuctionWorker) loadClosings() error {
+	auctions, err := w.service.GetUnsettledClosingBefore(w.ctx, time.Now().Add(w.cfg.Horizon))
+	if err != nil {
+		w.logger.Error("failed to load upcoming auction closings", "error", err)
+		return err
+	}
+
+	// Очередь дополняется, а не заменяется, чтобы не потерять аукционы, добавленные сервисом
+	// во время чтения. Устаревшие записи отсеет проверка при расчёте.
+	for _, auction := range auctions {
+		if auction.ClosedAt != nil {
+			w.closing.Set(auction.AuctionID, *auction.ClosedAt)
+		}
 	}
+	return nil
 }
 
-func (w *AuctionWorker) processCompletedAuctions() {
-	ctx := context.Background()
+func (w *AuctionWorker) processDueAuctions() {
+	for _, auctionID := range w.closing.PopDue(time.Now()) {
+		w.submit(auctionID)
+	}
+}
 
+func (w *AuctionWorker) processCompletedAuctions() error {
 	// 1. Выбор завершенных аукционов без победителя
-	completedAuctions, err := w.service.GetCompletedAuctionsWithoutWinner(ctx)
+	completedAuctions, err := w.service.GetCompletedAuctionsWithoutWinner(w.ctx)
 	if err != nil {
-		w.logger.Printf("Error retrieving completed auctions: %v", err)
-		return
+		w.logger.Error("failed to load completed auctions", "error", err)
+		return err
 	}
 
 	for _, auction := range completedAuctions {
-		// 2. Получение всех участников аукциона
-		bids, err := w.service.GetBidsByAuctionID(ctx, auction.AuctionID)
-		if err != nil {
-			w.logger.Printf("Error retrieving bids for auction %d: %v", auction.AuctionID, err)
-			continue
-		}
+		w.submit(auction.AuctionID)
+	}
+	return nil
+}
 
-		if len(bids) == 0 {
-			w.logger.Printf("No bids found for auction %d", auction.AuctionID)
-			continue
-		}
+// submit ставит аукцион в очередь пула, если он ещё не в ней. Блокируется, пока в очереди
+// нет места, чтобы при большом отставании не читать из базы больше, чем успевает пул.
+func (w *AuctionWorker) submit(auctionID int) {
+	w.mu.Lock()
+	if w.queued[auctionID] {
+		w.mu.Unlock()
+		return
+	}
+	w.queued[auctionID] = true
+	w.mu.Unlock()
 
-		// 3. Определение победителя
-		winnerID, losers, err := w.service.DetermineWinner(ctx, bids)
-		if err != nil {
-			w.logger.Printf("Error determining winner for auction %d: %v", auction.AuctionID, err)
-			continue
-		}
+	select {
+	case w.jobs <- auctionID:
+		w.metrics.queued(1)
+	case <-w.stopCh:
+	}
+}
 
-		// 4. Обработка транзакций
-		err = w.service.ProcessTransactions(ctx, auction.AuctionID, winnerID, losers)
-		if err != nil {
-			w.logger.Printf("Error processing transactions for auction %d: %v", auction.AuctionID, err)
-			continue
+func (w *AuctionWorker) settleLoop() {
+	for {
+		// После остановки очередь не разбирается, даже если в ней остались аукционы
+		select {
+		case <-w.stopCh:
+			return
+		default:
 		}
 
-		// 5. Уведомление победителя и проигравших
-		err = w.service.NotifyAuctionResults(ctx, auction.AuctionID, winnerID, losers)
-		if err != nil {
-			w.logger.Printf("Error notifying auction results for auction %d: %v", auction.AuctionID, err)
-			continue
+		select {
+		case auctionID := <-w.jobs:
+			w.metrics.queued(-1)
+			w.settleWithTimeout(auctionID)
+		case <-w.stopCh:
+			return
 		}
 	}
 }
 
-func (w *AuctionWorker) processNewAuctions() {
-	ctx := context.Background()
+func (w *AuctionWorker) settleWithTimeout(auctionID int) {
+	ctx, cancel := context.WithTimeout(w.ctx, w.cfg.SettlementTimeout)
+	defer cancel()
 
-	err := w.service.NotifyUsersAboutNewAuctions(ctx)
+	ctx = logging.ContextWithLogger(ctx, w.logger.With("auction_id", auctionID))
+	ctx, span := tracer().Start(ctx, "AuctionWorker.settle", trace.WithAttributes(attribute.Int("auction.id", auctionID)))
+	w.metrics.settlementStarted()
+	start := time.Now()
+	err := w.settleAuction(ctx, auctionID)
+	w.metrics.settlementFinished(time.Since(start), err)
+	endSpan(span, err)
 	if err != nil {
-		w.logger.Printf("Error notifying users about new auctions: %v", err)
-		return
+		w.failing.Store(true)
+	}
+
+	w.mu.Lock()
+	delete(w.queued, auctionID)
+	w.mu.Unlock()
+}
+
+// settleAuction рассчитывает аукцион и возвращает ошибку, если расчёт не удался
+func (w *AuctionWorker) settleAuction(ctx context.Context, auctionID int) error {
+	// 2. Расчёт аукциона: определение победителя и движение средств в одной транзакции
+	settlement, settled, err := w.service.SettleAuction(ctx, auctionID)
+	if errors.Is(err, domain.ErrAuctionBusy) {
+		// Аукцион рассчитывает другой экземпляр
+		return nil
+	}
+	if errors.Is(err, domain.ErrAuctionNotClosed) || errors.Is(err, domain.ErrAuctionCancelled) {
+		// Запись в очереди устарела: аукцион продлён или отменён другим экземпляром
+		return nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to settle auction", "error", err)
+		return err
+	}
+	if !settled {
+		// Аукцион уже рассчитан другим вызовом
+		return nil
+	}
+
+	// Уведомления победителю и проигравшим записаны в outbox вместе с расчётом
+	if !settlement.Sold() {
+		logging.FromContext(ctx).Info("auction closed without bids")
+	}
+	return nil
+}
+
+func (w *AuctionWorker) remindEndingSoon() error {
+	if err := w.service.RemindAuctionsEndingSoon(w.ctx, w.cfg.EndingSoon); err != nil {
+		w.logger.Error("failed to remind about auctions ending soon", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (w *AuctionWorker) processNewAuctions() error {
+	err := w.service.NotifyUsersAboutNewAuctions(w.ctx, w.cfg.DigestWindow)
+	if err != nil {
+		w.logger.Error("failed to notify users about new auctions", "error", err)
+		return err
 	}
 
-	w.logger.Println("Successfully notified users about new auctions")
+	w.logger.Debug("notified users about new auctions")
+	return nil
 }
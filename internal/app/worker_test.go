@@ -0,0 +1,325 @@
+package app
+
+import (
+	"auction/internal/domain"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWorkerService struct {
+	domain.AuctionService
+	mu        sync.Mutex
+	settled   map[int]time.Time
+	announced int
+	reminded  int
+	completed []domain.Auction
+	// loadErr возвращается при чтении завершённых аукционов
+	loadErr error
+	settle  func(ctx context.Context, auctionID int) error
+}
+
+func (s *fakeWorkerService) GetCompletedAuctionsWithoutWinner(context.Context) ([]domain.Auction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loadErr != nil {
+		return nil, s.loadErr
+	}
+	return s.completed, nil
+}
+
+func (s *fakeWorkerService) setLoadErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadErr = err
+}
+
+func (s *fakeWorkerService) GetUnsettledClosingBefore(context.Context, time.Time) ([]domain.Auction, error) {
+	return nil, nil
+}
+
+func (s *fakeWorkerService) RemindAuctionsEndingSoon(context.Context, time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reminded++
+	return nil
+}
+
+func (s *fakeWorkerService) NotifyUsersAboutNewAuctions(context.Context, time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.announced++
+	return nil
+}
+
+func (s *fakeWorkerService) SettleAuction(ctx context.Context, auctionID int) (domain.Settlement, bool, error) {
+	if s.settle != nil {
+		if err := s.settle(ctx, auctionID); err != nil {
+			return domain.Settlement{}, false, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settled[auctionID] = time.Now()
+	return domain.Settlement{AuctionID: auctionID}, true, nil
+}
+
+func (s *fakeWorkerService) settledAt(auctionID int) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.settled[auctionID]
+	return at, ok
+}
+
+func TestAuctionWorkerSettlesAtClosingTime(t *testing.T) {
+	service := &fakeWorkerService{settled: map[int]time.Time{}}
+	schedule := NewClosingSchedule()
+	worker := NewAuctionWorker(service, schedule, nil, Scheduler{SweepInterval: time.Hour}, discardLogger(), nil)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	// Аукцион добавлен после запуска: обработчик должен перевзвести таймер
+	closeAt := time.Now().Add(100 * time.Millisecond)
+	schedule.Set(7, closeAt)
+
+	require.Eventually(t, func() bool {
+		_, ok := service.settledAt(7)
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	settledAt, _ := service.settledAt(7)
+	assert.False(t, settledAt.Before(closeAt))
+	assert.WithinDuration(t, closeAt, settledAt, 50*time.Millisecond)
+}
+
+type fakeLeader struct {
+	leader   bool
+	released bool
+}
+
+func (l *fakeLeader) TryAcquire(context.Context) (bool, error) {
+	return l.leader, nil
+}
+
+func (l *fakeLeader) Release(context.Context) error {
+	l.released = true
+	return nil
+}
+
+func TestAuctionWorkerAnnouncesOnlyAsLeader(t *testing.T) {
+	service := &fakeWorkerService{settled: map[int]time.Time{}}
+	leader := &fakeLeader{}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), leader, Scheduler{}, discardLogger(), nil)
+
+	worker.sweep()
+	assert.Zero(t, service.announced)
+	// Напоминания рассылает любой экземпляр: аукцион забирает тот, кто первым его отметил
+	assert.Equal(t, 1, service.reminded)
+
+	leader.leader = true
+	worker.sweep()
+	assert.Equal(t, 1, service.announced)
+
+	worker.Stop(context.Background())
+	assert.True(t, leader.released)
+}
+
+// TestAuctionWorkersSettleEachAuctionOnce запускает несколько обработчиков над общим хранилищем,
+// как несколько экземпляров сервиса над одной базой (см. TestWorkersSettleEachAuctionOnce)
+func TestAuctionWorkersSettleEachAuctionOnce(t *testing.T) {
+	const auctions = 20
+	closedAt := time.Now().Add(-time.Minute)
+	store := newFakeStore()
+	store.state.balances = map[int]int64{2: 10000, 3: 10000, 10: 0}
+	for id := 1; id <= auctions; id++ {
+		store.state.auctions[id] = domain.Auction{AuctionID: id, ClosedAt: &closedAt}
+		store.state.lots[id] = domain.Lot{LotID: id, AuctionID: id, UserID: 10}
+		store.state.bids = append(store.state.bids,
+			domain.Bid{BidID: 2*id - 1, AuctionID: id, LotID: id, UserID: 2, Price: 100},
+			domain.Bid{BidID: 2 * id, AuctionID: id, LotID: id, UserID: 3, Price: 200})
+	}
+
+	for i := 0; i < 4; i++ {
+		worker := NewAuctionWorker(newFakeService(store), NewClosingSchedule(), nil,
+			Scheduler{SweepInterval: 10 * time.Millisecond, Concurrency: 3}, discardLogger(), nil)
+		worker.Start()
+		defer worker.Stop(context.Background())
+	}
+
+	require.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.state.settlements) == auctions
+	}, 5*time.Second, 10*time.Millisecond)
+	// Даём обработчикам ещё несколько проходов: повторный расчёт ничего не должен менять
+	time.Sleep(50 * time.Millisecond)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, map[int]int64{2: 10000, 3: 10000 - 200*auctions, 10: 200 * auctions}, store.state.balances)
+	var won, settled int
+	for _, message := range store.state.outbox {
+		if message.Topic == domain.OutboxAuctionWon {
+			won++
+		}
+	}
+	for _, event := range store.state.events {
+		if event.Action == domain.AuditAuctionSettled {
+			settled++
+		}
+	}
+	assert.Equal(t, auctions, won)
+	assert.Equal(t, auctions, settled)
+}
+
+func completedAuctions(n int) []domain.Auction {
+	auctions := make([]domain.Auction, n)
+	for i := range auctions {
+		auctions[i].AuctionID = i + 1
+	}
+	return auctions
+}
+
+func TestAuctionWorkerBoundsConcurrency(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+	)
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(12),
+		settle: func(context.Context, int) error {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		},
+	}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, Scheduler{SweepInterval: time.Hour, Concurrency: 3}, discardLogger(), nil)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		service.mu.Lock()
+		defer service.mu.Unlock()
+		return len(service.settled) == 12
+	}, 2*time.Second, 5*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, peak)
+}
+
+func TestAuctionWorkerTimesOutSlowSettlement(t *testing.T) {
+	errs := make(chan error, 1)
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(1),
+		settle: func(ctx context.Context, _ int) error {
+			<-ctx.Done()
+			errs <- ctx.Err()
+			return ctx.Err()
+		},
+	}
+	cfg := Scheduler{SweepInterval: time.Hour, SettlementTimeout: 20 * time.Millisecond}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, cfg, discardLogger(), nil)
+	worker.Start()
+	defer worker.Stop(context.Background())
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("settlement was not cancelled by timeout")
+	}
+}
+
+func TestAuctionWorkerStopWaitsForSettlements(t *testing.T) {
+	started := make(chan struct{})
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(1),
+		settle: func(ctx context.Context, _ int) error {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			return ctx.Err()
+		},
+	}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, Scheduler{SweepInterval: time.Hour}, discardLogger(), nil)
+	worker.Start()
+	<-started
+
+	require.NoError(t, worker.Stop(context.Background()))
+
+	_, ok := service.settledAt(1)
+	assert.True(t, ok)
+	// Повторная остановка безопасна
+	assert.NoError(t, worker.Stop(context.Background()))
+}
+
+func TestAuctionWorkerStopCancelsSettlementsAtDeadline(t *testing.T) {
+	started := make(chan struct{})
+	errs := make(chan error, 1)
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(1),
+		settle: func(ctx context.Context, _ int) error {
+			close(started)
+			<-ctx.Done()
+			errs <- ctx.Err()
+			return ctx.Err()
+		},
+	}
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, Scheduler{SweepInterval: time.Hour}, discardLogger(), nil)
+	worker.Start()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, worker.Stop(ctx), context.DeadlineExceeded)
+	assert.ErrorIs(t, <-errs, context.Canceled)
+}
+
+func TestAuctionWorkerLogsAuctionID(t *testing.T) {
+	attempted := make(chan struct{}, 1)
+	service := &fakeWorkerService{
+		settled:   map[int]time.Time{},
+		completed: completedAuctions(1),
+		settle: func(context.Context, int) error {
+			select {
+			case attempted <- struct{}{}:
+			default:
+			}
+			return errors.New("deadlock detected")
+		},
+	}
+	var out bytes.Buffer
+	worker := NewAuctionWorker(service, NewClosingSchedule(), nil, Scheduler{SweepInterval: time.Hour},
+		slog.New(slog.NewJSONHandler(&out, nil)), nil)
+	worker.Start()
+
+	select {
+	case <-attempted:
+	case <-time.After(time.Second):
+		t.Fatal("settlement was not attempted")
+	}
+	require.NoError(t, worker.Stop(context.Background()))
+
+	assert.Contains(t, out.String(), `"msg":"failed to settle auction","auction_id":1,"error":"deadlock detected"`)
+}
@@ -25,13 +25,23 @@ type Bid struct {
 }
 
 type Auction struct {
-	AuctionID int
-	CreatedAt time.Time
-	ClosedAt  *time.Time
-	UserID    *int
-	WinnerID  *int
-	User      *User
-	Winner    *User
+	AuctionID   int
+	CreatedAt   time.Time
+	ClosedAt    *time.Time
+	UserID      *int
+	WinnerID    *int
+	CancelledAt *time.Time
+	// AnnouncedAt - когда аукцион попал в рассылку о новых аукционах
+	AnnouncedAt *time.Time
+	// RemindedAt - когда участникам отправлено напоминание о скором завершении
+	RemindedAt *time.Time
+	User       *User
+	Winner     *User
+}
+
+// IsOpen сообщает, принимает ли аукцион ставки в момент now
+func (a Auction) IsOpen(now time.Time) bool {
+	return a.CancelledAt == nil && (a.ClosedAt == nil || a.ClosedAt.After(now))
 }
 
 type User struct {
@@ -39,14 +49,33 @@ type User struct {
 	Name    string
 	Email   string
 	Balance *int64
+	Roles   []Role
+	// NotificationChannels - каналы, по которым пользователь получает уведомления
+	NotificationChannels []NotificationChannel
+	WebhookURL           string
+	// Locale - язык уведомлений
+	Locale Locale
 }
 
+// Role - роль пользователя, определяющая доступные ему операции
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleSeller Role = "seller"
+	RoleBidder Role = "bidder"
+)
+
 // Проверка валидности ставки и баланса пользователя
-func ValidateBid(bid Bid, userBalance int64, currentBids []Bid) error {
+func ValidateBid(bid Bid, lot Lot, userBalance int64, currentBids []Bid) error {
 	if bid.Price <= 0 {
 		return ErrInvalidBidAmount
 	}
 
+	if bid.UserID == lot.UserID {
+		return ErrSelfBid
+	}
+
 	totalCommittedAmount := bid.Price
 	for _, b := range currentBids {
 		totalCommittedAmount += b.Price
@@ -66,3 +95,14 @@ func ValidateLot(lot Lot) error {
 	}
 	return nil
 }
+
+// ValidateCancellation проверяет, что аукцион ещё можно отменить
+func ValidateCancellation(auction Auction) error {
+	if auction.CancelledAt != nil {
+		return ErrAuctionCancelled
+	}
+	if auction.WinnerID != nil {
+		return ErrAuctionSettled
+	}
+	return nil
+}
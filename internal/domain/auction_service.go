@@ -1,17 +1,45 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // AuctionService - интерфейс для всех операций аукциона
 type AuctionService interface {
 	CreateLot(ctx context.Context, lot Lot) (int, error)
 	RefillBalance(ctx context.Context, userID int, amount int64) error
 	PlaceBid(ctx context.Context, bid Bid) (int, error)
+	CancelAuction(ctx context.Context, auctionID int) error
+	ListShillReviews(ctx context.Context, status ShillReviewStatus, limit, offset int) ([]ShillReview, error)
+	ResolveShillReview(ctx context.Context, reviewID int, status ShillReviewStatus, adminID int) error
+	ListAuditEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
 	GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]Auction, error)
+	GetUnsettledClosingBefore(ctx context.Context, until time.Time) ([]Auction, error)
 	GetBidsByAuctionID(ctx context.Context, auctionID int) ([]Bid, error)
-	ProcessTransactions(ctx context.Context, auctionID, winnerID int, losers []int) error
-	NotifyAuctionResults(ctx context.Context, auctionID, winnerID int, losers []int) error
+	SettleAuction(ctx context.Context, auctionID int) (Settlement, bool, error)
+	GetSettlement(ctx context.Context, auctionID int) (Settlement, error)
+	RequestWithdrawal(ctx context.Context, withdrawal Withdrawal) (int, error)
+	ResolveWithdrawal(ctx context.Context, withdrawalID int, resolution WithdrawalStatus, adminID int) (Withdrawal, error)
+	ListWithdrawals(ctx context.Context, filter WithdrawalFilter) ([]Withdrawal, error)
+	ListBalanceTransactions(ctx context.Context, filter BalanceTransactionFilter) ([]BalanceTransaction, error)
+	CreatePayment(ctx context.Context, userID int, amount int64) (Payment, error)
+	ConfirmPayment(ctx context.Context, provider string, callback PaymentCallback) (Payment, error)
+	GetPayment(ctx context.Context, paymentID int) (Payment, error)
 	DetermineWinner(ctx context.Context, bids []Bid) (int, []int, error)
 	GetNewAuctions(ctx context.Context) ([]Auction, error)
-	NotifyUsersAboutNewAuctions(ctx context.Context) error
+	NotifyUsersAboutNewAuctions(ctx context.Context, digestWindow time.Duration) error
+	ListNotifications(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]Notification, error)
+	MarkNotificationRead(ctx context.Context, userID int, notificationID int64) error
+	UpdateNotificationSettings(ctx context.Context, userID int, settings NotificationSettings) error
+	GetNotificationPreferences(ctx context.Context, userID int) (NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, preferences NotificationPreferences) error
+	RemindAuctionsEndingSoon(ctx context.Context, lead time.Duration) error
+	WatchAuction(ctx context.Context, userID, auctionID int) error
+	UnwatchAuction(ctx context.Context, userID, auctionID int) error
+	CreateWebhookSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID int) error
+	EnableWebhookSubscription(ctx context.Context, subscriptionID int) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]WebhookDelivery, error)
 }
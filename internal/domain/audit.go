@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction - тип изменения, записываемого в журнал аудита
+type AuditAction string
+
+const (
+	AuditLotCreated              AuditAction = "lot.created"
+	AuditBidPlaced               AuditAction = "bid.placed"
+	AuditBalanceRefilled         AuditAction = "balance.refilled"
+	AuditAuctionSettled          AuditAction = "auction.settled"
+	AuditAuctionCancelled        AuditAction = "auction.cancelled"
+	AuditShillReviewResolved     AuditAction = "shill_review.resolved"
+	AuditWithdrawalRequested     AuditAction = "withdrawal.requested"
+	AuditWithdrawalResolved      AuditAction = "withdrawal.resolved"
+	AuditWithdrawalPayoutStarted AuditAction = "withdrawal.payout_started"
+	AuditWithdrawalPaid          AuditAction = "withdrawal.paid"
+	AuditWithdrawalPayoutFailed  AuditAction = "withdrawal.payout_failed"
+	AuditPaymentCreated          AuditAction = "payment.created"
+	AuditPaymentConfirmed        AuditAction = "payment.confirmed"
+	AuditWebhookCreated          AuditAction = "webhook_subscription.created"
+	AuditWebhookDeleted          AuditAction = "webhook_subscription.deleted"
+	AuditWebhookEnabled          AuditAction = "webhook_subscription.enabled"
+)
+
+// Типы сущностей журнала аудита
+const (
+	EntityLot         = "lot"
+	EntityBid         = "bid"
+	EntityUser        = "user"
+	EntityAuction     = "auction"
+	EntityShillReview = "shill_review"
+	EntityWithdrawal  = "withdrawal"
+	EntityPayment     = "payment"
+	EntityWebhook     = "webhook_subscription"
+)
+
+// AuditEvent - запись журнала аудита. ActorID пуст для действий фоновых обработчиков.
+type AuditEvent struct {
+	EventID    int64
+	ActorID    *int
+	Action     AuditAction
+	EntityType string
+	EntityID   int
+	Before     json.RawMessage
+	After      json.RawMessage
+	RequestID  string
+	ClientIP   string
+	CreatedAt  time.Time
+}
+
+// AuditFilter - условия выборки журнала аудита. Пустые поля не ограничивают выборку.
+type AuditFilter struct {
+	EntityType string
+	EntityID   int
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// BalanceTransactionType - вид движения средств на балансе пользователя
+type BalanceTransactionType string
+
+const (
+	// TransactionRefill - пополнение баланса: оплата через провайдера или зачисление администратором
+	TransactionRefill BalanceTransactionType = "refill"
+	// TransactionCharge - списание цены лота с победителя
+	TransactionCharge BalanceTransactionType = "charge"
+	// TransactionPayout - зачисление цены лота продавцу
+	TransactionPayout BalanceTransactionType = "payout"
+	// TransactionFee - комиссия площадки, удерживаемая с продавца
+	TransactionFee BalanceTransactionType = "fee"
+	// TransactionHold - удержание суммы заявки на вывод
+	TransactionHold BalanceTransactionType = "hold"
+	// TransactionRelease - возврат удержания по отклонённой заявке
+	TransactionRelease BalanceTransactionType = "release"
+)
+
+// BalanceTransaction - запись журнала движения средств. Amount положителен для зачислений
+// и отрицателен для списаний, поэтому сумма записей пользователя равна изменению баланса.
+type BalanceTransaction struct {
+	TransactionID int64
+	UserID        int
+	Type          BalanceTransactionType
+	Amount        int64
+	// AuctionID - аукцион, по которому прошло движение
+	AuctionID *int
+	// WithdrawalID - заявка на вывод, по которой прошло движение
+	WithdrawalID *int
+	// PaymentID - платёж, которым пополнен баланс
+	PaymentID *int
+	// BalanceAfter - баланс пользователя после движения
+	BalanceAfter int64
+	CreatedAt    time.Time
+}
+
+// BalanceTransactionTypes - все виды движений в порядке вывода в документации
+var BalanceTransactionTypes = []BalanceTransactionType{
+	TransactionRefill, TransactionHold, TransactionRelease, TransactionCharge,
+	TransactionFee, TransactionPayout,
+}
+
+// BalanceTransactionFilter - условия выборки журнала. Пустые поля не ограничивают выборку,
+// нулевой Limit снимает ограничение на число записей.
+type BalanceTransactionFilter struct {
+	UserID int
+	Types  []BalanceTransactionType
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// ValidateBalanceTransactionFilter проверяет виды движений в фильтре
+func ValidateBalanceTransactionFilter(filter BalanceTransactionFilter) error {
+	for _, transactionType := range filter.Types {
+		if !slices.Contains(BalanceTransactionTypes, transactionType) {
+			return ErrInvalidTransactionType
+		}
+	}
+	return nil
+}
+
+// FillBalanceAfter заполняет BalanceAfter записей по балансам пользователей, в которых
+// движения уже учтены. Записи идут в порядке проведения: баланс после последней записи
+// пользователя равен текущему, после предыдущих - текущему за вычетом более поздних движений.
+func FillBalanceAfter(transactions []BalanceTransaction, balances map[int]int64) {
+	balances = maps.Clone(balances)
+	for i := len(transactions) - 1; i >= 0; i-- {
+		transactions[i].BalanceAfter = balances[transactions[i].UserID]
+		balances[transactions[i].UserID] -= transactions[i].Amount
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillBalanceAfter(t *testing.T) {
+	transactions := []BalanceTransaction{
+		{UserID: 3, Amount: -150},
+		{UserID: 10, Amount: 150},
+		{UserID: 10, Amount: -20},
+	}
+	balances := map[int]int64{3: 850, 10: 130}
+
+	FillBalanceAfter(transactions, balances)
+
+	assert.Equal(t, []int64{850, 150, 130}, []int64{
+		transactions[0].BalanceAfter, transactions[1].BalanceAfter, transactions[2].BalanceAfter,
+	})
+	assert.Equal(t, map[int]int64{3: 850, 10: 130}, balances)
+}
+
+func TestValidateBalanceTransactionFilter(t *testing.T) {
+	assert.NoError(t, ValidateBalanceTransactionFilter(BalanceTransactionFilter{}))
+	assert.NoError(t, ValidateBalanceTransactionFilter(BalanceTransactionFilter{Types: BalanceTransactionTypes}))
+	assert.ErrorIs(t, ValidateBalanceTransactionFilter(BalanceTransactionFilter{
+		Types: []BalanceTransactionType{TransactionRefill, "bonus"},
+	}), ErrInvalidTransactionType)
+}
@@ -0,0 +1,42 @@
+package domain
+
+// Commission - комиссия площадки с продажи лота. Tiers упорядочены по возрастанию FromPrice,
+// к цене продажи применяется последний тир, порог которого она достигла.
+type Commission struct {
+	Tiers []CommissionTier
+}
+
+// CommissionTier - процент от цены в базисных пунктах (1% = 100) и фиксированный сбор
+// для продаж от FromPrice
+type CommissionTier struct {
+	FromPrice   int64
+	BasisPoints int64
+	FixedFee    int64
+}
+
+// Fee возвращает комиссию с продажи по цене price. Процент округляется вниз, комиссия
+// не превышает цену.
+func (c Commission) Fee(price int64) int64 {
+	var tier CommissionTier
+	for _, t := range c.Tiers {
+		if price < t.FromPrice {
+			break
+		}
+		tier = t
+	}
+	return min(price*tier.BasisPoints/10000+tier.FixedFee, price)
+}
+
+// ValidateCommission проверяет, что проценты лежат в пределах 0-100%, сборы неотрицательны,
+// а пороги тиров возрастают
+func ValidateCommission(commission Commission) error {
+	for i, tier := range commission.Tiers {
+		if tier.BasisPoints < 0 || tier.BasisPoints > 10000 || tier.FixedFee < 0 || tier.FromPrice < 0 {
+			return ErrInvalidCommission
+		}
+		if i > 0 && tier.FromPrice <= commission.Tiers[i-1].FromPrice {
+			return ErrInvalidCommission
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommissionFee(t *testing.T) {
+	commission := Commission{Tiers: []CommissionTier{
+		{BasisPoints: 500, FixedFee: 10},
+		{FromPrice: 10000, BasisPoints: 300},
+		{FromPrice: 100000, BasisPoints: 150, FixedFee: 500},
+	}}
+
+	assert.Equal(t, int64(59), commission.Fee(999))
+	assert.Equal(t, int64(509), commission.Fee(9999))
+	assert.Equal(t, int64(300), commission.Fee(10000))
+	assert.Equal(t, int64(2000), commission.Fee(100000))
+	// Комиссия не превышает цену
+	assert.Equal(t, int64(8), commission.Fee(8))
+	assert.Zero(t, Commission{}.Fee(1000))
+}
+
+func TestSettlementTransactions(t *testing.T) {
+	winnerID, sellerID := 3, 10
+	settlement := Settlement{AuctionID: 1, WinnerID: &winnerID, SellerID: &sellerID, Price: 150}
+
+	transactions := settlement.Transactions()
+	assert.Len(t, transactions, 2)
+	var total int64
+	for _, transaction := range transactions {
+		total += transaction.Amount
+	}
+	assert.Zero(t, total)
+
+	settlement.Fee = 20
+	assert.Len(t, settlement.Transactions(), 3)
+	assert.Empty(t, Settlement{AuctionID: 1}.Transactions())
+}
+
+func TestValidateCommission(t *testing.T) {
+	assert.NoError(t, ValidateCommission(Commission{}))
+	assert.NoError(t, ValidateCommission(Commission{Tiers: []CommissionTier{{BasisPoints: 10000}, {FromPrice: 100}}}))
+
+	invalid := []Commission{
+		{Tiers: []CommissionTier{{BasisPoints: 10001}}},
+		{Tiers: []CommissionTier{{BasisPoints: -1}}},
+		{Tiers: []CommissionTier{{FixedFee: -1}}},
+		{Tiers: []CommissionTier{{}, {FromPrice: 0}}},
+		{Tiers: []CommissionTier{{FromPrice: 100}, {FromPrice: 50}}},
+	}
+	for _, commission := range invalid {
+		assert.ErrorIs(t, ValidateCommission(commission), ErrInvalidCommission)
+	}
+}
@@ -3,8 +3,45 @@ package domain
 import "errors"
 
 var (
-	ErrInvalidLotData    = errors.New("invalid lot data: start price and step must be greater than zero")
-	ErrInvalidBidAmount  = errors.New("invalid bid amount: amount must be greater than zero")
-	ErrLotNotFound       = errors.New("lot not found")
-	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidLotData     = errors.New("invalid lot data: start price and step must be greater than zero")
+	ErrInvalidBidAmount   = errors.New("invalid bid amount: amount must be greater than zero")
+	ErrLotNotFound        = errors.New("lot not found")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrAuctionNotFound    = errors.New("auction not found")
+	ErrAuctionCancelled   = errors.New("auction is cancelled")
+	ErrAuctionSettled     = errors.New("auction is already settled")
+	ErrAuctionNotClosed   = errors.New("auction is not closed yet")
+	ErrAuctionBusy        = errors.New("auction is being settled by another worker")
+	ErrSelfBid            = errors.New("sellers may not bid on their own lots")
+	ErrSettlementNotFound = errors.New("settlement not found")
+	ErrInvalidCommission  = errors.New("commission tiers must have 0-100% rates, non-negative fees and increasing price thresholds")
+
+	ErrShillReviewNotFound    = errors.New("shill review not found or already resolved")
+	ErrInvalidShillResolution = errors.New("resolution must be confirmed or dismissed")
+
+	ErrNotificationNotFound       = errors.New("notification not found")
+	ErrInvalidNotificationChannel = errors.New("unknown notification channel: expected email, webhook or inbox")
+	ErrInvalidWebhookURL          = errors.New("webhook channel requires an http or https webhook url")
+	ErrPrivateWebhookURL          = errors.New("webhook url must not point to a loopback, private or link-local address")
+	ErrInvalidLocale              = errors.New("unknown locale: expected ru or en")
+	ErrInvalidNotificationEvent   = errors.New("unknown notification event")
+	ErrInvalidQuietHours          = errors.New("quiet hours must be distinct times of day in a known timezone")
+
+	ErrWithdrawalNotFound           = errors.New("withdrawal not found")
+	ErrWithdrawalResolved           = errors.New("withdrawal is already paid or rejected")
+	ErrWithdrawalProcessing         = errors.New("withdrawal payout is in progress")
+	ErrWithdrawalPayoutAttempted    = errors.New("withdrawal payout has been attempted and can no longer be rejected")
+	ErrInvalidWithdrawalAmount      = errors.New("withdrawal amount must be greater than zero")
+	ErrInvalidWithdrawalDestination = errors.New("withdrawal destination is required")
+	ErrInvalidWithdrawalResolution  = errors.New("resolution must be approved or rejected")
+	ErrInvalidTransactionType       = errors.New("unknown balance transaction type")
+
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrInvalidPaymentAmount  = errors.New("payment amount must be greater than zero")
+	ErrInvalidPaymentStatus  = errors.New("payment callback status must be succeeded or failed")
+	ErrPaymentAmountMismatch = errors.New("paid amount does not match the payment")
+
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrInvalidWebhookEvent         = errors.New("webhook events must be lot.created, bid.placed or auction.settled")
 )
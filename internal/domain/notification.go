@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"net/netip"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// NotificationChannel - канал доставки уведомлений
+type NotificationChannel string
+
+const (
+	ChannelEmail   NotificationChannel = "email"
+	ChannelWebhook NotificationChannel = "webhook"
+	// ChannelInbox - лента уведомлений в приложении, хранится в базе
+	ChannelInbox NotificationChannel = "inbox"
+)
+
+// NotificationEvent - событие, о котором уведомляется пользователь
+type NotificationEvent string
+
+const (
+	EventNewAuctions NotificationEvent = "new_auctions"
+	EventOutbid      NotificationEvent = "outbid"
+	EventAuctionWon  NotificationEvent = "auction_won"
+	EventAuctionLost NotificationEvent = "auction_lost"
+	EventEndingSoon  NotificationEvent = "ending_soon"
+	// EventFirstBid - первая ставка на лот, уведомление продавцу
+	EventFirstBid NotificationEvent = "first_bid"
+)
+
+// NotificationEvents - все события, о которых отправляются уведомления
+var NotificationEvents = []NotificationEvent{
+	EventNewAuctions, EventOutbid, EventAuctionWon, EventAuctionLost, EventEndingSoon, EventFirstBid,
+}
+
+// Locale - язык уведомлений пользователя
+type Locale string
+
+const (
+	LocaleRU Locale = "ru"
+	LocaleEN Locale = "en"
+
+	DefaultLocale = LocaleRU
+)
+
+var Locales = []Locale{LocaleRU, LocaleEN}
+
+// NotificationStatus - итог доставки уведомления по каналу
+type NotificationStatus string
+
+const (
+	NotificationSent   NotificationStatus = "sent"
+	NotificationFailed NotificationStatus = "failed"
+)
+
+// Notification - уведомление, отправленное пользователю по одному каналу
+type Notification struct {
+	NotificationID int64
+	UserID         int
+	Event          NotificationEvent
+	Channel        NotificationChannel
+	Subject        string
+	Body           string
+	Status         NotificationStatus
+	// Error - причина неудачной доставки
+	Error     string
+	CreatedAt time.Time
+	ReadAt    *time.Time
+}
+
+// NotificationSettings - выбранные пользователем каналы доставки и язык. Пустой Locale
+// оставляет язык без изменений.
+type NotificationSettings struct {
+	Channels   []NotificationChannel
+	WebhookURL string
+	Locale     Locale
+}
+
+// ValidateNotificationSettings проверяет, что каналы и язык известны и для webhook задан
+// http(s) адрес во внешней сети
+func ValidateNotificationSettings(settings NotificationSettings) error {
+	if settings.Locale != "" && !slices.Contains(Locales, settings.Locale) {
+		return ErrInvalidLocale
+	}
+	for _, channel := range settings.Channels {
+		switch channel {
+		case ChannelEmail, ChannelInbox:
+		case ChannelWebhook:
+			if err := ValidateWebhookURL(settings.WebhookURL); err != nil {
+				return err
+			}
+		default:
+			return ErrInvalidNotificationChannel
+		}
+	}
+	return nil
+}
+
+// ValidateWebhookURL проверяет, что адрес - http(s) URL и не указывает IP-адресом во внутреннюю
+// сеть. Имена хостов проверяет при соединении клиент исходящих запросов.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublicAddr(addr) {
+		return ErrPrivateWebhookURL
+	}
+	return nil
+}
+
+// IsPublicAddr сообщает, можно ли отправлять запросы пользователей на адрес addr:
+// loopback, частные, link-local, multicast и неуказанные адреса запрещены
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && !addr.IsLoopback() && !addr.IsPrivate() && !addr.IsUnspecified() &&
+		!addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() && !addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast()
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"slices"
+	"time"
+	// Часовые пояса тихих часов проверяются и в контейнерах без системной базы zoneinfo
+	_ "time/tzdata"
+)
+
+// NotificationPreferences - какие уведомления и по каким каналам получает пользователь.
+// Событие без настройки приходит по каналам из NotificationSettings.
+type NotificationPreferences struct {
+	UserID         int
+	DisabledEvents []NotificationEvent
+	// EventChannels - каналы отдельных событий вместо каналов пользователя
+	EventChannels map[NotificationEvent][]NotificationChannel
+	// QuietHours - время, когда сразу приходят только уведомления в ленту, а письма и webhook
+	// откладываются до конца тихих часов. nil - без тихих часов.
+	QuietHours *QuietHours
+}
+
+// QuietHours - интервал тихих часов в минутах от полуночи по часовому поясу Timezone
+// (пустой - UTC). Интервал может переходить через полночь, например 22:00-08:00.
+type QuietHours struct {
+	StartMinute int
+	EndMinute   int
+	Timezone    string
+}
+
+const minutesPerDay = 24 * 60
+
+// Contains сообщает, попадает ли now в тихие часы
+func (q QuietHours) Contains(now time.Time) bool {
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	if q.StartMinute < q.EndMinute {
+		return minute >= q.StartMinute && minute < q.EndMinute
+	}
+	return minute >= q.StartMinute || minute < q.EndMinute
+}
+
+// End возвращает ближайший после now конец тихих часов
+func (q QuietHours) End(now time.Time) time.Time {
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.EndMinute/60, q.EndMinute%60, 0, 0, location)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Channels возвращает каналы, по которым пользователь получит событие в момент now.
+// defaults - каналы из NotificationSettings. В тихие часы сразу доставляется только лента,
+// остальные каналы возвращаются в deferred: их доставка откладывается до QuietHours.End.
+func (p NotificationPreferences) Channels(event NotificationEvent, defaults []NotificationChannel, now time.Time) (channels, deferred []NotificationChannel) {
+	if slices.Contains(p.DisabledEvents, event) {
+		return nil, nil
+	}
+	channels = defaults
+	if override, ok := p.EventChannels[event]; ok {
+		channels = override
+	}
+	if p.QuietHours == nil || !p.QuietHours.Contains(now) {
+		return channels, nil
+	}
+
+	var immediate []NotificationChannel
+	for _, channel := range channels {
+		if channel == ChannelInbox {
+			immediate = append(immediate, channel)
+		} else {
+			deferred = append(deferred, channel)
+		}
+	}
+	return immediate, deferred
+}
+
+// ValidateNotificationPreferences проверяет события, каналы и тихие часы. webhookURL - адрес
+// из NotificationSettings: без него событию нельзя выбрать канал webhook.
+func ValidateNotificationPreferences(preferences NotificationPreferences, webhookURL string) error {
+	for _, event := range preferences.DisabledEvents {
+		if !slices.Contains(NotificationEvents, event) {
+			return ErrInvalidNotificationEvent
+		}
+	}
+	for event, channels := range preferences.EventChannels {
+		if !slices.Contains(NotificationEvents, event) {
+			return ErrInvalidNotificationEvent
+		}
+		for _, channel := range channels {
+			switch channel {
+			case ChannelEmail, ChannelInbox:
+			case ChannelWebhook:
+				if err := ValidateWebhookURL(webhookURL); err != nil {
+					return err
+				}
+			default:
+				return ErrInvalidNotificationChannel
+			}
+		}
+	}
+
+	if q := preferences.QuietHours; q != nil {
+		if q.StartMinute < 0 || q.StartMinute >= minutesPerDay || q.EndMinute < 0 || q.EndMinute >= minutesPerDay ||
+			q.StartMinute == q.EndMinute {
+			return ErrInvalidQuietHours
+		}
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return ErrInvalidQuietHours
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	night := QuietHours{StartMinute: 22 * 60, EndMinute: 8 * 60, Timezone: "Europe/Moscow"}
+	lunch := QuietHours{StartMinute: 13 * 60, EndMinute: 14 * 60}
+
+	// 20:30 UTC - 23:30 по Москве
+	assert.True(t, night.Contains(time.Date(2024, 10, 17, 20, 30, 0, 0, time.UTC)))
+	assert.True(t, night.Contains(time.Date(2024, 10, 17, 4, 59, 0, 0, time.UTC)))
+	assert.False(t, night.Contains(time.Date(2024, 10, 17, 5, 0, 0, 0, time.UTC)))
+	assert.False(t, night.Contains(time.Date(2024, 10, 17, 18, 59, 0, 0, time.UTC)))
+
+	assert.True(t, lunch.Contains(time.Date(2024, 10, 17, 13, 0, 0, 0, time.UTC)))
+	assert.False(t, lunch.Contains(time.Date(2024, 10, 17, 14, 0, 0, 0, time.UTC)))
+}
+
+func TestQuietHoursEnd(t *testing.T) {
+	night := QuietHours{StartMinute: 22 * 60, EndMinute: 8 * 60, Timezone: "Europe/Moscow"}
+
+	// 23:30 по Москве - до 08:00 следующего дня, 04:00 - до 08:00 того же дня
+	assert.Equal(t, time.Date(2024, 10, 18, 5, 0, 0, 0, time.UTC), night.End(time.Date(2024, 10, 17, 20, 30, 0, 0, time.UTC)).UTC())
+	assert.Equal(t, time.Date(2024, 10, 17, 5, 0, 0, 0, time.UTC), night.End(time.Date(2024, 10, 17, 1, 0, 0, 0, time.UTC)).UTC())
+}
+
+func TestNotificationPreferencesChannels(t *testing.T) {
+	defaults := []NotificationChannel{ChannelInbox, ChannelEmail}
+	day := time.Date(2024, 10, 17, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2024, 10, 17, 23, 0, 0, 0, time.UTC)
+	preferences := NotificationPreferences{
+		DisabledEvents: []NotificationEvent{EventNewAuctions},
+		EventChannels:  map[NotificationEvent][]NotificationChannel{EventOutbid: {ChannelWebhook}},
+		QuietHours:     &QuietHours{StartMinute: 22 * 60, EndMinute: 7 * 60},
+	}
+
+	channels, deferred := preferences.Channels(EventAuctionWon, defaults, day)
+	assert.Equal(t, defaults, channels)
+	assert.Empty(t, deferred)
+	channels, deferred = preferences.Channels(EventNewAuctions, defaults, day)
+	assert.Empty(t, channels)
+	assert.Empty(t, deferred)
+	channels, _ = preferences.Channels(EventOutbid, defaults, day)
+	assert.Equal(t, []NotificationChannel{ChannelWebhook}, channels)
+
+	// В тихие часы сразу доставляется только лента, остальные каналы откладываются
+	channels, deferred = preferences.Channels(EventAuctionWon, defaults, night)
+	assert.Equal(t, []NotificationChannel{ChannelInbox}, channels)
+	assert.Equal(t, []NotificationChannel{ChannelEmail}, deferred)
+	channels, deferred = preferences.Channels(EventOutbid, defaults, night)
+	assert.Empty(t, channels)
+	assert.Equal(t, []NotificationChannel{ChannelWebhook}, deferred)
+	channels, deferred = preferences.Channels(EventNewAuctions, defaults, night)
+	assert.Empty(t, channels)
+	assert.Empty(t, deferred)
+
+	channels, deferred = NotificationPreferences{}.Channels(EventOutbid, defaults, night)
+	assert.Equal(t, defaults, channels)
+	assert.Empty(t, deferred)
+}
+
+func TestValidateNotificationPreferences(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences NotificationPreferences
+		webhookURL  string
+		err         error
+	}{
+		{name: "defaults", preferences: NotificationPreferences{}},
+		{name: "disabled event", preferences: NotificationPreferences{DisabledEvents: []NotificationEvent{EventEndingSoon}}},
+		{name: "unknown disabled event", preferences: NotificationPreferences{DisabledEvents: []NotificationEvent{"lot_created"}}, err: ErrInvalidNotificationEvent},
+		{name: "event channels", preferences: NotificationPreferences{EventChannels: map[NotificationEvent][]NotificationChannel{EventFirstBid: {ChannelEmail}}}},
+		{name: "unknown event channels", preferences: NotificationPreferences{EventChannels: map[NotificationEvent][]NotificationChannel{"lot_created": nil}}, err: ErrInvalidNotificationEvent},
+		{name: "webhook channel", preferences: NotificationPreferences{EventChannels: map[NotificationEvent][]NotificationChannel{EventOutbid: {ChannelWebhook}}}, webhookURL: "https://example.com/hook"},
+		{name: "webhook channel without url", preferences: NotificationPreferences{EventChannels: map[NotificationEvent][]NotificationChannel{EventOutbid: {ChannelWebhook}}}, err: ErrInvalidWebhookURL},
+		{name: "unknown channel", preferences: NotificationPreferences{EventChannels: map[NotificationEvent][]NotificationChannel{EventOutbid: {"sms"}}}, err: ErrInvalidNotificationChannel},
+		{name: "quiet hours", preferences: NotificationPreferences{QuietHours: &QuietHours{StartMinute: 1380, EndMinute: 420, Timezone: "Asia/Yekaterinburg"}}},
+		{name: "empty quiet hours", preferences: NotificationPreferences{QuietHours: &QuietHours{StartMinute: 600, EndMinute: 600}}, err: ErrInvalidQuietHours},
+		{name: "quiet hours out of day", preferences: NotificationPreferences{QuietHours: &QuietHours{StartMinute: 0, EndMinute: 1440}}, err: ErrInvalidQuietHours},
+		{name: "unknown timezone", preferences: NotificationPreferences{QuietHours: &QuietHours{StartMinute: 0, EndMinute: 60, Timezone: "Mars/Olympus"}}, err: ErrInvalidQuietHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateNotificationPreferences(tt.preferences, tt.webhookURL), tt.err)
+		})
+	}
+}
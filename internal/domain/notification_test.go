@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNotificationSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings NotificationSettings
+		err      error
+	}{
+		{name: "disabled", settings: NotificationSettings{}},
+		{name: "inbox and email", settings: NotificationSettings{Channels: []NotificationChannel{ChannelInbox, ChannelEmail}}},
+		{name: "webhook", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "https://example.com/hook"}},
+		{name: "webhook without url", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}}, err: ErrInvalidWebhookURL},
+		{name: "webhook with ftp url", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "ftp://example.com"}, err: ErrInvalidWebhookURL},
+		{name: "webhook to loopback", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://127.0.0.1:8080/hook"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to private network", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://10.0.0.5/hook"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to metadata service", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://169.254.169.254/latest"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to ipv6 loopback", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://[::1]/hook"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to mapped private ipv4", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://[::ffff:192.168.0.1]/hook"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to unspecified", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "http://0.0.0.0/hook"}, err: ErrPrivateWebhookURL},
+		{name: "webhook to public ip", settings: NotificationSettings{Channels: []NotificationChannel{ChannelWebhook}, WebhookURL: "https://203.0.113.10/hook"}},
+		{name: "unknown channel", settings: NotificationSettings{Channels: []NotificationChannel{"sms"}}, err: ErrInvalidNotificationChannel},
+		{name: "english", settings: NotificationSettings{Locale: LocaleEN}},
+		{name: "unknown locale", settings: NotificationSettings{Locale: "de"}, err: ErrInvalidLocale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateNotificationSettings(tt.settings), tt.err)
+		})
+	}
+}
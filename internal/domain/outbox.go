@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxTopic - тип события в outbox
+type OutboxTopic string
+
+const (
+	OutboxAuctionWon  OutboxTopic = "auction.won"
+	OutboxAuctionLost OutboxTopic = "auction.lost"
+	OutboxBidPlaced   OutboxTopic = "bid.placed"
+	// OutboxAuctionsAnnounced - рассылка всем пользователям о новых аукционах
+	OutboxAuctionsAnnounced OutboxTopic = "auction.announced"
+	// OutboxBidOutbid - ставка участника перестала быть лучшей
+	OutboxBidOutbid OutboxTopic = "bid.outbid"
+	// OutboxLotFirstBid - на лот продавца сделана первая ставка
+	OutboxLotFirstBid OutboxTopic = "lot.first_bid"
+	// OutboxAuctionEndingSoon - напоминание о скором завершении аукциона
+	OutboxAuctionEndingSoon OutboxTopic = "auction.ending_soon"
+	OutboxLotCreated        OutboxTopic = "lot.created"
+	// OutboxAuctionSettled - расчёт по аукциону завершён, в том числе без победителя
+	OutboxAuctionSettled OutboxTopic = "auction.settled"
+	// OutboxNotificationDeferred - уведомление, отложенное до конца тихих часов получателя
+	OutboxNotificationDeferred OutboxTopic = "notification.deferred"
+)
+
+// OutboxMessage - событие, записанное в одной транзакции с изменением и доставляемое
+// обработчикам не менее одного раза
+type OutboxMessage struct {
+	ID            int64
+	Topic         OutboxTopic
+	Payload       json.RawMessage
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	FailedAt      *time.Time
+}
+
+// AuctionResultEvent - содержимое событий auction.won и auction.lost, по одному на участника
+type AuctionResultEvent struct {
+	AuctionID int `json:"auction_id"`
+	UserID    int `json:"user_id"`
+	// Price - итоговая цена аукциона
+	Price int64 `json:"price,omitempty"`
+}
+
+// BidPlacedEvent - содержимое события bid.placed
+type BidPlacedEvent struct {
+	BidID     int   `json:"bid_id"`
+	AuctionID int   `json:"auction_id"`
+	LotID     int   `json:"lot_id"`
+	UserID    int   `json:"user_id"`
+	SellerID  int   `json:"seller_id"`
+	Price     int64 `json:"price"`
+	// OutbidUserID - участник, чью лучшую ставку перебила эта ставка
+	OutbidUserID *int `json:"outbid_user_id,omitempty"`
+}
+
+// LotCreatedEvent - содержимое события lot.created
+type LotCreatedEvent struct {
+	LotID      int        `json:"lot_id"`
+	AuctionID  int        `json:"auction_id"`
+	SellerID   int        `json:"seller_id"`
+	Title      string     `json:"title"`
+	StartPrice int        `json:"start_price"`
+	Step       int        `json:"step"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+}
+
+// AuctionSettledEvent - содержимое события auction.settled. Без победителя WinnerID пуст.
+type AuctionSettledEvent struct {
+	AuctionID int       `json:"auction_id"`
+	WinnerID  *int      `json:"winner_id,omitempty"`
+	Price     int64     `json:"price,omitempty"`
+	SettledAt time.Time `json:"settled_at"`
+}
+
+// AuctionsAnnouncedEvent - содержимое события auction.announced: аукционы одной рассылки
+type AuctionsAnnouncedEvent struct {
+	AuctionIDs []int `json:"auction_ids"`
+}
+
+// OutbidEvent - содержимое события bid.outbid для участника, чью ставку перебили
+type OutbidEvent struct {
+	AuctionID int   `json:"auction_id"`
+	LotID     int   `json:"lot_id"`
+	UserID    int   `json:"user_id"`
+	Price     int64 `json:"price"`
+}
+
+// FirstBidEvent - содержимое события lot.first_bid для продавца лота
+type FirstBidEvent struct {
+	AuctionID int   `json:"auction_id"`
+	LotID     int   `json:"lot_id"`
+	SellerID  int   `json:"seller_id"`
+	Price     int64 `json:"price"`
+}
+
+// EndingSoonEvent - содержимое события auction.ending_soon, по одному на участника и наблюдателя
+type EndingSoonEvent struct {
+	AuctionID int       `json:"auction_id"`
+	UserID    int       `json:"user_id"`
+	ClosedAt  time.Time `json:"closed_at"`
+}
+
+// NotificationDeferredEvent - содержимое события notification.deferred: уведомление,
+// уже отрендеренное на языке получателя, и каналы, доставка по которым отложена
+type NotificationDeferredEvent struct {
+	UserID   int                   `json:"user_id"`
+	Event    NotificationEvent     `json:"event"`
+	Channels []NotificationChannel `json:"channels"`
+	Subject  string                `json:"subject"`
+	Body     string                `json:"body"`
+	HTML     string                `json:"html,omitempty"`
+}
+
+// NewOutboxMessage упаковывает событие в сообщение outbox
+func NewOutboxMessage(topic OutboxTopic, event any) (OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	return OutboxMessage{Topic: topic, Payload: payload}, nil
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+type PaymentStatus string
+
+const (
+	// PaymentPending - платёж создан, пользователь ещё не оплатил его у провайдера
+	PaymentPending   PaymentStatus = "pending"
+	PaymentSucceeded PaymentStatus = "succeeded"
+	PaymentFailed    PaymentStatus = "failed"
+)
+
+// Payment - пополнение баланса через платёжного провайдера. Баланс пополняется только
+// после подтверждения оплаты провайдером.
+type Payment struct {
+	PaymentID int
+	UserID    int
+	Amount    int64
+	// Provider - имя провайдера, создавшего намерение оплаты
+	Provider string
+	// ProviderPaymentID - идентификатор намерения оплаты у провайдера
+	ProviderPaymentID string
+	// CheckoutURL - страница оплаты у провайдера
+	CheckoutURL string
+	Status      PaymentStatus
+	CreatedAt   time.Time
+	ConfirmedAt *time.Time
+}
+
+// PaymentCallback - уведомление провайдера о результате оплаты с проверенной подписью
+type PaymentCallback struct {
+	ProviderPaymentID string
+	Status            PaymentStatus
+	Amount            int64
+}
+
+// ValidatePaymentCallback проверяет, что уведомление относится к платежу и сообщает
+// окончательный результат
+func ValidatePaymentCallback(payment Payment, callback PaymentCallback) error {
+	if callback.Status != PaymentSucceeded && callback.Status != PaymentFailed {
+		return ErrInvalidPaymentStatus
+	}
+	if callback.Status == PaymentSucceeded && callback.Amount != payment.Amount {
+		return ErrPaymentAmountMismatch
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePaymentCallback(t *testing.T) {
+	payment := Payment{Amount: 500, Status: PaymentPending}
+
+	assert.NoError(t, ValidatePaymentCallback(payment, PaymentCallback{Status: PaymentSucceeded, Amount: 500}))
+	// Сумма отказа не важна: баланс не пополняется
+	assert.NoError(t, ValidatePaymentCallback(payment, PaymentCallback{Status: PaymentFailed}))
+	assert.ErrorIs(t, ValidatePaymentCallback(payment, PaymentCallback{Status: PaymentSucceeded, Amount: 50}), ErrPaymentAmountMismatch)
+	assert.ErrorIs(t, ValidatePaymentCallback(payment, PaymentCallback{Status: PaymentPending, Amount: 500}), ErrInvalidPaymentStatus)
+}
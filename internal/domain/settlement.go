@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Settlement - итог расчёта по аукциону. Запись создаётся в одной транзакции со списанием
+// средств победителя, поэтому её наличие означает, что аукцион рассчитан. WinnerID пуст,
+// если лот не продан.
+type Settlement struct {
+	AuctionID    int
+	WinnerID     *int
+	WinningBidID *int
+	Price        int64
+	Losers       []int
+	// SellerID и Fee заполняются, если лот продан. Fee - комиссия площадки с цены Price.
+	SellerID  *int
+	Fee       int64
+	SettledAt time.Time
+}
+
+// Sold сообщает, был ли у аукциона победитель
+func (s Settlement) Sold() bool {
+	return s.WinnerID != nil
+}
+
+// SellerPayout возвращает сумму, которую продавец получает за вычетом комиссии
+func (s Settlement) SellerPayout() int64 {
+	return s.Price - s.Fee
+}
+
+// Transactions возвращает движения средств по расчёту: списание с победителя, зачисление
+// цены продавцу и удержание комиссии
+func (s Settlement) Transactions() []BalanceTransaction {
+	if !s.Sold() || s.SellerID == nil {
+		return nil
+	}
+	auctionID := s.AuctionID
+	transactions := []BalanceTransaction{
+		{UserID: *s.WinnerID, Type: TransactionCharge, Amount: -s.Price, AuctionID: &auctionID, CreatedAt: s.SettledAt},
+		{UserID: *s.SellerID, Type: TransactionPayout, Amount: s.Price, AuctionID: &auctionID, CreatedAt: s.SettledAt},
+	}
+	if s.Fee > 0 {
+		transactions = append(transactions,
+			BalanceTransaction{UserID: *s.SellerID, Type: TransactionFee, Amount: -s.Fee, AuctionID: &auctionID, CreatedAt: s.SettledAt})
+	}
+	return transactions
+}
+
+// RankBidders возвращает лучшую ставку каждого участника, начиная с самой высокой.
+// Ставки bids идут в порядке их создания, поэтому при равной цене выше оказывается
+// участник, который предложил её раньше.
+func RankBidders(bids []Bid) []Bid {
+	// Индекс лучшей ставки участника в bids
+	best := make(map[int]int, len(bids))
+	for i, bid := range bids {
+		if j, ok := best[bid.UserID]; !ok || bid.Price > bids[j].Price {
+			best[bid.UserID] = i
+		}
+	}
+
+	indexes := slices.Sorted(maps.Values(best))
+	slices.SortStableFunc(indexes, func(a, b int) int {
+		return cmp.Compare(bids[b].Price, bids[a].Price)
+	})
+	ranking := make([]Bid, len(indexes))
+	for i, j := range indexes {
+		ranking[i] = bids[j]
+	}
+	return ranking
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankBidders(t *testing.T) {
+	bids := []Bid{
+		{BidID: 1, UserID: 2, Price: 100},
+		{BidID: 2, UserID: 3, Price: 120},
+		{BidID: 3, UserID: 2, Price: 130},
+		{BidID: 4, UserID: 4, Price: 130},
+		{BidID: 5, UserID: 3, Price: 110},
+	}
+
+	ranking := RankBidders(bids)
+
+	// Участник 2 предложил 130 раньше участника 4
+	assert.Equal(t, []Bid{bids[2], bids[3], bids[1]}, ranking)
+	assert.Empty(t, RankBidders(nil))
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ShillReason - признак подставных ставок
+type ShillReason string
+
+const (
+	// ShillSingleSeller - пользователь ставит только на лоты одного продавца
+	ShillSingleSeller ShillReason = "single_seller"
+	// ShillJustBelow - ставки пользователя почти всегда перебиваются минимальным шагом
+	ShillJustBelow ShillReason = "just_below"
+	// ShillNewAccount - недавно зарегистрированный пользователь поднимает цену
+	ShillNewAccount ShillReason = "new_account"
+)
+
+type ShillReviewStatus string
+
+const (
+	ShillReviewPending   ShillReviewStatus = "pending"
+	ShillReviewConfirmed ShillReviewStatus = "confirmed"
+	ShillReviewDismissed ShillReviewStatus = "dismissed"
+)
+
+// ShillReview - запись очереди ручной проверки подозрительных ставок
+type ShillReview struct {
+	ReviewID   int
+	UserID     int
+	SellerID   *int
+	LotID      *int
+	Reason     ShillReason
+	Details    string
+	Status     ShillReviewStatus
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+	ResolvedBy *int
+}
+
+// BidderStats - история ставок пользователя, по которой ищутся подставные ставки
+type BidderStats struct {
+	UserID           int
+	AccountCreatedAt time.Time
+	TotalBids        int
+	// TopSellerID и TopSellerBids - продавец, на лоты которого сделано больше всего ставок
+	TopSellerID   int
+	TopSellerBids int
+	// JustBelowBids - ставки, перебитые другим участником не больше чем на шаг лота
+	JustBelowBids int
+}
+
+// ShillRules - пороги срабатывания правил
+type ShillRules struct {
+	MinBids           int
+	SingleSellerShare float64
+	JustBelowShare    float64
+	NewAccountAge     time.Duration
+}
+
+// ShillSignal - сработавшее правило с пояснением для проверяющего
+type ShillSignal struct {
+	Reason  ShillReason
+	Details string
+}
+
+// DetectShillBidding проверяет историю ставок пользователя по правилам.
+// raisesPrice - последняя ставка перебила ставку другого участника.
+func DetectShillBidding(stats BidderStats, rules ShillRules, raisesPrice bool, now time.Time) []ShillSignal {
+	var signals []ShillSignal
+
+	if raisesPrice && now.Sub(stats.AccountCreatedAt) < rules.NewAccountAge {
+		signals = append(signals, ShillSignal{
+			Reason:  ShillNewAccount,
+			Details: fmt.Sprintf("account registered %s ago raises the price", now.Sub(stats.AccountCreatedAt).Round(time.Minute)),
+		})
+	}
+
+	if stats.TotalBids < rules.MinBids {
+		return signals
+	}
+
+	if share := float64(stats.TopSellerBids) / float64(stats.TotalBids); share >= rules.SingleSellerShare {
+		signals = append(signals, ShillSignal{
+			Reason:  ShillSingleSeller,
+			Details: fmt.Sprintf("%d of %d bids placed on lots of seller %d", stats.TopSellerBids, stats.TotalBids, stats.TopSellerID),
+		})
+	}
+
+	if share := float64(stats.JustBelowBids) / float64(stats.TotalBids); share >= rules.JustBelowShare {
+		signals = append(signals, ShillSignal{
+			Reason:  ShillJustBelow,
+			Details: fmt.Sprintf("%d of %d bids outbid by at most one step", stats.JustBelowBids, stats.TotalBids),
+		})
+	}
+
+	return signals
+}
+
+// ValidateShillResolution проверяет итог ручной проверки
+func ValidateShillResolution(status ShillReviewStatus) error {
+	if status != ShillReviewConfirmed && status != ShillReviewDismissed {
+		return ErrInvalidShillResolution
+	}
+	return nil
+}
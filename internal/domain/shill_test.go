@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectShillBidding(t *testing.T) {
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	rules := ShillRules{
+		MinBids:           5,
+		SingleSellerShare: 1,
+		JustBelowShare:    0.8,
+		NewAccountAge:     72 * time.Hour,
+	}
+	oldAccount := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		stats       BidderStats
+		raisesPrice bool
+		want        []ShillReason
+	}{
+		{
+			name:  "Regular bidder",
+			stats: BidderStats{AccountCreatedAt: oldAccount, TotalBids: 10, TopSellerID: 2, TopSellerBids: 4, JustBelowBids: 3},
+		},
+		{
+			name:  "Too few bids to judge",
+			stats: BidderStats{AccountCreatedAt: oldAccount, TotalBids: 4, TopSellerID: 2, TopSellerBids: 4, JustBelowBids: 4},
+		},
+		{
+			name:  "Only bids on one seller",
+			stats: BidderStats{AccountCreatedAt: oldAccount, TotalBids: 6, TopSellerID: 2, TopSellerBids: 6},
+			want:  []ShillReason{ShillSingleSeller},
+		},
+		{
+			name:  "Always lands just below",
+			stats: BidderStats{AccountCreatedAt: oldAccount, TotalBids: 10, TopSellerID: 2, TopSellerBids: 3, JustBelowBids: 9},
+			want:  []ShillReason{ShillJustBelow},
+		},
+		{
+			name:        "New account raises the price",
+			stats:       BidderStats{AccountCreatedAt: now.Add(-time.Hour), TotalBids: 1, TopSellerBids: 1},
+			raisesPrice: true,
+			want:        []ShillReason{ShillNewAccount},
+		},
+		{
+			name:  "New account opening bid",
+			stats: BidderStats{AccountCreatedAt: now.Add(-time.Hour), TotalBids: 1, TopSellerBids: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reasons []ShillReason
+			for _, signal := range DetectShillBidding(tt.stats, rules, tt.raisesPrice, now) {
+				reasons = append(reasons, signal.Reason)
+			}
+			assert.Equal(t, tt.want, reasons)
+		})
+	}
+}
+
+func TestValidateBidRejectsSelfBid(t *testing.T) {
+	lot := Lot{LotID: 1, UserID: 7}
+
+	assert.ErrorIs(t, ValidateBid(Bid{UserID: 7, Price: 100}, lot, 1000, nil), ErrSelfBid)
+	assert.NoError(t, ValidateBid(Bid{UserID: 8, Price: 100}, lot, 1000, nil))
+}
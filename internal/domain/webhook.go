@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// WebhookEvents - события, на которые могут подписаться внешние системы
+var WebhookEvents = []OutboxTopic{OutboxLotCreated, OutboxBidPlaced, OutboxAuctionSettled}
+
+// WebhookSubscription - подписка внешней системы на события аукциона. Запросы подписываются
+// HMAC-SHA256 с секретом Secret.
+type WebhookSubscription struct {
+	SubscriptionID int
+	URL            string
+	Events         []OutboxTopic
+	Secret         string
+	Active         bool
+	// ConsecutiveFailures - неудачные попытки подряд; после успешной доставки сбрасывается
+	ConsecutiveFailures int
+	CreatedAt           time.Time
+	// DisabledAt - когда подписка отключена из-за неудачных доставок
+	DisabledAt *time.Time
+}
+
+// WebhookDeliveryStatus - состояние доставки события подписчику
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryFailed - попытки исчерпаны
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery - доставка одного события одной подписке
+type WebhookDelivery struct {
+	DeliveryID      int64
+	SubscriptionID  int
+	OutboxMessageID int64
+	Event           OutboxTopic
+	// Payload - тело запроса, подписывается вместе с временем отправки
+	Payload        json.RawMessage
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// Subscription заполняется при выборке доставок для отправки
+	Subscription *WebhookSubscription
+}
+
+// ValidateWebhookSubscription проверяет адрес и список событий подписки
+func ValidateWebhookSubscription(subscription WebhookSubscription) error {
+	if err := ValidateWebhookURL(subscription.URL); err != nil {
+		return err
+	}
+	if len(subscription.Events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+	for _, event := range subscription.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhookSubscription(t *testing.T) {
+	valid := WebhookSubscription{URL: "https://partner.example.com/hooks", Events: []OutboxTopic{OutboxLotCreated, OutboxAuctionSettled}}
+	assert.NoError(t, ValidateWebhookSubscription(valid))
+
+	for _, url := range []string{"", "partner.example.com", "ftp://partner.example.com", "https://"} {
+		subscription := valid
+		subscription.URL = url
+		assert.ErrorIs(t, ValidateWebhookSubscription(subscription), ErrInvalidWebhookURL, url)
+	}
+
+	for _, url := range []string{"http://127.0.0.1:9000/hooks", "http://192.168.1.10/hooks", "http://[fe80::1]/hooks"} {
+		subscription := valid
+		subscription.URL = url
+		assert.ErrorIs(t, ValidateWebhookSubscription(subscription), ErrPrivateWebhookURL, url)
+	}
+
+	for _, events := range [][]OutboxTopic{nil, {OutboxAuctionWon}, {OutboxLotCreated, "lot.deleted"}} {
+		subscription := valid
+		subscription.Events = events
+		assert.ErrorIs(t, ValidateWebhookSubscription(subscription), ErrInvalidWebhookEvent)
+	}
+}
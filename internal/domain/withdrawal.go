@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type WithdrawalStatus string
+
+const (
+	// WithdrawalPending - заявка ждёт решения администратора, сумма удержана с баланса
+	WithdrawalPending WithdrawalStatus = "pending"
+	// WithdrawalApproved - заявка одобрена, но выплата не прошла и ждёт повторного одобрения
+	WithdrawalApproved WithdrawalStatus = "approved"
+	// WithdrawalProcessing - выплата отправлена провайдеру, ответ ещё не получен. Если ответ
+	// так и не записан, заявка считается зависшей и её можно одобрить повторно.
+	WithdrawalProcessing WithdrawalStatus = "processing"
+	WithdrawalPaid       WithdrawalStatus = "paid"
+	// WithdrawalRejected - заявка отклонена, удержание возвращено на баланс
+	WithdrawalRejected WithdrawalStatus = "rejected"
+)
+
+// Withdrawal - заявка на вывод средств. Сумма удерживается с баланса при создании заявки.
+type Withdrawal struct {
+	WithdrawalID int
+	UserID       int
+	Amount       int64
+	// Destination - реквизиты для выплаты, передаются провайдеру как есть
+	Destination string
+	Status      WithdrawalStatus
+	// ProviderReference - идентификатор выплаты у провайдера
+	ProviderReference string
+	// LastError - ошибка последней попытки выплаты
+	LastError  string
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+	ResolvedBy *int
+	PaidAt     *time.Time
+	// PayoutStartedAt - время последнего перевода заявки в processing
+	PayoutStartedAt *time.Time
+}
+
+// PayoutIdempotencyKey - ключ идемпотентности выплаты у провайдера. Он одинаков для всех
+// попыток, поэтому повтор после ошибки или зависания не переведёт деньги второй раз.
+func (w Withdrawal) PayoutIdempotencyKey() string {
+	return fmt.Sprintf("withdrawal-%d", w.WithdrawalID)
+}
+
+// PayoutStale сообщает, что выплата в статусе processing начата раньше staleBefore и
+// результат так и не записан, например, процесс упал во время обращения к провайдеру
+func (w Withdrawal) PayoutStale(staleBefore time.Time) bool {
+	return w.Status == WithdrawalProcessing && w.PayoutStartedAt != nil && w.PayoutStartedAt.Before(staleBefore)
+}
+
+// WithdrawalFilter - условия выборки заявок. Пустые поля не ограничивают выборку.
+type WithdrawalFilter struct {
+	UserID int
+	Status WithdrawalStatus
+	Limit  int
+	Offset int
+}
+
+// ValidateWithdrawal проверяет сумму и что она не превышает средств, свободных от ставок
+// в нерассчитанных аукционах
+func ValidateWithdrawal(withdrawal Withdrawal, userBalance int64, currentBids []Bid) error {
+	if withdrawal.Amount <= 0 {
+		return ErrInvalidWithdrawalAmount
+	}
+	if withdrawal.Destination == "" {
+		return ErrInvalidWithdrawalDestination
+	}
+
+	available := userBalance
+	for _, b := range currentBids {
+		available -= b.Price
+	}
+	if withdrawal.Amount > available {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// ValidateWithdrawalResolution проверяет решение администратора по заявке. Одобренную заявку
+// можно одобрить повторно, чтобы повторить неудавшуюся выплату, но не отклонить: провайдер мог
+// провести выплату, несмотря на ошибку. Так же повторяется выплата, зависшая в processing
+// дольше staleBefore.
+func ValidateWithdrawalResolution(withdrawal Withdrawal, resolution WithdrawalStatus, staleBefore time.Time) error {
+	if resolution != WithdrawalApproved && resolution != WithdrawalRejected {
+		return ErrInvalidWithdrawalResolution
+	}
+	switch withdrawal.Status {
+	case WithdrawalPending:
+		return nil
+	case WithdrawalApproved:
+		if resolution == WithdrawalRejected {
+			return ErrWithdrawalPayoutAttempted
+		}
+		return nil
+	case WithdrawalProcessing:
+		if !withdrawal.PayoutStale(staleBefore) {
+			return ErrWithdrawalProcessing
+		}
+		if resolution == WithdrawalRejected {
+			return ErrWithdrawalPayoutAttempted
+		}
+		return nil
+	default:
+		return ErrWithdrawalResolved
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWithdrawal(t *testing.T) {
+	bids := []Bid{{Price: 300}, {Price: 200}}
+
+	assert.NoError(t, ValidateWithdrawal(Withdrawal{Amount: 500, Destination: "card"}, 1000, bids))
+	assert.ErrorIs(t, ValidateWithdrawal(Withdrawal{Amount: 501, Destination: "card"}, 1000, bids), ErrInsufficientFunds)
+	assert.ErrorIs(t, ValidateWithdrawal(Withdrawal{Amount: 0, Destination: "card"}, 1000, nil), ErrInvalidWithdrawalAmount)
+	assert.ErrorIs(t, ValidateWithdrawal(Withdrawal{Amount: 100}, 1000, nil), ErrInvalidWithdrawalDestination)
+}
+
+func TestValidateWithdrawalResolution(t *testing.T) {
+	now := time.Now()
+	staleBefore := now.Add(-time.Minute)
+	status := func(status WithdrawalStatus) Withdrawal { return Withdrawal{Status: status} }
+
+	assert.NoError(t, ValidateWithdrawalResolution(status(WithdrawalPending), WithdrawalApproved, staleBefore))
+	assert.NoError(t, ValidateWithdrawalResolution(status(WithdrawalPending), WithdrawalRejected, staleBefore))
+	// Одобрение повторяется, если выплата не прошла
+	assert.NoError(t, ValidateWithdrawalResolution(status(WithdrawalApproved), WithdrawalApproved, staleBefore))
+	// После попытки выплаты заявку нельзя отклонить, а идущую выплату - повторить
+	assert.ErrorIs(t, ValidateWithdrawalResolution(status(WithdrawalApproved), WithdrawalRejected, staleBefore), ErrWithdrawalPayoutAttempted)
+	processing := Withdrawal{Status: WithdrawalProcessing, PayoutStartedAt: &now}
+	assert.ErrorIs(t, ValidateWithdrawalResolution(processing, WithdrawalApproved, staleBefore), ErrWithdrawalProcessing)
+	assert.ErrorIs(t, ValidateWithdrawalResolution(processing, WithdrawalRejected, staleBefore), ErrWithdrawalProcessing)
+	assert.ErrorIs(t, ValidateWithdrawalResolution(status(WithdrawalPending), WithdrawalPaid, staleBefore), ErrInvalidWithdrawalResolution)
+	assert.ErrorIs(t, ValidateWithdrawalResolution(status(WithdrawalPaid), WithdrawalRejected, staleBefore), ErrWithdrawalResolved)
+	assert.ErrorIs(t, ValidateWithdrawalResolution(status(WithdrawalRejected), WithdrawalApproved, staleBefore), ErrWithdrawalResolved)
+}
+
+func TestValidateStaleWithdrawalResolution(t *testing.T) {
+	startedAt := time.Now().Add(-time.Hour)
+	stale := Withdrawal{Status: WithdrawalProcessing, PayoutStartedAt: &startedAt}
+	staleBefore := time.Now().Add(-time.Minute)
+
+	// Зависшую выплату можно повторить, но не отклонить: провайдер мог её провести
+	assert.NoError(t, ValidateWithdrawalResolution(stale, WithdrawalApproved, staleBefore))
+	assert.ErrorIs(t, ValidateWithdrawalResolution(stale, WithdrawalRejected, staleBefore), ErrWithdrawalPayoutAttempted)
+	// Заявка без времени начала выплаты не считается зависшей
+	assert.ErrorIs(t, ValidateWithdrawalResolution(Withdrawal{Status: WithdrawalProcessing}, WithdrawalApproved, staleBefore), ErrWithdrawalProcessing)
+}
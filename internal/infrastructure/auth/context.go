@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"auction/internal/domain"
+	"context"
+	"slices"
+)
+
+// User - аутентифицированный пользователь, от имени которого выполняется запрос.
+// Роли заполняются интерцептором авторизации из таблицы user.
+type User struct {
+	ID    int
+	Roles []domain.Role
+}
+
+func (u User) HasRole(role domain.Role) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+type userKey struct{}
+
+// ContextWithUser сохраняет аутентифицированного пользователя в контексте
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext возвращает пользователя, сохранённого интерцептором аутентификации
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey{}).(User)
+	return user, ok
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// Key - ключ проверки подписи токена
+type Key struct {
+	ID     string
+	method jwt.SigningMethod
+	verify any
+}
+
+// NewHMACKey создаёт ключ для токенов, подписанных HS256
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) == 0 {
+		return Key{}, fmt.Errorf("key %q: empty secret", id)
+	}
+	return Key{ID: id, method: jwt.SigningMethodHS256, verify: secret}, nil
+}
+
+// NewRSAKey создаёт ключ для токенов, подписанных RS256, из публичного ключа в формате PEM
+func NewRSAKey(id string, publicKeyPEM []byte) (Key, error) {
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return Key{}, fmt.Errorf("key %q: %w", id, err)
+	}
+	return Key{ID: id, method: jwt.SigningMethodRS256, verify: publicKey}, nil
+}
+
+// Verifier проверяет JWT по локальному набору ключей
+type Verifier struct {
+	keys   []Key
+	parser *jwt.Parser
+}
+
+func NewVerifier(keys []Key, issuer, audience string) (*Verifier, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+// Verify проверяет подпись и срок действия токена и возвращает пользователя из claim sub
+func (v *Verifier) Verify(token string) (User, error) {
+	var claims jwt.RegisteredClaims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.keyFunc); err != nil {
+		return User{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || userID <= 0 {
+		return User{}, fmt.Errorf("%w: subject must be a user id", ErrInvalidToken)
+	}
+
+	return User{ID: userID}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var candidates []any
+	for _, key := range v.keys {
+		if key.method.Alg() != token.Method.Alg() {
+			continue
+		}
+		if kid != "" && key.ID == kid {
+			return key.verify, nil
+		}
+		candidates = append(candidates, key.verify)
+	}
+
+	// Токены без kid принимаются, только если ключ определяется однозначно
+	if kid == "" && len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, ErrUnknownKey
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.RegisteredClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(sub string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   sub,
+		Issuer:    "auction",
+		Audience:  jwt.ClaimStrings{"auction-api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	secret := []byte("test-secret")
+	hmacKey, err := NewHMACKey("local", secret)
+	require.NoError(t, err)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaPrivate.PublicKey)
+	require.NoError(t, err)
+	rsaKey, err := NewRSAKey("sso", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier([]Key{hmacKey, rsaKey}, "auction", "auction-api")
+	require.NoError(t, err)
+
+	expired := validClaims("7")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	foreignIssuer := validClaims("7")
+	foreignIssuer.Issuer = "someone-else"
+
+	tests := []struct {
+		name     string
+		token    string
+		wantUser User
+		wantErr  bool
+	}{
+		{
+			name:     "HS256 with kid",
+			token:    signToken(t, jwt.SigningMethodHS256, "local", secret, validClaims("7")),
+			wantUser: User{ID: 7},
+		},
+		{
+			name:     "HS256 without kid",
+			token:    signToken(t, jwt.SigningMethodHS256, "", secret, validClaims("7")),
+			wantUser: User{ID: 7},
+		},
+		{
+			name:     "RS256",
+			token:    signToken(t, jwt.SigningMethodRS256, "sso", rsaPrivate, validClaims("42")),
+			wantUser: User{ID: 42},
+		},
+		{
+			name:    "Wrong secret",
+			token:   signToken(t, jwt.SigningMethodHS256, "local", []byte("other"), validClaims("7")),
+			wantErr: true,
+		},
+		{
+			name:    "Unknown kid",
+			token:   signToken(t, jwt.SigningMethodHS256, "missing", secret, validClaims("7")),
+			wantErr: true,
+		},
+		{
+			name:    "Algorithm not allowed",
+			token:   signToken(t, jwt.SigningMethodHS512, "local", secret, validClaims("7")),
+			wantErr: true,
+		},
+		{
+			name:    "Expired",
+			token:   signToken(t, jwt.SigningMethodHS256, "local", secret, expired),
+			wantErr: true,
+		},
+		{
+			name:    "Foreign issuer",
+			token:   signToken(t, jwt.SigningMethodHS256, "local", secret, foreignIssuer),
+			wantErr: true,
+		},
+		{
+			name:    "Subject is not a user id",
+			token:   signToken(t, jwt.SigningMethodHS256, "local", secret, validClaims("admin")),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUser, user)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// New создаёт логгер, пишущий JSON в w, с уровнем по окружению env
+func New(env string, w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: Level(env)}))
+}
+
+// Level - уровень журнала для окружения: debug при разработке, info в остальных
+func Level(env string) slog.Level {
+	switch env {
+	case "development", "dev", "local":
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type loggerKey struct{}
+
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext возвращает логгер запроса или slog.Default, если в контексте логгера нет
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// With добавляет атрибуты к логгеру из контекста, например With(ctx, "lot_id", lotID)
+func With(ctx context.Context, args ...any) context.Context {
+	return ContextWithLogger(ctx, FromContext(ctx).With(args...))
+}
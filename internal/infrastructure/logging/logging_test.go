@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, Level("development"))
+	assert.Equal(t, slog.LevelDebug, Level("local"))
+	assert.Equal(t, slog.LevelInfo, Level("production"))
+	assert.Equal(t, slog.LevelInfo, Level(""))
+}
+
+func TestNew(t *testing.T) {
+	var out bytes.Buffer
+	logger := New("production", &out)
+
+	logger.Debug("hidden")
+	logger.Info("shown", "lot_id", 3)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "shown", line["msg"])
+	assert.Equal(t, 3.0, line["lot_id"])
+}
+
+func TestWith(t *testing.T) {
+	var out bytes.Buffer
+	ctx := ContextWithLogger(context.Background(), New("production", &out))
+
+	ctx = With(ctx, "request_id", "req-1")
+	ctx = With(ctx, "auction_id", 5)
+	FromContext(ctx).Info("settled")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, 5.0, line["auction_id"])
+}
+
+func TestFromContextDefault(t *testing.T) {
+	assert.Same(t, slog.Default(), FromContext(context.Background()))
+}
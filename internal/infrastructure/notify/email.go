@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig - параметры почтового сервера. Без username письма отправляются без авторизации.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// EmailDriver отправляет уведомления письмом через SMTP. STARTTLS включается,
+// если сервер его поддерживает.
+type EmailDriver struct {
+	cfg SMTPConfig
+}
+
+func NewEmailDriver(cfg SMTPConfig) *EmailDriver {
+	return &EmailDriver{cfg: cfg}
+}
+
+func (d *EmailDriver) Channel() domain.NotificationChannel {
+	return domain.ChannelEmail
+}
+
+func (d *EmailDriver) Send(ctx context.Context, user domain.User, message Message) error {
+	if user.Email == "" {
+		return errors.New("user has no email")
+	}
+	if d.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(d.cfg.Host, strconv.Itoa(d.cfg.Port)))
+	if err != nil {
+		return err
+	}
+	// net/smtp не принимает контекст, поэтому дедлайн ограничивает весь диалог с сервером
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, d.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: d.cfg.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if d.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", d.cfg.Username, d.cfg.Password, d.cfg.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(d.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(user.Email); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(d.compose(user.Email, message)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose собирает письмо в UTF-8: тема в кодировке RFC 2047, тело в quoted-printable.
+// Если у сообщения есть HTML, письмо отправляется как multipart/alternative с текстовой
+// и HTML-версией.
+func (d *EmailDriver) compose(to string, message Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", d.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writeQuotedPrintable(&buf, message.Body)
+		return buf.Bytes()
+	}
+
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", message.Body},
+		{"text/html; charset=UTF-8", message.HTML},
+	} {
+		w, _ := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(w, part.content)
+	}
+	_ = parts.Close()
+	return buf.Bytes()
+}
+
+func writeQuotedPrintable(w io.Writer, content string) {
+	qp := quotedprintable.NewWriter(w)
+	_, _ = qp.Write([]byte(content))
+	_ = qp.Close()
+}
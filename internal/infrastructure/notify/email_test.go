@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data []byte
+}
+
+// smtpServer - минимальный SMTP-сервер, принимающий письма без TLS
+type smtpServer struct {
+	host     string
+	port     int
+	messages chan smtpMessage
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	addr := listener.Addr().(*net.TCPAddr)
+	server := &smtpServer{host: addr.IP.String(), port: addr.Port, messages: make(chan smtpMessage, 1)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	var message smtpMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN "):
+			credentials, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			message.auth = string(credentials)
+			_ = tp.PrintfLine("235 Authenticated")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			_ = tp.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.to = append(message.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			_ = tp.PrintfLine("250 OK")
+		case command == "DATA":
+			_ = tp.PrintfLine("354 Go ahead")
+			message.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			_ = tp.PrintfLine("250 OK")
+			s.messages <- message
+		case command == "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func TestEmailDriverSend(t *testing.T) {
+	server := newSMTPServer(t)
+	driver := NewEmailDriver(SMTPConfig{
+		Host:     server.host,
+		Port:     server.port,
+		Username: "auction",
+		Password: "secret",
+		From:     "auction@example.com",
+		Timeout:  time.Second,
+	})
+	user := domain.User{UserID: 1, Email: "bidder@example.com"}
+
+	err := driver.Send(context.Background(), user, Message{Subject: "Аукцион 5: победа", Body: "Вы победили в аукционе 5"})
+	require.NoError(t, err)
+
+	var received smtpMessage
+	select {
+	case received = <-server.messages:
+	case <-time.After(time.Second):
+		t.Fatal("message was not received")
+	}
+	assert.Equal(t, "\x00auction\x00secret", received.auth)
+	assert.Equal(t, "auction@example.com", received.from)
+	assert.Equal(t, []string{"bidder@example.com"}, received.to)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(received.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Аукцион 5: победа", subject)
+	assert.Equal(t, "bidder@example.com", parsed.Header.Get("To"))
+
+	body, err := io.ReadAll(quotedprintable.NewReader(parsed.Body))
+	require.NoError(t, err)
+	// ReadDotBytes оставляет перевод строки перед завершающей точкой
+	assert.Equal(t, "Вы победили в аукционе 5", strings.TrimSuffix(string(body), "\n"))
+}
+
+func TestEmailDriverErrors(t *testing.T) {
+	driver := NewEmailDriver(SMTPConfig{Host: "127.0.0.1", Port: 1, Timeout: time.Second})
+
+	assert.EqualError(t, driver.Send(context.Background(), domain.User{}, Message{}), "user has no email")
+
+	// На порту никто не слушает
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	driver = NewEmailDriver(SMTPConfig{Host: "127.0.0.1", Port: port, Timeout: time.Second})
+	err = driver.Send(context.Background(), domain.User{Email: "bidder@example.com"}, Message{})
+	assert.ErrorContains(t, err, strconv.Itoa(port))
+}
+
+func TestEmailComposeWithHTML(t *testing.T) {
+	driver := NewEmailDriver(SMTPConfig{From: "auction@example.com"})
+
+	data := driver.compose("bidder@example.com", Message{Subject: "Победа", Body: "Вы победили", HTML: "<p>Вы победили</p>"})
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for _, expected := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", "Вы победили"},
+		{"text/html; charset=UTF-8", "<p>Вы победили</p>"},
+	} {
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, expected.contentType, part.Header.Get("Content-Type"))
+		// multipart.Reader сам декодирует quoted-printable
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, expected.body, string(body))
+	}
+	_, err = reader.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -2,57 +2,262 @@ package notify
 
 import (
 	"auction/internal/domain"
+	"auction/internal/infrastructure/logging"
 	"auction/internal/infrastructure/repo"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 )
 
 type NotifyService interface {
-	NotifyUser(ctx context.Context, userID int, message string) error
-	NotifyAllUsersAboutNewAuctions(ctx context.Context, auctions []domain.Auction) error
+	NotifyUser(ctx context.Context, userID int, event domain.NotificationEvent, data TemplateData) error
+	NotifyAllUsersAboutNewAuctions(ctx context.Context, auctions []AuctionSummary) error
+	// DeliverDeferred отправляет уведомление, отложенное до конца тихих часов
+	DeliverDeferred(ctx context.Context, deferred domain.NotificationDeferredEvent) error
 }
 
+// Message - уведомление, отрендеренное на языке получателя. HTML используется только в письмах.
+type Message struct {
+	Event   domain.NotificationEvent
+	Subject string
+	Body    string
+	HTML    string
+}
+
+// Driver доставляет уведомление пользователю по одному каналу
+type Driver interface {
+	Channel() domain.NotificationChannel
+	Send(ctx context.Context, user domain.User, message Message) error
+}
+
+// notifyService рассылает уведомление по каналам, выбранным пользователем для события,
+// и сохраняет итог доставки по каждому из них. Доставка в тихие часы откладывается
+// сообщением в outbox.
 type notifyService struct {
-	userRepo repo.UserRepository
+	userRepo         repo.UserRepository
+	notificationRepo repo.NotificationRepository
+	preferencesRepo  repo.NotificationPreferencesRepository
+	outboxRepo       repo.OutboxRepository
+	templates        *Templates
+	drivers          map[domain.NotificationChannel]Driver
+	now              func() time.Time
 }
 
-func NewNotifyService(userRepo repo.UserRepository) NotifyService {
+func NewNotifyService(
+	userRepo repo.UserRepository,
+	notificationRepo repo.NotificationRepository,
+	preferencesRepo repo.NotificationPreferencesRepository,
+	outboxRepo repo.OutboxRepository,
+	templates *Templates,
+	drivers ...Driver,
+) NotifyService {
+	byChannel := make(map[domain.NotificationChannel]Driver, len(drivers))
+	for _, driver := range drivers {
+		byChannel[driver.Channel()] = driver
+	}
 	return &notifyService{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		preferencesRepo:  preferencesRepo,
+		outboxRepo:       outboxRepo,
+		templates:        templates,
+		drivers:          byChannel,
+		now:              time.Now,
 	}
 }
 
-func (n *notifyService) NotifyUser(ctx context.Context, userID int, message string) error {
-	// Логика отправки уведомления пользователю
-	// Например, отправка через email или push-уведомления
-	fmt.Printf("Sending notification to user %d: %s\n", userID, message)
-	return nil
+func (s *notifyService) NotifyUser(ctx context.Context, userID int, event domain.NotificationEvent, data TemplateData) error {
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	preferences, err := s.preferencesRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.deliver(ctx, user, preferences, event, data)
 }
 
-func (s *notifyService) NotifyAllUsersAboutNewAuctions(ctx context.Context, auctions []domain.Auction) error {
+func (s *notifyService) NotifyAllUsersAboutNewAuctions(ctx context.Context, auctions []AuctionSummary) error {
 	users, err := s.userRepo.GetAllUsers(ctx)
 	if err != nil {
 		return err
 	}
+	userIDs := make([]int, len(users))
+	for i, user := range users {
+		userIDs[i] = user.UserID
+	}
+	preferences, err := s.preferencesRepo.GetForUsers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
 
-	auctionList := ""
-	for _, auction := range auctions {
-		auctionList += fmt.Sprintf("Auction ID: %d", auction.AuctionID)
+	data := TemplateData{Auctions: auctions}
+	for _, user := range users {
+		if err := s.deliver(ctx, user, preferences[user.UserID], domain.EventNewAuctions, data); err != nil {
+			logging.FromContext(ctx).Error("failed to send notification", "user_id", user.UserID, "error", err)
+		}
 	}
 
-	message := fmt.Sprintf("New auctions have started:\n%s", auctionList)
+	return nil
+}
+
+// deliver рендерит сообщение на языке пользователя и отправляет его по каждому каналу,
+// выбранному для события. В тихие часы каналы, кроме ленты, откладываются до их конца.
+func (s *notifyService) deliver(
+	ctx context.Context,
+	user domain.User,
+	preferences domain.NotificationPreferences,
+	event domain.NotificationEvent,
+	data TemplateData,
+) error {
+	now := s.now()
+	channels, deferred := preferences.Channels(event, user.NotificationChannels, now)
+	if len(channels) == 0 && len(deferred) == 0 {
+		return nil
+	}
 
-	for _, user := range users {
-		err := s.sendNotification(ctx, user.ID, message)
-		if err != nil {
-			fmt.Printf("Error sending notification to user %d: %v", user.ID, err)
+	data.UserName = user.Name
+	message, err := s.templates.Render(user.Locale, event, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s notification: %w", event, err)
+	}
+
+	return s.sendAndDefer(ctx, user, message, channels, deferred, preferences.QuietHours, now)
+}
+
+// DeliverDeferred отправляет отложенное уведомление по каналам, которые пользователь
+// не отключил за время тихих часов. Если тихие часы снова начались, доставка опять откладывается.
+func (s *notifyService) DeliverDeferred(ctx context.Context, deferred domain.NotificationDeferredEvent) error {
+	user, err := s.userRepo.GetUser(ctx, deferred.UserID)
+	if err != nil {
+		return err
+	}
+	preferences, err := s.preferencesRepo.Get(ctx, deferred.UserID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	current, later := preferences.Channels(deferred.Event, user.NotificationChannels, now)
+	requested := func(channel domain.NotificationChannel) bool {
+		return !slices.Contains(deferred.Channels, channel)
+	}
+	current = slices.DeleteFunc(current, requested)
+	later = slices.DeleteFunc(later, requested)
+
+	message := Message{Event: deferred.Event, Subject: deferred.Subject, Body: deferred.Body, HTML: deferred.HTML}
+	return s.sendAndDefer(ctx, user, message, current, later, preferences.QuietHours, now)
+}
+
+// sendAndDefer отправляет message по channels и откладывает отправку по deferred до конца тихих часов.
+// Отложенная доставка записывается только после успешной отправки: если отправка не удалась,
+// outbox повторит сообщение, и без этого каждая попытка откладывала бы ещё одну копию.
+func (s *notifyService) sendAndDefer(
+	ctx context.Context,
+	user domain.User,
+	message Message,
+	channels, deferred []domain.NotificationChannel,
+	quietHours *domain.QuietHours,
+	now time.Time,
+) error {
+	if err := s.send(ctx, user, message, channels, now); err != nil {
+		return err
+	}
+	if len(deferred) == 0 {
+		return nil
+	}
+	return s.deferDelivery(ctx, user.UserID, message, deferred, quietHours.End(now))
+}
+
+// deferDelivery откладывает отправку message по channels до until
+func (s *notifyService) deferDelivery(
+	ctx context.Context,
+	userID int,
+	message Message,
+	channels []domain.NotificationChannel,
+	until time.Time,
+) error {
+	outboxMessage, err := domain.NewOutboxMessage(domain.OutboxNotificationDeferred, domain.NotificationDeferredEvent{
+		UserID:   userID,
+		Event:    message.Event,
+		Channels: channels,
+		Subject:  message.Subject,
+		Body:     message.Body,
+		HTML:     message.HTML,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal deferred notification: %w", err)
+	}
+	outboxMessage.NextAttemptAt = until
+	if err := s.outboxRepo.Enqueue(ctx, outboxMessage); err != nil {
+		return fmt.Errorf("failed to defer notification: %w", err)
+	}
+	return nil
+}
+
+// send отправляет сообщение по каждому каналу и сохраняет итог доставки. Ошибка возвращается,
+// только если не удалось ни одного канала или не сохранились записи о доставке.
+func (s *notifyService) send(
+	ctx context.Context,
+	user domain.User,
+	message Message,
+	channels []domain.NotificationChannel,
+	now time.Time,
+) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	notifications := make([]domain.Notification, 0, len(channels))
+	var errs []error
+	for _, channel := range channels {
+		notification := domain.Notification{
+			UserID:    user.UserID,
+			Event:     message.Event,
+			Channel:   channel,
+			Subject:   message.Subject,
+			Body:      message.Body,
+			Status:    domain.NotificationSent,
+			CreatedAt: now,
 		}
+		if err := s.sendTo(ctx, channel, user, message); err != nil {
+			logging.FromContext(ctx).Warn("notification not delivered",
+				"user_id", user.UserID, "channel", channel, "error", err)
+			notification.Status = domain.NotificationFailed
+			notification.Error = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+		notifications = append(notifications, notification)
 	}
 
+	if err := s.notificationRepo.Create(ctx, notifications...); err != nil {
+		return fmt.Errorf("failed to record notifications: %w", err)
+	}
+	if len(errs) == len(notifications) {
+		return errors.Join(errs...)
+	}
 	return nil
 }
 
-func (s *notifyService) sendNotification(ctx context.Context, userID int, message string) error {
-	fmt.Printf("Sending notification to user %d: %s\n", userID, message)
+func (s *notifyService) sendTo(ctx context.Context, channel domain.NotificationChannel, user domain.User, message Message) error {
+	driver, ok := s.drivers[channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", channel)
+	}
+	return driver.Send(ctx, user, message)
+}
+
+// InboxDriver - лента уведомлений в приложении. Отправлять ничего не нужно: записью в ленте
+// служит сохранённое уведомление.
+type InboxDriver struct{}
+
+func (InboxDriver) Channel() domain.NotificationChannel {
+	return domain.ChannelInbox
+}
+
+func (InboxDriver) Send(context.Context, domain.User, Message) error {
 	return nil
 }
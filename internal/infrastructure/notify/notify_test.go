@@ -0,0 +1,337 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/repo"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsers struct {
+	repo.UserRepository
+	users []domain.User
+}
+
+func (f *fakeUsers) GetUser(_ context.Context, userID int) (domain.User, error) {
+	for _, user := range f.users {
+		if user.UserID == userID {
+			return user, nil
+		}
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+func (f *fakeUsers) GetAllUsers(context.Context) ([]domain.User, error) {
+	return f.users, nil
+}
+
+type fakeNotifications struct {
+	repo.NotificationRepository
+	created []domain.Notification
+}
+
+func (f *fakeNotifications) Create(_ context.Context, notifications ...domain.Notification) error {
+	f.created = append(f.created, notifications...)
+	return nil
+}
+
+type fakePreferences struct {
+	repo.NotificationPreferencesRepository
+	preferences map[int]domain.NotificationPreferences
+}
+
+func (f *fakePreferences) Get(_ context.Context, userID int) (domain.NotificationPreferences, error) {
+	return f.preferences[userID], nil
+}
+
+func (f *fakePreferences) GetForUsers(_ context.Context, userIDs []int) (map[int]domain.NotificationPreferences, error) {
+	result := map[int]domain.NotificationPreferences{}
+	for _, userID := range userIDs {
+		if preferences, ok := f.preferences[userID]; ok {
+			result[userID] = preferences
+		}
+	}
+	return result, nil
+}
+
+// fakeOutbox запоминает отложенные уведомления
+type fakeOutbox struct {
+	repo.OutboxRepository
+	messages []domain.OutboxMessage
+}
+
+func (f *fakeOutbox) Enqueue(_ context.Context, messages ...domain.OutboxMessage) error {
+	f.messages = append(f.messages, messages...)
+	return nil
+}
+
+// fakeDriver запоминает отправленные сообщения и возвращает err
+type fakeDriver struct {
+	channel domain.NotificationChannel
+	err     error
+	sent    []Message
+}
+
+func (d *fakeDriver) Channel() domain.NotificationChannel {
+	return d.channel
+}
+
+func (d *fakeDriver) Send(_ context.Context, _ domain.User, message Message) error {
+	d.sent = append(d.sent, message)
+	return d.err
+}
+
+func newTestNotifyService(t *testing.T, users repo.UserRepository, store repo.NotificationRepository, drivers ...Driver) NotifyService {
+	t.Helper()
+	return newTestNotifyServiceWithPreferences(t, users, store, &fakePreferences{}, &fakeOutbox{}, drivers...)
+}
+
+func newTestNotifyServiceWithPreferences(
+	t *testing.T,
+	users repo.UserRepository,
+	store repo.NotificationRepository,
+	preferences repo.NotificationPreferencesRepository,
+	outbox repo.OutboxRepository,
+	drivers ...Driver,
+) NotifyService {
+	t.Helper()
+	templates, err := NewTemplates("https://auction.example.com")
+	require.NoError(t, err)
+	return NewNotifyService(users, store, preferences, outbox, templates, drivers...)
+}
+
+var wonData = TemplateData{AuctionID: 5, LotID: 2, LotTitle: "Часы", Price: 300}
+
+func TestNotifyUserRecordsStatusPerChannel(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		Name:                 "Анна",
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelInbox, domain.ChannelWebhook, domain.ChannelEmail},
+	}}}
+	store := &fakeNotifications{}
+	webhook := &fakeDriver{channel: domain.ChannelWebhook, err: errors.New("connection refused")}
+	service := newTestNotifyService(t, users, store, InboxDriver{}, webhook)
+
+	err := service.NotifyUser(context.Background(), 1, domain.EventAuctionWon, wonData)
+
+	require.NoError(t, err)
+	require.Len(t, store.created, 3)
+	assert.Equal(t, domain.ChannelInbox, store.created[0].Channel)
+	assert.Equal(t, domain.NotificationSent, store.created[0].Status)
+	assert.Equal(t, domain.EventAuctionWon, store.created[0].Event)
+	assert.Equal(t, "Вы выиграли «Часы»", store.created[0].Subject)
+	assert.Contains(t, store.created[0].Body, "Здравствуйте, Анна!")
+
+	assert.Equal(t, domain.NotificationFailed, store.created[1].Status)
+	assert.Equal(t, "connection refused", store.created[1].Error)
+
+	// Почта не настроена
+	assert.Equal(t, domain.ChannelEmail, store.created[2].Channel)
+	assert.Equal(t, domain.NotificationFailed, store.created[2].Status)
+	assert.Contains(t, store.created[2].Error, "not configured")
+	require.Len(t, webhook.sent, 1)
+	assert.Contains(t, webhook.sent[0].HTML, `<a href="https://auction.example.com/auctions/5">`)
+}
+
+func TestNotifyUserRendersUserLocale(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		Locale:               domain.LocaleEN,
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelInbox},
+	}}}
+	store := &fakeNotifications{}
+	service := newTestNotifyService(t, users, store, InboxDriver{})
+
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventAuctionWon, wonData))
+
+	require.Len(t, store.created, 1)
+	assert.Equal(t, `You won "Часы"`, store.created[0].Subject)
+}
+
+func TestNotifyUserFailsWhenNoChannelDelivered(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelWebhook},
+	}}}
+	store := &fakeNotifications{}
+	webhook := &fakeDriver{channel: domain.ChannelWebhook, err: errors.New("502 Bad Gateway")}
+	service := newTestNotifyService(t, users, store, webhook)
+
+	err := service.NotifyUser(context.Background(), 1, domain.EventAuctionLost, wonData)
+
+	assert.ErrorContains(t, err, "webhook: 502 Bad Gateway")
+	require.Len(t, store.created, 1)
+	assert.Equal(t, domain.NotificationFailed, store.created[0].Status)
+}
+
+func TestNotifyUserWithoutChannels(t *testing.T) {
+	store := &fakeNotifications{}
+	service := newTestNotifyService(t, &fakeUsers{users: []domain.User{{UserID: 1}}}, store, InboxDriver{})
+
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventOutbid, wonData))
+	assert.Empty(t, store.created)
+
+	assert.ErrorIs(t, service.NotifyUser(context.Background(), 2, domain.EventOutbid, wonData), domain.ErrUserNotFound)
+}
+
+func TestNotifyAllUsersAboutNewAuctions(t *testing.T) {
+	inbox := []domain.NotificationChannel{domain.ChannelInbox}
+	users := &fakeUsers{users: []domain.User{
+		{UserID: 1, NotificationChannels: inbox},
+		{UserID: 2, NotificationChannels: inbox, Locale: domain.LocaleEN},
+	}}
+	store := &fakeNotifications{}
+	service := newTestNotifyService(t, users, store, InboxDriver{})
+
+	err := service.NotifyAllUsersAboutNewAuctions(context.Background(), []AuctionSummary{
+		{AuctionID: 3, LotTitle: "Часы", StartPrice: 100},
+		{AuctionID: 4, LotTitle: "Картина", StartPrice: 500},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, store.created, 2)
+	assert.Equal(t, "Новые аукционы: 2", store.created[0].Subject)
+	assert.Contains(t, store.created[0].Body, "- «Картина», стартовая цена 500: https://auction.example.com/auctions/4")
+	assert.Equal(t, 2, store.created[1].UserID)
+	assert.Equal(t, "New auctions: 2", store.created[1].Subject)
+}
+
+func TestNotifyUserConsultsPreferences(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelInbox, domain.ChannelEmail},
+	}}}
+	preferences := &fakePreferences{preferences: map[int]domain.NotificationPreferences{1: {
+		UserID:         1,
+		DisabledEvents: []domain.NotificationEvent{domain.EventEndingSoon},
+		EventChannels:  map[domain.NotificationEvent][]domain.NotificationChannel{domain.EventOutbid: {domain.ChannelEmail}},
+		QuietHours:     &domain.QuietHours{StartMinute: 22 * 60, EndMinute: 8 * 60, Timezone: "Europe/Moscow"},
+	}}}
+	store := &fakeNotifications{}
+	outbox := &fakeOutbox{}
+	email := &fakeDriver{channel: domain.ChannelEmail}
+	service := newTestNotifyServiceWithPreferences(t, users, store, preferences, outbox, InboxDriver{}, email).(*notifyService)
+	// 12:00 по Москве
+	service.now = func() time.Time { return time.Date(2024, 10, 17, 9, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventEndingSoon, wonData))
+	assert.Empty(t, store.created)
+
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventOutbid, wonData))
+	require.Len(t, store.created, 1)
+	assert.Equal(t, domain.ChannelEmail, store.created[0].Channel)
+
+	// 23:00 по Москве: уведомление сразу попадает в ленту, письмо откладывается до 08:00
+	service.now = func() time.Time { return time.Date(2024, 10, 17, 20, 0, 0, 0, time.UTC) }
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventAuctionWon, wonData))
+	require.Len(t, store.created, 2)
+	assert.Equal(t, domain.ChannelInbox, store.created[1].Channel)
+	assert.Len(t, email.sent, 1)
+
+	require.Len(t, outbox.messages, 1)
+	deferred := outbox.messages[0]
+	assert.Equal(t, domain.OutboxNotificationDeferred, deferred.Topic)
+	assert.Equal(t, time.Date(2024, 10, 18, 5, 0, 0, 0, time.UTC), deferred.NextAttemptAt.UTC())
+	var event domain.NotificationDeferredEvent
+	require.NoError(t, json.Unmarshal(deferred.Payload, &event))
+	assert.Equal(t, []domain.NotificationChannel{domain.ChannelEmail}, event.Channels)
+	assert.Equal(t, domain.EventAuctionWon, event.Event)
+	assert.Equal(t, store.created[1].Subject, event.Subject)
+}
+
+func TestNotifyUserDefersOnceWhenImmediateChannelsFail(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelInbox, domain.ChannelEmail},
+	}}}
+	preferences := &fakePreferences{preferences: map[int]domain.NotificationPreferences{1: {
+		UserID:     1,
+		QuietHours: &domain.QuietHours{StartMinute: 22 * 60, EndMinute: 8 * 60},
+	}}}
+	outbox := &fakeOutbox{}
+	inbox := &fakeDriver{channel: domain.ChannelInbox, err: errors.New("database is down")}
+	service := newTestNotifyServiceWithPreferences(t, users, &fakeNotifications{}, preferences, outbox,
+		inbox, &fakeDriver{channel: domain.ChannelEmail}).(*notifyService)
+	service.now = func() time.Time { return time.Date(2024, 10, 17, 23, 0, 0, 0, time.UTC) }
+
+	// Outbox повторяет сообщение, пока лента недоступна: письмо при этом не откладывается
+	for range 3 {
+		assert.Error(t, service.NotifyUser(context.Background(), 1, domain.EventOutbid, wonData))
+	}
+	assert.Empty(t, outbox.messages)
+
+	inbox.err = nil
+	require.NoError(t, service.NotifyUser(context.Background(), 1, domain.EventOutbid, wonData))
+	require.Len(t, outbox.messages, 1)
+	assert.Equal(t, domain.OutboxNotificationDeferred, outbox.messages[0].Topic)
+}
+
+func TestDeliverDeferred(t *testing.T) {
+	users := &fakeUsers{users: []domain.User{{
+		UserID:               1,
+		NotificationChannels: []domain.NotificationChannel{domain.ChannelInbox, domain.ChannelEmail, domain.ChannelWebhook},
+	}}}
+	preferences := &fakePreferences{preferences: map[int]domain.NotificationPreferences{1: {
+		UserID:     1,
+		QuietHours: &domain.QuietHours{StartMinute: 22 * 60, EndMinute: 8 * 60},
+	}}}
+	store := &fakeNotifications{}
+	outbox := &fakeOutbox{}
+	email := &fakeDriver{channel: domain.ChannelEmail}
+	webhook := &fakeDriver{channel: domain.ChannelWebhook}
+	service := newTestNotifyServiceWithPreferences(t, users, store, preferences, outbox, InboxDriver{}, email, webhook).(*notifyService)
+	deferred := domain.NotificationDeferredEvent{
+		UserID:   1,
+		Event:    domain.EventOutbid,
+		Channels: []domain.NotificationChannel{domain.ChannelEmail},
+		Subject:  "Вашу ставку перебили",
+		Body:     "Текст",
+	}
+
+	// Тихие часы снова начались: доставка откладывается ещё раз
+	service.now = func() time.Time { return time.Date(2024, 10, 17, 23, 0, 0, 0, time.UTC) }
+	require.NoError(t, service.DeliverDeferred(context.Background(), deferred))
+	assert.Empty(t, email.sent)
+	require.Len(t, outbox.messages, 1)
+	assert.Equal(t, time.Date(2024, 10, 18, 8, 0, 0, 0, time.UTC), outbox.messages[0].NextAttemptAt)
+
+	// После тихих часов уходят только отложенные каналы, лента не дублируется
+	service.now = func() time.Time { return time.Date(2024, 10, 18, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, service.DeliverDeferred(context.Background(), deferred))
+	require.Len(t, email.sent, 1)
+	assert.Equal(t, "Вашу ставку перебили", email.sent[0].Subject)
+	assert.Empty(t, webhook.sent)
+	require.Len(t, store.created, 1)
+	assert.Equal(t, domain.ChannelEmail, store.created[0].Channel)
+	assert.Equal(t, domain.EventOutbid, store.created[0].Event)
+
+	// Канал, отключённый за время тихих часов, пропускается
+	users.users[0].NotificationChannels = []domain.NotificationChannel{domain.ChannelInbox}
+	require.NoError(t, service.DeliverDeferred(context.Background(), deferred))
+	assert.Len(t, email.sent, 1)
+	assert.Len(t, store.created, 1)
+}
+
+func TestNotifyAllUsersSkipsDisabledNewAuctions(t *testing.T) {
+	inbox := []domain.NotificationChannel{domain.ChannelInbox}
+	users := &fakeUsers{users: []domain.User{
+		{UserID: 1, NotificationChannels: inbox},
+		{UserID: 2, NotificationChannels: inbox},
+	}}
+	preferences := &fakePreferences{preferences: map[int]domain.NotificationPreferences{
+		1: {UserID: 1, DisabledEvents: []domain.NotificationEvent{domain.EventNewAuctions}},
+	}}
+	store := &fakeNotifications{}
+	service := newTestNotifyServiceWithPreferences(t, users, store, preferences, &fakeOutbox{}, InboxDriver{})
+
+	require.NoError(t, service.NotifyAllUsersAboutNewAuctions(context.Background(), []AuctionSummary{{AuctionID: 3}}))
+
+	require.Len(t, store.created, 1)
+	assert.Equal(t, 2, store.created[0].UserID)
+}
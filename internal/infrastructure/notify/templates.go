@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// TemplateData - данные для шаблона уведомления. Ссылки и имя получателя заполняются
+// при рендеринге.
+type TemplateData struct {
+	UserName    string
+	AuctionID   int
+	LotID       int
+	LotTitle    string
+	Price       int64
+	MinutesLeft int
+	// Auctions - новые аукционы для рассылки new_auctions
+	Auctions   []AuctionSummary
+	AuctionURL string
+}
+
+// AuctionSummary - аукцион в рассылке о новых аукционах
+type AuctionSummary struct {
+	AuctionID  int
+	LotTitle   string
+	StartPrice int64
+	URL        string
+}
+
+type templateKey struct {
+	locale domain.Locale
+	event  domain.NotificationEvent
+}
+
+// Templates рендерит уведомления из шаблонов templates/<locale>/<event>: в .txt заданы
+// блоки subject и text, .html - тело письма. Общие блоки лежат в common.txt и common.html.
+type Templates struct {
+	baseURL string
+	text    map[templateKey]*texttemplate.Template
+	html    map[templateKey]*htmltemplate.Template
+}
+
+// NewTemplates разбирает шаблоны всех событий для всех языков. baseURL - адрес сайта,
+// от которого строятся ссылки на аукционы.
+func NewTemplates(baseURL string) (*Templates, error) {
+	t := &Templates{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		text:    make(map[templateKey]*texttemplate.Template),
+		html:    make(map[templateKey]*htmltemplate.Template),
+	}
+	for _, locale := range domain.Locales {
+		dir := "templates/" + string(locale)
+		for _, event := range domain.NotificationEvents {
+			key := templateKey{locale: locale, event: event}
+			text, err := texttemplate.New(string(event)).Option("missingkey=error").
+				ParseFS(templateFiles, dir+"/"+string(event)+".txt", dir+"/common.txt")
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s/%s text template: %w", locale, event, err)
+			}
+			html, err := htmltemplate.New(string(event)).Option("missingkey=error").
+				ParseFS(templateFiles, dir+"/"+string(event)+".html", dir+"/common.html")
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s/%s html template: %w", locale, event, err)
+			}
+			t.text[key], t.html[key] = text, html
+		}
+	}
+	return t, nil
+}
+
+// Render собирает сообщение о событии на языке locale. Для неизвестного языка
+// используется язык по умолчанию.
+func (t *Templates) Render(locale domain.Locale, event domain.NotificationEvent, data TemplateData) (Message, error) {
+	if !slices.Contains(domain.Locales, locale) {
+		locale = domain.DefaultLocale
+	}
+	key := templateKey{locale: locale, event: event}
+	text, ok := t.text[key]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for event %s", event)
+	}
+
+	if data.AuctionID != 0 {
+		data.AuctionURL = t.auctionURL(data.AuctionID)
+	}
+	data.Auctions = slices.Clone(data.Auctions)
+	for i := range data.Auctions {
+		data.Auctions[i].URL = t.auctionURL(data.Auctions[i].AuctionID)
+	}
+
+	var subject, body, html bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html[key].ExecuteTemplate(&html, string(event)+".html", data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Event:   event,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()),
+		HTML:    strings.TrimSpace(html.String()),
+	}, nil
+}
+
+func (t *Templates) auctionURL(auctionID int) string {
+	return t.baseURL + "/auctions/" + strconv.Itoa(auctionID)
+}
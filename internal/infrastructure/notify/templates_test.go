@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "перезаписать golden-файлы шаблонов")
+
+// sampleData - данные, на которых рендерятся golden-файлы
+var sampleData = map[domain.NotificationEvent]TemplateData{
+	domain.EventAuctionWon:  {UserName: "Анна", AuctionID: 12, LotID: 5, LotTitle: "Часы <Полёт>", Price: 1500},
+	domain.EventAuctionLost: {UserName: "Анна", AuctionID: 12, LotID: 5, LotTitle: "Часы <Полёт>", Price: 1500},
+	domain.EventOutbid:      {UserName: "Анна", AuctionID: 12, LotID: 5, LotTitle: "Часы <Полёт>", Price: 1200},
+	domain.EventFirstBid:    {UserName: "Борис", AuctionID: 12, LotID: 5, LotTitle: "Часы <Полёт>", Price: 1000},
+	domain.EventEndingSoon:  {AuctionID: 12, LotID: 5, LotTitle: "Часы <Полёт>", MinutesLeft: 15},
+	domain.EventNewAuctions: {UserName: "Анна", Auctions: []AuctionSummary{
+		{AuctionID: 12, LotTitle: "Часы <Полёт>", StartPrice: 1000},
+		{AuctionID: 13, LotTitle: "Картина", StartPrice: 5000},
+	}},
+}
+
+func TestTemplatesGolden(t *testing.T) {
+	templates, err := NewTemplates("https://auction.example.com/")
+	require.NoError(t, err)
+
+	for _, locale := range domain.Locales {
+		for _, event := range domain.NotificationEvents {
+			t.Run(string(locale)+"/"+string(event), func(t *testing.T) {
+				data, ok := sampleData[event]
+				require.True(t, ok, "no sample data for %s", event)
+
+				message, err := templates.Render(locale, event, data)
+				require.NoError(t, err)
+				assert.Equal(t, event, message.Event)
+
+				base := filepath.Join("testdata", string(locale), string(event))
+				assertGolden(t, base+".subject", message.Subject)
+				assertGolden(t, base+".txt", message.Body)
+				assertGolden(t, base+".html", message.HTML)
+			})
+		}
+	}
+}
+
+func assertGolden(t *testing.T, path, actual string) {
+	t.Helper()
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(actual+"\n"), 0o644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "run go test with -update to create golden files")
+	assert.Equal(t, string(expected), actual+"\n", path)
+}
+
+func TestTemplatesFallbackToDefaultLocale(t *testing.T) {
+	templates, err := NewTemplates("https://auction.example.com")
+	require.NoError(t, err)
+	data := sampleData[domain.EventOutbid]
+
+	expected, err := templates.Render(domain.DefaultLocale, domain.EventOutbid, data)
+	require.NoError(t, err)
+	for _, locale := range []domain.Locale{"", "de"} {
+		message, err := templates.Render(locale, domain.EventOutbid, data)
+		require.NoError(t, err)
+		assert.Equal(t, expected, message)
+	}
+
+	_, err = templates.Render(domain.LocaleEN, "unknown", data)
+	assert.EqualError(t, err, "no template for event unknown")
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookDriver отправляет уведомления POST-запросом с JSON на адрес, указанный пользователем
+type WebhookDriver struct {
+	client *http.Client
+}
+
+func NewWebhookDriver(client *http.Client) *WebhookDriver {
+	return &WebhookDriver{client: client}
+}
+
+// webhookPayload - тело запроса к webhook пользователя
+type webhookPayload struct {
+	UserID  int       `json:"user_id"`
+	Event   string    `json:"event,omitempty"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+func (d *WebhookDriver) Channel() domain.NotificationChannel {
+	return domain.ChannelWebhook
+}
+
+func (d *WebhookDriver) Send(ctx context.Context, user domain.User, message Message) error {
+	if user.WebhookURL == "" {
+		return errors.New("user has no webhook url")
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		UserID:  user.UserID,
+		Event:   string(message.Event),
+		Subject: message.Subject,
+		Body:    message.Body,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, user.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
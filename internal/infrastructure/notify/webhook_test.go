@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"auction/internal/domain"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDriverSend(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	driver := NewWebhookDriver(server.Client())
+	user := domain.User{UserID: 7, WebhookURL: server.URL + "/hooks/auction"}
+
+	err := driver.Send(context.Background(), user, Message{Event: domain.EventAuctionWon, Subject: "Аукцион 5", Body: "Вы победили"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, received.UserID)
+	assert.Equal(t, "auction_won", received.Event)
+	assert.Equal(t, "Аукцион 5", received.Subject)
+	assert.Equal(t, "Вы победили", received.Body)
+	assert.False(t, received.SentAt.IsZero())
+}
+
+func TestWebhookDriverErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	driver := NewWebhookDriver(server.Client())
+
+	err := driver.Send(context.Background(), domain.User{WebhookURL: server.URL}, Message{})
+	assert.EqualError(t, err, "webhook responded with 502 Bad Gateway")
+
+	err = driver.Send(context.Background(), domain.User{}, Message{})
+	assert.EqualError(t, err, "user has no webhook url")
+}
@@ -0,0 +1,64 @@
+package outbound
+
+import (
+	"auction/internal/domain"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// maxRedirects - сколько перенаправлений клиент проходит, как и http.Client по умолчанию
+const maxRedirects = 10
+
+// ErrForbiddenAddress - адрес назначения находится во внутренней сети
+var ErrForbiddenAddress = errors.New("outbound request to a non-public address is forbidden")
+
+// NewClient создаёт HTTP-клиент для запросов на адреса, заданные пользователями.
+// Клиент соединяется только с публичными адресами: проверка выполняется после разрешения
+// имени, поэтому её не обойти DNS-записью на внутренний адрес или перенаправлением.
+// timeout ограничивает запрос целиком, вместе с чтением ответа.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// Прокси из окружения не используется: соединение с ним прошло бы мимо проверки адреса
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// control вызывается для каждого соединения с уже разрешённым адресом
+func control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !domain.IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// checkRedirect сразу отклоняет перенаправления на внутренние IP-адреса и на другие схемы;
+// имена хостов проверит control при соединении
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if err := domain.ValidateWebhookURL(req.URL.String()); err != nil {
+		return fmt.Errorf("%w: redirect to %s", ErrForbiddenAddress, req.URL.Redacted())
+	}
+	return nil
+}
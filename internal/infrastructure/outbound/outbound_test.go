@@ -0,0 +1,56 @@
+package outbound
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRejectsLoopback(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	client := NewClient(time.Second)
+
+	for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := client.Get(target)
+		assert.ErrorIs(t, err, ErrForbiddenAddress, target)
+	}
+	assert.False(t, called)
+}
+
+func TestCheckRedirect(t *testing.T) {
+	tests := []struct {
+		target string
+		ok     bool
+	}{
+		{target: "https://example.com/next", ok: true},
+		{target: "http://10.0.0.1/admin"},
+		{target: "http://169.254.169.254/latest/meta-data"},
+		{target: "file:///etc/passwd"},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, tt.target, nil)
+		require.NoError(t, err)
+
+		err = checkRedirect(req, nil)
+
+		if tt.ok {
+			assert.NoError(t, err, tt.target)
+		} else {
+			assert.ErrorIs(t, err, ErrForbiddenAddress, tt.target)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	assert.Error(t, checkRedirect(req, make([]*http.Request, maxRedirects)))
+}
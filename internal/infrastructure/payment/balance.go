@@ -1,30 +1,39 @@
 package payment
 
 import (
+	"auction/internal/infrastructure/repo"
 	"context"
-	"fmt"
 )
 
 type BalanceService interface {
 	DeductBalance(ctx context.Context, userID int, amount int64) error
 	RefundBalance(ctx context.Context, userID int, amount int64) error
+	PayoutSeller(ctx context.Context, sellerID int, amount int64) error
 }
 
 type balanceService struct {
+	userRepo repo.UserRepository
 }
 
-func NewBalanceService() BalanceService {
-	return &balanceService{}
+func NewBalanceService(userRepo repo.UserRepository) BalanceService {
+	return &balanceService{userRepo: userRepo}
 }
 
+// DeductBalance списывает средства победителя. Вызывается в транзакции расчёта,
+// поэтому списание откатывается вместе с ней.
 func (b *balanceService) DeductBalance(ctx context.Context, userID int, amount int64) error {
-	// Мок-реализация списания средств с пользователя
-	fmt.Printf("Deducting %d from user %d\n", amount, userID)
-	return nil
+	return b.userRepo.Debit(ctx, userID, amount)
 }
 
+// RefundBalance освобождает средства проигравшего. При ставке средства не списываются,
+// а резервируются ставками в нерассчитанных аукционах, поэтому после записи итогов
+// расчёта резерв снимается сам и баланс не меняется.
 func (b *balanceService) RefundBalance(ctx context.Context, userID int, amount int64) error {
-	// Мок-реализация возврата средств пользователю
-	fmt.Printf("Refunding %d to user %d\n", amount, userID)
 	return nil
 }
+
+// PayoutSeller зачисляет продавцу выручку за вычетом комиссии. Вызывается в транзакции
+// расчёта вместе со списанием с победителя.
+func (b *balanceService) PayoutSeller(ctx context.Context, sellerID int, amount int64) error {
+	return b.userRepo.Credit(ctx, sellerID, amount)
+}
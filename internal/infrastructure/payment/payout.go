@@ -0,0 +1,43 @@
+package payment
+
+import (
+	"auction/internal/domain"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// PayoutProvider переводит выведенные средства на реквизиты пользователя. Выплата
+// повторяется с тем же idempotencyKey, если прошлая попытка завершилась ошибкой или
+// зависла, и провайдер не должен переводить деньги по одному ключу дважды.
+type PayoutProvider interface {
+	Payout(ctx context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (reference string, err error)
+}
+
+// LocalPayoutProvider - провайдер для разработки и тестов: деньги никуда не уходят,
+// выплата только записывается в лог
+type LocalPayoutProvider struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	payouts map[string]string
+}
+
+func NewLocalPayoutProvider(logger *slog.Logger) *LocalPayoutProvider {
+	return &LocalPayoutProvider{logger: logger, payouts: make(map[string]string)}
+}
+
+func (p *LocalPayoutProvider) Payout(_ context.Context, withdrawal domain.Withdrawal, idempotencyKey string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if reference, ok := p.payouts[idempotencyKey]; ok {
+		return reference, nil
+	}
+
+	reference := fmt.Sprintf("local-%d", withdrawal.WithdrawalID)
+	p.payouts[idempotencyKey] = reference
+	p.logger.Info("local payout", "withdrawal_id", withdrawal.WithdrawalID, "user_id", withdrawal.UserID,
+		"amount", withdrawal.Amount, "destination", withdrawal.Destination, "reference", reference)
+	return reference, nil
+}
@@ -0,0 +1,185 @@
+package payment
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CallbackPath - адрес, на который провайдеры отправляют уведомления о результате оплаты
+const CallbackPath = "/v1/payments/callback"
+
+// LocalCheckoutPattern - шаблон адреса страницы оплаты локального провайдера для http.ServeMux
+const LocalCheckoutPattern = "POST /v1/payments/local/{intent_id}"
+
+// CallbackTolerance - насколько время подписи уведомления может отличаться от текущего
+const CallbackTolerance = 5 * time.Minute
+
+// PaymentIntent - намерение оплаты, созданное провайдером
+type PaymentIntent struct {
+	ID string
+	// CheckoutURL - страница, на которой пользователь оплачивает платёж
+	CheckoutURL string
+}
+
+// PaymentProvider принимает оплату пополнений баланса. Баланс пополняется не при создании
+// намерения, а по уведомлению провайдера, подпись которого проверяет ParseCallback.
+type PaymentProvider interface {
+	Name() string
+	CreateIntent(ctx context.Context, payment domain.Payment) (PaymentIntent, error)
+	ParseCallback(header http.Header, body []byte) (domain.PaymentCallback, error)
+}
+
+// localCallback - тело уведомления локального провайдера
+type localCallback struct {
+	PaymentID string               `json:"payment_id"`
+	Status    domain.PaymentStatus `json:"status"`
+	Amount    int64                `json:"amount"`
+}
+
+// LocalPaymentProvider имитирует провайдера для разработки и тестов. Страница оплаты
+// (CheckoutHandler) сразу отправляет на CallbackPath уведомление, подписанное как
+// webhooks для партнёров.
+type LocalPaymentProvider struct {
+	secret    string
+	publicURL string
+	client    *http.Client
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu      sync.Mutex
+	intents map[string]domain.Payment
+}
+
+// NewLocalPaymentProvider создаёт провайдера, ссылки которого строятся от publicURL -
+// внешнего адреса REST-шлюза
+func NewLocalPaymentProvider(secret, publicURL string, client *http.Client, logger *slog.Logger) *LocalPaymentProvider {
+	return &LocalPaymentProvider{
+		secret:    secret,
+		publicURL: publicURL,
+		client:    client,
+		logger:    logger,
+		now:       time.Now,
+		intents:   make(map[string]domain.Payment),
+	}
+}
+
+func (p *LocalPaymentProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalPaymentProvider) CreateIntent(_ context.Context, payment domain.Payment) (PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := fmt.Sprintf("local_%d", payment.PaymentID)
+	p.intents[id] = payment
+	return PaymentIntent{ID: id, CheckoutURL: p.publicURL + "/v1/payments/local/" + id}, nil
+}
+
+func (p *LocalPaymentProvider) ParseCallback(header http.Header, body []byte) (domain.PaymentCallback, error) {
+	if err := webhook.Verify(p.secret, header, body, p.now(), CallbackTolerance); err != nil {
+		return domain.PaymentCallback{}, err
+	}
+	var callback localCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return domain.PaymentCallback{}, fmt.Errorf("invalid payment callback: %w", err)
+	}
+	return domain.PaymentCallback{
+		ProviderPaymentID: callback.PaymentID,
+		Status:            callback.Status,
+		Amount:            callback.Amount,
+	}, nil
+}
+
+// SignCallback собирает подписанное уведомление о результате оплаты намерения
+func (p *LocalPaymentProvider) SignCallback(intentID string, status domain.PaymentStatus) (http.Header, []byte, error) {
+	p.mu.Lock()
+	payment, ok := p.intents[intentID]
+	p.mu.Unlock()
+	if !ok {
+		return nil, nil, domain.ErrPaymentNotFound
+	}
+
+	body, err := json.Marshal(localCallback{PaymentID: intentID, Status: status, Amount: payment.Amount})
+	if err != nil {
+		return nil, nil, err
+	}
+	timestamp := p.now().Unix()
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(webhook.HeaderSignature, webhook.Sign(p.secret, timestamp, body))
+	return header, body, nil
+}
+
+// Complete завершает оплату намерения и отправляет уведомление, как настоящий провайдер
+func (p *LocalPaymentProvider) Complete(ctx context.Context, intentID string, status domain.PaymentStatus) error {
+	header, body, err := p.SignCallback(intentID, status)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.publicURL+CallbackPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("payment callback responded with %s", resp.Status)
+	}
+	p.logger.Info("local payment completed", "intent_id", intentID, "status", status)
+	return nil
+}
+
+// CheckoutHandler - страница оплаты по LocalCheckoutPattern: POST оплачивает намерение,
+// а с параметром status=failed - отклоняет. Оплатить намерение может только владелец
+// платежа, поэтому обработчик ждёт пользователя в контексте запроса.
+func (p *LocalPaymentProvider) CheckoutHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+
+		intentID := r.PathValue("intent_id")
+		p.mu.Lock()
+		payment, ok := p.intents[intentID]
+		p.mu.Unlock()
+		// Чужое намерение неотличимо от несуществующего
+		if !ok || payment.UserID != user.ID {
+			http.Error(w, domain.ErrPaymentNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		status := domain.PaymentSucceeded
+		if r.URL.Query().Get("status") == string(domain.PaymentFailed) {
+			status = domain.PaymentFailed
+		}
+
+		if err := p.Complete(r.Context(), intentID, status); err != nil {
+			p.logger.Error("failed to complete local payment", "error", err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "payment %s\n", status)
+	})
+}
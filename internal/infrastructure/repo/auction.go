@@ -3,15 +3,22 @@ package repo
 import (
 	"auction/internal/domain"
 	"context"
+	"errors"
 	"github.com/go-pg/pg/v10"
 	"time"
 )
 
 type AuctionRepository interface {
 	Create(ctx context.Context, auction domain.Auction) (int, error)
+	GetByID(ctx context.Context, auctionID int) (domain.Auction, error)
+	TryGetForUpdate(ctx context.Context, auctionID int) (domain.Auction, error)
+	Cancel(ctx context.Context, auctionID int) error
 	GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]domain.Auction, error)
+	GetUnsettledClosingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error)
 	CloseAuction(ctx context.Context, auctionID, winnerID int) error
 	GetNewAuctions(ctx context.Context) ([]domain.Auction, error)
+	ClaimUnannounced(ctx context.Context, createdBefore time.Time) ([]domain.Auction, error)
+	ClaimEndingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error)
 }
 
 type AuctionRepo struct {
@@ -24,16 +31,68 @@ func NewAuctionRepository(db *pg.DB) *AuctionRepo {
 
 func (r *AuctionRepo) Create(ctx context.Context, auction domain.Auction) (int, error) {
 	dbAuction := NewDatabaseAuction(auction)
-	_, err := r.db.Model(dbAuction).Insert()
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbAuction).Insert()
 	if err != nil {
 		return 0, err
 	}
 	return dbAuction.ID, nil
 }
 
+func (r *AuctionRepo) GetByID(ctx context.Context, auctionID int) (domain.Auction, error) {
+	var dbAuction Auction
+	err := conn(ctx, r.db).ModelContext(ctx, &dbAuction).Where("id = ?", auctionID).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Auction{}, domain.ErrAuctionNotFound
+		}
+		return domain.Auction{}, err
+	}
+	return NewDomainAuction(&dbAuction), nil
+}
+
+// TryGetForUpdate читает аукцион с блокировкой строки до конца транзакции. Если строку уже
+// заблокировал другой обработчик, не ждёт и возвращает ErrAuctionBusy.
+func (r *AuctionRepo) TryGetForUpdate(ctx context.Context, auctionID int) (domain.Auction, error) {
+	var dbAuction Auction
+	err := conn(ctx, r.db).ModelContext(ctx, &dbAuction).Where("id = ?", auctionID).For("UPDATE SKIP LOCKED").Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			// Пропущенная заблокированная строка неотличима от отсутствующей
+			if _, err := r.GetByID(ctx, auctionID); err != nil {
+				return domain.Auction{}, err
+			}
+			return domain.Auction{}, domain.ErrAuctionBusy
+		}
+		return domain.Auction{}, err
+	}
+	return NewDomainAuction(&dbAuction), nil
+}
+
+// Cancel отменяет аукцион, если он ещё не завершён и не отменён
+func (r *AuctionRepo) Cancel(ctx context.Context, auctionID int) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, &Auction{}).
+		Set("cancelled_at = ?", time.Now()).
+		Where("id = ? AND winner_id IS NULL AND cancelled_at IS NULL", auctionID).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrAuctionSettled
+	}
+	return nil
+}
+
 func (r *AuctionRepo) GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]domain.Auction, error) {
+	return r.GetUnsettledClosingBefore(ctx, time.Now())
+}
+
+// GetUnsettledClosingBefore возвращает нерассчитанные и неотменённые аукционы, закрывающиеся не позже until
+func (r *AuctionRepo) GetUnsettledClosingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error) {
 	var dbAuctions []*Auction
-	err := r.db.Model(&dbAuctions).Where("closed_at <= ? AND winner_id IS NULL", time.Now()).Select()
+	err := conn(ctx, r.db).ModelContext(ctx, &dbAuctions).Where("closed_at <= ? AND winner_id IS NULL AND cancelled_at IS NULL", until).
+		Where("NOT EXISTS (SELECT 1 FROM settlement s WHERE s.auction_id = t.id)").
+		Order("closed_at").Select()
 	if err != nil {
 		return nil, err
 	}
@@ -42,13 +101,51 @@ func (r *AuctionRepo) GetCompletedAuctionsWithoutWinner(ctx context.Context) ([]
 }
 
 func (r *AuctionRepo) CloseAuction(ctx context.Context, auctionID, winnerID int) error {
-	_, err := r.db.Model(&Auction{}).Where("id = ?", auctionID).Set("winner_id = ?", winnerID).Set("closed_at = ?", time.Now()).Update()
+	_, err := conn(ctx, r.db).ModelContext(ctx, &Auction{}).Where("id = ?", auctionID).Set("winner_id = ?", winnerID).Set("closed_at = ?", time.Now()).Update()
 	return err
 }
 
+// GetNewAuctions возвращает аукционы, ещё не попавшие в рассылку
 func (r *AuctionRepo) GetNewAuctions(ctx context.Context) ([]domain.Auction, error) {
 	var dbAuctions []*Auction
-	err := r.db.Model(&dbAuctions).Where("created_at > ?", time.Now().Add(-24*time.Hour)).Select() //время установлено для упрощения тестирования
+	err := conn(ctx, r.db).ModelContext(ctx, &dbAuctions).
+		Where("announced_at IS NULL").
+		Order("created_at").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDomainAuctions(dbAuctions), nil
+}
+
+// ClaimUnannounced отмечает объявленными все не объявленные аукционы и возвращает их, если
+// самый старый из них создан не позже createdBefore. Так аукционы, созданные в пределах окна,
+// попадают в одну рассылку. Вызывается в транзакции вместе с записью события рассылки:
+// строки блокируются обновлением, и параллельный вызов те же аукционы не получит.
+func (r *AuctionRepo) ClaimUnannounced(ctx context.Context, createdBefore time.Time) ([]domain.Auction, error) {
+	var dbAuctions []*Auction
+	_, err := conn(ctx, r.db).QueryContext(ctx, &dbAuctions, `
+		UPDATE auction SET announced_at = now()
+		WHERE announced_at IS NULL
+		  AND EXISTS (SELECT 1 FROM auction WHERE announced_at IS NULL AND created_at <= ?)
+		RETURNING *`, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDomainAuctions(dbAuctions), nil
+}
+
+// ClaimEndingBefore отмечает аукционы, которые ещё идут и закроются не позже until, как получившие
+// напоминание о завершении и возвращает их. Каждый аукцион возвращается один раз.
+func (r *AuctionRepo) ClaimEndingBefore(ctx context.Context, until time.Time) ([]domain.Auction, error) {
+	var dbAuctions []*Auction
+	_, err := conn(ctx, r.db).QueryContext(ctx, &dbAuctions, `
+		UPDATE auction SET reminded_at = now()
+		WHERE reminded_at IS NULL AND cancelled_at IS NULL AND winner_id IS NULL
+		  AND closed_at > now() AND closed_at <= ?
+		RETURNING *`, until)
 	if err != nil {
 		return nil, err
 	}
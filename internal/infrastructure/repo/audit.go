@@ -0,0 +1,51 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"github.com/go-pg/pg/v10"
+)
+
+type AuditRepository interface {
+	Append(ctx context.Context, event domain.AuditEvent) error
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+}
+
+type AuditRepo struct {
+	db *pg.DB
+}
+
+func NewAuditRepository(db *pg.DB) *AuditRepo {
+	return &AuditRepo{db: db}
+}
+
+// Append записывает событие в транзакции из контекста, чтобы оно фиксировалось вместе с изменением
+func (r *AuditRepo) Append(ctx context.Context, event domain.AuditEvent) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, NewDatabaseAuditEvent(event)).Insert()
+	return err
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	var dbEvents []*AuditEvent
+	query := conn(ctx, r.db).ModelContext(ctx, &dbEvents).
+		Order("created_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	return NewDomainAuditEvents(dbEvents), nil
+}
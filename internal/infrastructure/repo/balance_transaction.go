@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type TransactionRepository interface {
+	Record(ctx context.Context, transactions ...domain.BalanceTransaction) error
+	List(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error)
+}
+
+type TransactionRepo struct {
+	db *pg.DB
+}
+
+func NewTransactionRepository(db *pg.DB) *TransactionRepo {
+	return &TransactionRepo{db: db}
+}
+
+// Record добавляет движения средств в журнал. Вызывается в транзакции, меняющей баланс,
+// после изменения балансов: по ним считается баланс после каждого движения, а строки
+// пользователей уже заблокированы обновлением.
+func (r *TransactionRepo) Record(ctx context.Context, transactions ...domain.BalanceTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int, len(transactions))
+	for i, transaction := range transactions {
+		userIDs[i] = transaction.UserID
+	}
+	var users []*User
+	err := conn(ctx, r.db).ModelContext(ctx, &users).
+		Column("id", "balance").
+		Where("id IN (?)", pg.In(userIDs)).
+		Select()
+	if err != nil {
+		return err
+	}
+	balances := make(map[int]int64, len(users))
+	for _, user := range users {
+		if user.Balance != nil {
+			balances[user.ID] = *user.Balance
+		}
+	}
+	domain.FillBalanceAfter(transactions, balances)
+
+	dbTransactions := make([]*BalanceTransaction, len(transactions))
+	for i, transaction := range transactions {
+		dbTransactions[i] = NewDatabaseBalanceTransaction(transaction)
+	}
+	_, err = conn(ctx, r.db).ModelContext(ctx, &dbTransactions).Insert()
+	return err
+}
+
+// List возвращает журнал, начиная с последних движений
+func (r *TransactionRepo) List(ctx context.Context, filter domain.BalanceTransactionFilter) ([]domain.BalanceTransaction, error) {
+	var dbTransactions []*BalanceTransaction
+	query := conn(ctx, r.db).ModelContext(ctx, &dbTransactions).
+		Order("id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN (?)", pg.In(filter.Types))
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+	return NewDomainBalanceTransactions(dbTransactions), nil
+}
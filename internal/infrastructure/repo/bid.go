@@ -3,6 +3,7 @@ package repo
 import (
 	"auction/internal/domain"
 	"context"
+	"errors"
 	"github.com/go-pg/pg/v10"
 )
 
@@ -10,6 +11,8 @@ type BidRepository interface {
 	GetBidsByAuctionID(ctx context.Context, auctionID int) ([]domain.Bid, error)
 	GetWinningBid(ctx context.Context, auctionID, winnerID int) (domain.Bid, error)
 	GetUserBid(ctx context.Context, auctionID, userID int) (domain.Bid, error)
+	GetTopBid(ctx context.Context, auctionID int) (*domain.Bid, error)
+	GetBidders(ctx context.Context, auctionID int) ([]int, error)
 }
 
 type bidRepo struct {
@@ -21,19 +24,60 @@ func NewBidRepository(db *pg.DB) BidRepository {
 }
 
 func (r *bidRepo) GetBidsByAuctionID(ctx context.Context, auctionID int) ([]domain.Bid, error) {
-	var bids []domain.Bid
-	err := r.db.Model(&bids).Where("auction_id = ?", auctionID).Select()
-	return bids, err
+	var dbBids []*Bid
+	err := conn(ctx, r.db).ModelContext(ctx, &dbBids).
+		Where("auction_id = ?", auctionID).
+		Order("created_at ASC", "id ASC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return NewDomainBids(dbBids), nil
 }
 
 func (r *bidRepo) GetWinningBid(ctx context.Context, auctionID, winnerID int) (domain.Bid, error) {
-	var bid domain.Bid
-	err := r.db.Model(&bid).Where("auction_id = ? AND user_id = ?", auctionID, winnerID).Select()
-	return bid, err
+	return r.GetUserBid(ctx, auctionID, winnerID)
 }
 
+// GetUserBid возвращает максимальную ставку пользователя в аукционе
 func (r *bidRepo) GetUserBid(ctx context.Context, auctionID, userID int) (domain.Bid, error) {
-	var bid domain.Bid
-	err := r.db.Model(&bid).Where("auction_id = ? AND user_id = ?", auctionID, userID).Select()
-	return bid, err
+	var dbBid Bid
+	err := conn(ctx, r.db).ModelContext(ctx, &dbBid).
+		Where("auction_id = ? AND user_id = ?", auctionID, userID).
+		Order("price DESC", "created_at ASC").
+		First()
+	if err != nil {
+		return domain.Bid{}, err
+	}
+	return NewDomainBid(&dbBid), nil
+}
+
+// GetTopBid возвращает текущую максимальную ставку аукциона или nil, если ставок нет
+func (r *bidRepo) GetTopBid(ctx context.Context, auctionID int) (*domain.Bid, error) {
+	var dbBid Bid
+	err := conn(ctx, r.db).ModelContext(ctx, &dbBid).
+		Where("auction_id = ?", auctionID).
+		Order("price DESC", "created_at ASC").
+		First()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	bid := NewDomainBid(&dbBid)
+	return &bid, nil
+}
+
+// GetBidders возвращает участников аукциона, сделавших хотя бы одну ставку
+func (r *bidRepo) GetBidders(ctx context.Context, auctionID int) ([]int, error) {
+	var userIDs []int
+	err := conn(ctx, r.db).ModelContext(ctx, (*Bid)(nil)).
+		ColumnExpr("DISTINCT user_id").
+		Where("auction_id = ?", auctionID).
+		Select(&userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
 }
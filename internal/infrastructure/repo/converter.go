@@ -4,13 +4,16 @@ import "auction/internal/domain"
 
 func NewDomainAuction(auction *Auction) domain.Auction {
 	return domain.Auction{
-		AuctionID: auction.ID,
-		CreatedAt: auction.CreatedAt,
-		ClosedAt:  auction.ClosedAt,
-		UserID:    auction.UserID,
-		WinnerID:  auction.WinnerID,
-		User:      NewDomainUser(auction.User),
-		Winner:    NewDomainUser(auction.Winner),
+		AuctionID:   auction.ID,
+		CreatedAt:   auction.CreatedAt,
+		ClosedAt:    auction.ClosedAt,
+		UserID:      auction.UserID,
+		WinnerID:    auction.WinnerID,
+		CancelledAt: auction.CancelledAt,
+		AnnouncedAt: auction.AnnouncedAt,
+		RemindedAt:  auction.RemindedAt,
+		User:        NewDomainUser(auction.User),
+		Winner:      NewDomainUser(auction.Winner),
 	}
 }
 
@@ -33,6 +36,14 @@ func NewDomainBid(bid *Bid) domain.Bid {
 	}
 }
 
+func NewDomainBids(dbBids []*Bid) []domain.Bid {
+	bids := make([]domain.Bid, len(dbBids))
+	for i, dbBid := range dbBids {
+		bids[i] = NewDomainBid(dbBid)
+	}
+	return bids
+}
+
 func NewDomainUser(user *User) *domain.User {
 	if user == nil {
 		return nil
@@ -42,7 +53,28 @@ func NewDomainUser(user *User) *domain.User {
 		Name:    user.Name,
 		Email:   user.Email,
 		Balance: user.Balance,
+		Roles:   NewDomainRoles(user.Roles),
+
+		NotificationChannels: NewDomainNotificationChannels(user.NotificationChannels),
+		WebhookURL:           stringValue(user.WebhookURL),
+		Locale:               domain.Locale(user.Locale),
+	}
+}
+
+func NewDomainRoles(roles []string) []domain.Role {
+	domainRoles := make([]domain.Role, len(roles))
+	for i, role := range roles {
+		domainRoles[i] = domain.Role(role)
+	}
+	return domainRoles
+}
+
+func NewDatabaseRoles(roles []domain.Role) []string {
+	dbRoles := make([]string, len(roles))
+	for i, role := range roles {
+		dbRoles[i] = string(role)
 	}
+	return dbRoles
 }
 
 func NewDatabaseBid(bid domain.Bid) *Bid {
@@ -62,7 +94,20 @@ func NewDatabaseUser(user domain.User) *User {
 		Name:    user.Name,
 		Email:   user.Email,
 		Balance: user.Balance,
+		Roles:   NewDatabaseRoles(user.Roles),
+
+		NotificationChannels: NewDatabaseNotificationChannels(user.NotificationChannels),
+		WebhookURL:           stringPtr(user.WebhookURL),
+		Locale:               string(NewDatabaseLocale(user.Locale)),
+	}
+}
+
+// NewDatabaseLocale подставляет язык по умолчанию, если он не задан
+func NewDatabaseLocale(locale domain.Locale) domain.Locale {
+	if locale == "" {
+		return domain.DefaultLocale
 	}
+	return locale
 }
 
 func NewDatabaseLot(lot domain.Lot) *Lot {
@@ -79,10 +124,14 @@ func NewDatabaseLot(lot domain.Lot) *Lot {
 
 func NewDatabaseAuction(auction domain.Auction) *Auction {
 	return &Auction{
-		ID:        auction.AuctionID,
-		CreatedAt: auction.CreatedAt,
-		ClosedAt:  auction.ClosedAt,
-		WinnerID:  auction.WinnerID,
+		ID:          auction.AuctionID,
+		CreatedAt:   auction.CreatedAt,
+		ClosedAt:    auction.ClosedAt,
+		UserID:      auction.UserID,
+		WinnerID:    auction.WinnerID,
+		CancelledAt: auction.CancelledAt,
+		AnnouncedAt: auction.AnnouncedAt,
+		RemindedAt:  auction.RemindedAt,
 	}
 }
 
@@ -105,3 +154,453 @@ func NewDomainLot(dbLot Lot) domain.Lot {
 		AuctionID:  dbLot.AuctionID,
 	}
 }
+
+func NewDomainShillReview(review *ShillReview) domain.ShillReview {
+	return domain.ShillReview{
+		ReviewID:   review.ID,
+		UserID:     review.UserID,
+		SellerID:   review.SellerID,
+		LotID:      review.LotID,
+		Reason:     domain.ShillReason(review.Reason),
+		Details:    review.Details,
+		Status:     domain.ShillReviewStatus(review.Status),
+		CreatedAt:  review.CreatedAt,
+		ResolvedAt: review.ResolvedAt,
+		ResolvedBy: review.ResolvedBy,
+	}
+}
+
+func NewDomainShillReviews(dbReviews []*ShillReview) []domain.ShillReview {
+	reviews := make([]domain.ShillReview, len(dbReviews))
+	for i, dbReview := range dbReviews {
+		reviews[i] = NewDomainShillReview(dbReview)
+	}
+	return reviews
+}
+
+func NewDatabaseShillReview(review domain.ShillReview) *ShillReview {
+	return &ShillReview{
+		ID:         review.ReviewID,
+		UserID:     review.UserID,
+		SellerID:   review.SellerID,
+		LotID:      review.LotID,
+		Reason:     string(review.Reason),
+		Details:    review.Details,
+		Status:     string(review.Status),
+		CreatedAt:  review.CreatedAt,
+		ResolvedAt: review.ResolvedAt,
+		ResolvedBy: review.ResolvedBy,
+	}
+}
+
+func NewDomainAuditEvent(event *AuditEvent) domain.AuditEvent {
+	return domain.AuditEvent{
+		EventID:    event.ID,
+		ActorID:    event.ActorID,
+		Action:     domain.AuditAction(event.Action),
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Before:     event.Before,
+		After:      event.After,
+		RequestID:  stringValue(event.RequestID),
+		ClientIP:   stringValue(event.ClientIP),
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+func NewDomainAuditEvents(dbEvents []*AuditEvent) []domain.AuditEvent {
+	events := make([]domain.AuditEvent, len(dbEvents))
+	for i, dbEvent := range dbEvents {
+		events[i] = NewDomainAuditEvent(dbEvent)
+	}
+	return events
+}
+
+func NewDatabaseAuditEvent(event domain.AuditEvent) *AuditEvent {
+	return &AuditEvent{
+		ID:         event.EventID,
+		ActorID:    event.ActorID,
+		Action:     string(event.Action),
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		Before:     event.Before,
+		After:      event.After,
+		RequestID:  stringPtr(event.RequestID),
+		ClientIP:   stringPtr(event.ClientIP),
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func stringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func NewDomainSettlement(settlement *Settlement) domain.Settlement {
+	return domain.Settlement{
+		AuctionID:    settlement.AuctionID,
+		WinnerID:     settlement.WinnerID,
+		WinningBidID: settlement.WinningBidID,
+		Price:        settlement.Price,
+		Losers:       settlement.Losers,
+		SellerID:     settlement.SellerID,
+		Fee:          settlement.Fee,
+		SettledAt:    settlement.SettledAt,
+	}
+}
+
+func NewDatabaseSettlement(settlement domain.Settlement) *Settlement {
+	losers := settlement.Losers
+	if losers == nil {
+		losers = []int{}
+	}
+	return &Settlement{
+		AuctionID:    settlement.AuctionID,
+		WinnerID:     settlement.WinnerID,
+		WinningBidID: settlement.WinningBidID,
+		Price:        settlement.Price,
+		Losers:       losers,
+		SellerID:     settlement.SellerID,
+		Fee:          settlement.Fee,
+		SettledAt:    settlement.SettledAt,
+	}
+}
+
+func NewDatabaseBalanceTransaction(transaction domain.BalanceTransaction) *BalanceTransaction {
+	return &BalanceTransaction{
+		ID:           transaction.TransactionID,
+		UserID:       transaction.UserID,
+		Type:         string(transaction.Type),
+		Amount:       transaction.Amount,
+		AuctionID:    transaction.AuctionID,
+		WithdrawalID: transaction.WithdrawalID,
+		PaymentID:    transaction.PaymentID,
+		BalanceAfter: transaction.BalanceAfter,
+		CreatedAt:    transaction.CreatedAt,
+	}
+}
+
+func NewDomainBalanceTransaction(transaction *BalanceTransaction) domain.BalanceTransaction {
+	return domain.BalanceTransaction{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		Type:          domain.BalanceTransactionType(transaction.Type),
+		Amount:        transaction.Amount,
+		AuctionID:     transaction.AuctionID,
+		WithdrawalID:  transaction.WithdrawalID,
+		PaymentID:     transaction.PaymentID,
+		BalanceAfter:  transaction.BalanceAfter,
+		CreatedAt:     transaction.CreatedAt,
+	}
+}
+
+func NewDomainBalanceTransactions(transactions []*BalanceTransaction) []domain.BalanceTransaction {
+	result := make([]domain.BalanceTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		result = append(result, NewDomainBalanceTransaction(transaction))
+	}
+	return result
+}
+
+func NewDomainOutboxMessage(message *Outbox) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		ID:            message.ID,
+		Topic:         domain.OutboxTopic(message.Topic),
+		Payload:       message.Payload,
+		Attempts:      message.Attempts,
+		LastError:     stringValue(message.LastError),
+		NextAttemptAt: message.NextAttemptAt,
+		CreatedAt:     message.CreatedAt,
+		DeliveredAt:   message.DeliveredAt,
+		FailedAt:      message.FailedAt,
+	}
+}
+
+func NewDomainOutboxMessages(messages []*Outbox) []domain.OutboxMessage {
+	result := make([]domain.OutboxMessage, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, NewDomainOutboxMessage(message))
+	}
+	return result
+}
+
+func NewDatabaseOutboxMessage(message domain.OutboxMessage) *Outbox {
+	return &Outbox{
+		ID:            message.ID,
+		Topic:         string(message.Topic),
+		Payload:       message.Payload,
+		Attempts:      message.Attempts,
+		LastError:     stringPtr(message.LastError),
+		NextAttemptAt: message.NextAttemptAt,
+		CreatedAt:     message.CreatedAt,
+		DeliveredAt:   message.DeliveredAt,
+		FailedAt:      message.FailedAt,
+	}
+}
+
+func NewDomainNotificationChannels(channels []string) []domain.NotificationChannel {
+	domainChannels := make([]domain.NotificationChannel, len(channels))
+	for i, channel := range channels {
+		domainChannels[i] = domain.NotificationChannel(channel)
+	}
+	return domainChannels
+}
+
+func NewDatabaseNotificationChannels(channels []domain.NotificationChannel) []string {
+	dbChannels := make([]string, len(channels))
+	for i, channel := range channels {
+		dbChannels[i] = string(channel)
+	}
+	return dbChannels
+}
+
+func NewDomainNotification(notification *Notification) domain.Notification {
+	return domain.Notification{
+		NotificationID: notification.ID,
+		UserID:         notification.UserID,
+		Event:          domain.NotificationEvent(stringValue(notification.Event)),
+		Channel:        domain.NotificationChannel(notification.Channel),
+		Subject:        notification.Subject,
+		Body:           notification.Body,
+		Status:         domain.NotificationStatus(notification.Status),
+		Error:          stringValue(notification.Error),
+		CreatedAt:      notification.CreatedAt,
+		ReadAt:         notification.ReadAt,
+	}
+}
+
+func NewDomainNotifications(notifications []*Notification) []domain.Notification {
+	result := make([]domain.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		result = append(result, NewDomainNotification(notification))
+	}
+	return result
+}
+
+func NewDatabaseNotification(notification domain.Notification) *Notification {
+	return &Notification{
+		ID:        notification.NotificationID,
+		UserID:    notification.UserID,
+		Event:     stringPtr(string(notification.Event)),
+		Channel:   string(notification.Channel),
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		Status:    string(notification.Status),
+		Error:     stringPtr(notification.Error),
+		CreatedAt: notification.CreatedAt,
+		ReadAt:    notification.ReadAt,
+	}
+}
+
+func NewDomainNotificationPreferences(preferences *NotificationPreferences) domain.NotificationPreferences {
+	result := domain.NotificationPreferences{
+		UserID:         preferences.UserID,
+		DisabledEvents: make([]domain.NotificationEvent, len(preferences.DisabledEvents)),
+		EventChannels:  make(map[domain.NotificationEvent][]domain.NotificationChannel, len(preferences.EventChannels)),
+	}
+	for i, event := range preferences.DisabledEvents {
+		result.DisabledEvents[i] = domain.NotificationEvent(event)
+	}
+	for event, channels := range preferences.EventChannels {
+		result.EventChannels[domain.NotificationEvent(event)] = NewDomainNotificationChannels(channels)
+	}
+	if preferences.QuietStartMinute != nil && preferences.QuietEndMinute != nil {
+		result.QuietHours = &domain.QuietHours{
+			StartMinute: *preferences.QuietStartMinute,
+			EndMinute:   *preferences.QuietEndMinute,
+			Timezone:    preferences.Timezone,
+		}
+	}
+	return result
+}
+
+func NewDatabaseNotificationPreferences(preferences domain.NotificationPreferences) *NotificationPreferences {
+	result := &NotificationPreferences{
+		UserID:         preferences.UserID,
+		DisabledEvents: make([]string, len(preferences.DisabledEvents)),
+		EventChannels:  make(map[string][]string, len(preferences.EventChannels)),
+		Timezone:       "UTC",
+	}
+	for i, event := range preferences.DisabledEvents {
+		result.DisabledEvents[i] = string(event)
+	}
+	for event, channels := range preferences.EventChannels {
+		result.EventChannels[string(event)] = NewDatabaseNotificationChannels(channels)
+	}
+	if q := preferences.QuietHours; q != nil {
+		result.QuietStartMinute, result.QuietEndMinute = &q.StartMinute, &q.EndMinute
+		if q.Timezone != "" {
+			result.Timezone = q.Timezone
+		}
+	}
+	return result
+}
+
+func NewDomainWebhookSubscription(subscription *WebhookSubscription) domain.WebhookSubscription {
+	events := make([]domain.OutboxTopic, len(subscription.Events))
+	for i, event := range subscription.Events {
+		events[i] = domain.OutboxTopic(event)
+	}
+	return domain.WebhookSubscription{
+		SubscriptionID:      subscription.ID,
+		URL:                 subscription.URL,
+		Events:              events,
+		Secret:              subscription.Secret,
+		Active:              subscription.Active,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		CreatedAt:           subscription.CreatedAt,
+		DisabledAt:          subscription.DisabledAt,
+	}
+}
+
+func NewDomainWebhookSubscriptions(subscriptions []*WebhookSubscription) []domain.WebhookSubscription {
+	result := make([]domain.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, NewDomainWebhookSubscription(subscription))
+	}
+	return result
+}
+
+func NewDatabaseWebhookSubscription(subscription domain.WebhookSubscription) *WebhookSubscription {
+	events := make([]string, len(subscription.Events))
+	for i, event := range subscription.Events {
+		events[i] = string(event)
+	}
+	return &WebhookSubscription{
+		ID:                  subscription.SubscriptionID,
+		URL:                 subscription.URL,
+		Events:              events,
+		Secret:              subscription.Secret,
+		Active:              subscription.Active,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		CreatedAt:           subscription.CreatedAt,
+		DisabledAt:          subscription.DisabledAt,
+	}
+}
+
+func NewDomainWebhookDelivery(delivery *WebhookDelivery) domain.WebhookDelivery {
+	result := domain.WebhookDelivery{
+		DeliveryID:      delivery.ID,
+		SubscriptionID:  delivery.SubscriptionID,
+		OutboxMessageID: delivery.OutboxMessageID,
+		Event:           domain.OutboxTopic(delivery.Event),
+		Payload:         delivery.Payload,
+		Status:          domain.WebhookDeliveryStatus(delivery.Status),
+		Attempts:        delivery.Attempts,
+		LastError:       stringValue(delivery.LastError),
+		NextAttemptAt:   delivery.NextAttemptAt,
+		CreatedAt:       delivery.CreatedAt,
+		DeliveredAt:     delivery.DeliveredAt,
+	}
+	if delivery.ResponseStatus != nil {
+		result.ResponseStatus = *delivery.ResponseStatus
+	}
+	if delivery.Subscription != nil {
+		subscription := NewDomainWebhookSubscription(delivery.Subscription)
+		result.Subscription = &subscription
+	}
+	return result
+}
+
+func NewDomainWebhookDeliveries(deliveries []*WebhookDelivery) []domain.WebhookDelivery {
+	result := make([]domain.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, NewDomainWebhookDelivery(delivery))
+	}
+	return result
+}
+
+func NewDatabaseWebhookDelivery(delivery domain.WebhookDelivery) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:              delivery.DeliveryID,
+		SubscriptionID:  delivery.SubscriptionID,
+		OutboxMessageID: delivery.OutboxMessageID,
+		Event:           string(delivery.Event),
+		Payload:         delivery.Payload,
+		Status:          string(delivery.Status),
+		Attempts:        delivery.Attempts,
+		LastError:       stringPtr(delivery.LastError),
+		NextAttemptAt:   delivery.NextAttemptAt,
+		CreatedAt:       delivery.CreatedAt,
+		DeliveredAt:     delivery.DeliveredAt,
+	}
+}
+
+func NewDatabaseWithdrawal(withdrawal domain.Withdrawal) *Withdrawal {
+	return &Withdrawal{
+		ID:                withdrawal.WithdrawalID,
+		UserID:            withdrawal.UserID,
+		Amount:            withdrawal.Amount,
+		Destination:       withdrawal.Destination,
+		Status:            string(withdrawal.Status),
+		ProviderReference: stringPtr(withdrawal.ProviderReference),
+		LastError:         stringPtr(withdrawal.LastError),
+		CreatedAt:         withdrawal.CreatedAt,
+		ResolvedAt:        withdrawal.ResolvedAt,
+		ResolvedBy:        withdrawal.ResolvedBy,
+		PaidAt:            withdrawal.PaidAt,
+		PayoutStartedAt:   withdrawal.PayoutStartedAt,
+	}
+}
+
+func NewDomainWithdrawal(withdrawal *Withdrawal) domain.Withdrawal {
+	return domain.Withdrawal{
+		WithdrawalID:      withdrawal.ID,
+		UserID:            withdrawal.UserID,
+		Amount:            withdrawal.Amount,
+		Destination:       withdrawal.Destination,
+		Status:            domain.WithdrawalStatus(withdrawal.Status),
+		ProviderReference: stringValue(withdrawal.ProviderReference),
+		LastError:         stringValue(withdrawal.LastError),
+		CreatedAt:         withdrawal.CreatedAt,
+		ResolvedAt:        withdrawal.ResolvedAt,
+		ResolvedBy:        withdrawal.ResolvedBy,
+		PaidAt:            withdrawal.PaidAt,
+		PayoutStartedAt:   withdrawal.PayoutStartedAt,
+	}
+}
+
+func NewDomainWithdrawals(withdrawals []*Withdrawal) []domain.Withdrawal {
+	result := make([]domain.Withdrawal, 0, len(withdrawals))
+	for _, withdrawal := range withdrawals {
+		result = append(result, NewDomainWithdrawal(withdrawal))
+	}
+	return result
+}
+
+func NewDatabasePayment(payment domain.Payment) *Payment {
+	return &Payment{
+		ID:                payment.PaymentID,
+		UserID:            payment.UserID,
+		Amount:            payment.Amount,
+		Provider:          payment.Provider,
+		ProviderPaymentID: stringPtr(payment.ProviderPaymentID),
+		CheckoutURL:       stringPtr(payment.CheckoutURL),
+		Status:            string(payment.Status),
+		CreatedAt:         payment.CreatedAt,
+		ConfirmedAt:       payment.ConfirmedAt,
+	}
+}
+
+func NewDomainPayment(payment *Payment) domain.Payment {
+	return domain.Payment{
+		PaymentID:         payment.ID,
+		UserID:            payment.UserID,
+		Amount:            payment.Amount,
+		Provider:          payment.Provider,
+		ProviderPaymentID: stringValue(payment.ProviderPaymentID),
+		CheckoutURL:       stringValue(payment.CheckoutURL),
+		Status:            domain.PaymentStatus(payment.Status),
+		CreatedAt:         payment.CreatedAt,
+		ConfirmedAt:       payment.ConfirmedAt,
+	}
+}
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// AdvisoryLock - сессионная advisory-блокировка Postgres. Держится на выделенном соединении
+// и снимается сервером сама, если соединение или экземпляр сервиса пропадут.
+type AdvisoryLock struct {
+	db   *pg.DB
+	key  int64
+	mu   sync.Mutex
+	conn *pg.Conn
+}
+
+func NewAdvisoryLock(db *pg.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{db: db, key: key}
+}
+
+// TryAcquire захватывает блокировку без ожидания. Если блокировка уже у этого экземпляра,
+// проверяет, что её соединение живо.
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if _, err := l.conn.ExecContext(ctx, "SELECT 1"); err == nil {
+			return true, nil
+		}
+		// Соединение потеряно вместе с блокировкой
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+
+	conn := l.db.Conn()
+	var acquired bool
+	if _, err := conn.QueryOneContext(ctx, pg.Scan(&acquired), "SELECT pg_try_advisory_lock(?)", l.key); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	if !acquired {
+		_ = conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// terminateTimeout ограничивает завершение сессии, блокировку которой не удалось снять
+const terminateTimeout = 5 * time.Second
+
+// Release снимает блокировку и возвращает соединение в пул. Если снять блокировку не удалось,
+// сессия завершается: сервер снимает блокировку сам, а пул выбрасывает соединение и не отдаст
+// его другому запросу вместе с блокировкой.
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", l.key); err != nil {
+		// ctx мог истечь, поэтому сессия завершается со своим таймаутом
+		terminateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), terminateTimeout)
+		defer cancel()
+		_, _ = conn.ExecContext(terminateCtx, "SELECT pg_terminate_backend(pg_backend_pid())")
+		_ = conn.Close()
+		return err
+	}
+	return conn.Close()
+}
@@ -12,6 +12,7 @@ type LotRepository interface {
 	PlaceBid(ctx context.Context, bid domain.Bid) (int, error)
 	GetUserBids(ctx context.Context, userID int) ([]domain.Bid, error)
 	GetLotByID(ctx context.Context, id int) (domain.Lot, error)
+	GetLotByAuctionID(ctx context.Context, auctionID int) (domain.Lot, error)
 }
 
 type LotRepo struct {
@@ -24,7 +25,7 @@ func NewLotRepository(db *pg.DB) *LotRepo {
 
 func (r *LotRepo) Create(ctx context.Context, lot domain.Lot) (int, error) {
 	dbLot := NewDatabaseLot(lot)
-	_, err := r.db.Model(dbLot).Insert()
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbLot).Insert()
 	if err != nil {
 		return 0, err
 	}
@@ -33,16 +34,21 @@ func (r *LotRepo) Create(ctx context.Context, lot domain.Lot) (int, error) {
 
 func (r *LotRepo) PlaceBid(ctx context.Context, bid domain.Bid) (int, error) {
 	dbBid := NewDatabaseBid(bid)
-	_, err := r.db.Model(dbBid).Insert()
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbBid).Insert()
 	if err != nil {
 		return 0, err
 	}
 	return dbBid.ID, nil
 }
 
+// GetUserBids возвращает ставки пользователя в нерассчитанных аукционах: они резервируют средства
 func (r *LotRepo) GetUserBids(ctx context.Context, userID int) ([]domain.Bid, error) {
 	var dbBids []Bid
-	err := r.db.Model(&dbBids).Where("user_id = ?", userID).Select()
+	err := conn(ctx, r.db).ModelContext(ctx, &dbBids).
+		Where("user_id = ?", userID).
+		Where("auction_id IN (SELECT a.id FROM auction a WHERE a.winner_id IS NULL AND a.cancelled_at IS NULL)").
+		Where("NOT EXISTS (SELECT 1 FROM settlement s WHERE s.auction_id = t.auction_id)").
+		Select()
 	if err != nil {
 		return nil, err
 	}
@@ -56,7 +62,19 @@ func (r *LotRepo) GetUserBids(ctx context.Context, userID int) ([]domain.Bid, er
 
 func (r *LotRepo) GetLotByID(ctx context.Context, id int) (domain.Lot, error) {
 	var dbLot Lot
-	err := r.db.Model(&dbLot).Where("id = ?", id).Select()
+	err := conn(ctx, r.db).ModelContext(ctx, &dbLot).Where("id = ?", id).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Lot{}, domain.ErrLotNotFound
+		}
+		return domain.Lot{}, err
+	}
+	return NewDomainLot(dbLot), nil
+}
+
+func (r *LotRepo) GetLotByAuctionID(ctx context.Context, auctionID int) (domain.Lot, error) {
+	var dbLot Lot
+	err := conn(ctx, r.db).ModelContext(ctx, &dbLot).Where("auction_id = ?", auctionID).First()
 	if err != nil {
 		if errors.Is(err, pg.ErrNoRows) {
 			return domain.Lot{}, domain.ErrLotNotFound
@@ -5,15 +5,31 @@
 package repo
 
 import (
+	"encoding/json"
 	"time"
 )
 
 var Columns = struct {
+	AuditEvent struct {
+		ID, ActorID, Action, EntityType, EntityID, Before, After, RequestID, ClientIP, CreatedAt string
+
+		Actor string
+	}
 	Auction struct {
-		ID, CreatedAt, ClosedAt, UserID, WinnerID string
+		ID, CreatedAt, ClosedAt, UserID, WinnerID, CancelledAt, AnnouncedAt, RemindedAt string
 
 		User, Winner string
 	}
+	AuctionWatch struct {
+		AuctionID, UserID, CreatedAt string
+
+		Auction, User string
+	}
+	BalanceTransaction struct {
+		ID, UserID, Type, Amount, AuctionID, WithdrawalID, PaymentID, BalanceAfter, CreatedAt string
+
+		User, Auction, Withdrawal, Payment string
+	}
 	Bid struct {
 		ID, Price, CreatedAt, UserID, LotID, AuctionID string
 
@@ -24,24 +40,118 @@ var Columns = struct {
 
 		Auction, User string
 	}
+	Notification struct {
+		ID, UserID, Event, Channel, Subject, Body, Status, Error, CreatedAt, ReadAt string
+
+		User string
+	}
+	NotificationPreferences struct {
+		UserID, DisabledEvents, EventChannels, QuietStartMinute, QuietEndMinute, Timezone, UpdatedAt string
+
+		User string
+	}
+	Outbox struct {
+		ID, Topic, Payload, Attempts, LastError, NextAttemptAt, CreatedAt, DeliveredAt, FailedAt string
+	}
+	Payment struct {
+		ID, UserID, Amount, Provider, ProviderPaymentID, CheckoutURL, Status, CreatedAt, ConfirmedAt string
+
+		User string
+	}
+	Settlement struct {
+		AuctionID, WinnerID, WinningBidID, Price, Losers, SellerID, Fee, SettledAt string
+
+		Auction, Winner, WinningBid, Seller string
+	}
+	ShillReview struct {
+		ID, UserID, SellerID, LotID, Reason, Details, Status, CreatedAt, ResolvedAt, ResolvedBy string
+
+		User, Seller, Lot, ResolvedByUser string
+	}
 	User struct {
-		ID, Name, Email, Balance string
+		ID, Name, Email, Balance, Roles, CreatedAt, NotificationChannels, WebhookURL, Locale string
+	}
+	WebhookDelivery struct {
+		ID, SubscriptionID, OutboxMessageID, Event, Payload, Status, Attempts, ResponseStatus, LastError, NextAttemptAt, CreatedAt, DeliveredAt string
+
+		Subscription string
+	}
+	WebhookSubscription struct {
+		ID, URL, Events, Secret, Active, ConsecutiveFailures, CreatedAt, DisabledAt string
+	}
+	Withdrawal struct {
+		ID, UserID, Amount, Destination, Status, ProviderReference, LastError, CreatedAt, ResolvedAt, ResolvedBy, PaidAt string
+
+		User, ResolvedByUser string
 	}
 }{
+	AuditEvent: struct {
+		ID, ActorID, Action, EntityType, EntityID, Before, After, RequestID, ClientIP, CreatedAt string
+
+		Actor string
+	}{
+		ID:         "id",
+		ActorID:    "actor_id",
+		Action:     "action",
+		EntityType: "entity_type",
+		EntityID:   "entity_id",
+		Before:     "before",
+		After:      "after",
+		RequestID:  "request_id",
+		ClientIP:   "client_ip",
+		CreatedAt:  "created_at",
+
+		Actor: "Actor",
+	},
 	Auction: struct {
-		ID, CreatedAt, ClosedAt, UserID, WinnerID string
+		ID, CreatedAt, ClosedAt, UserID, WinnerID, CancelledAt, AnnouncedAt, RemindedAt string
 
 		User, Winner string
 	}{
-		ID:        "id",
-		CreatedAt: "created_at",
-		ClosedAt:  "closed_at",
-		UserID:    "user_id",
-		WinnerID:  "winner_id",
+		ID:          "id",
+		CreatedAt:   "created_at",
+		ClosedAt:    "closed_at",
+		UserID:      "user_id",
+		WinnerID:    "winner_id",
+		CancelledAt: "cancelled_at",
+		AnnouncedAt: "announced_at",
+		RemindedAt:  "reminded_at",
 
 		User:   "User",
 		Winner: "Winner",
 	},
+	AuctionWatch: struct {
+		AuctionID, UserID, CreatedAt string
+
+		Auction, User string
+	}{
+		AuctionID: "auction_id",
+		UserID:    "user_id",
+		CreatedAt: "created_at",
+
+		Auction: "Auction",
+		User:    "User",
+	},
+	BalanceTransaction: struct {
+		ID, UserID, Type, Amount, AuctionID, WithdrawalID, PaymentID, BalanceAfter, CreatedAt string
+
+		User, Auction, Withdrawal, Payment string
+	}{
+		ID:           "id",
+		UserID:       "user_id",
+		Type:         "type",
+		Amount:       "amount",
+		AuctionID:    "auction_id",
+		WithdrawalID: "withdrawal_id",
+		PaymentID:    "payment_id",
+		BalanceAfter: "balance_after",
+		CreatedAt:    "created_at",
+
+		User:       "User",
+		Auction:    "Auction",
+		Withdrawal: "Withdrawal",
+		Payment:    "Payment",
+	},
 	Bid: struct {
 		ID, Price, CreatedAt, UserID, LotID, AuctionID string
 
@@ -74,36 +184,250 @@ var Columns = struct {
 		Auction: "Auction",
 		User:    "User",
 	},
+	Notification: struct {
+		ID, UserID, Event, Channel, Subject, Body, Status, Error, CreatedAt, ReadAt string
+
+		User string
+	}{
+		ID:        "id",
+		UserID:    "user_id",
+		Event:     "event",
+		Channel:   "channel",
+		Subject:   "subject",
+		Body:      "body",
+		Status:    "status",
+		Error:     "error",
+		CreatedAt: "created_at",
+		ReadAt:    "read_at",
+
+		User: "User",
+	},
+	NotificationPreferences: struct {
+		UserID, DisabledEvents, EventChannels, QuietStartMinute, QuietEndMinute, Timezone, UpdatedAt string
+
+		User string
+	}{
+		UserID:           "user_id",
+		DisabledEvents:   "disabled_events",
+		EventChannels:    "event_channels",
+		QuietStartMinute: "quiet_start_minute",
+		QuietEndMinute:   "quiet_end_minute",
+		Timezone:         "timezone",
+		UpdatedAt:        "updated_at",
+
+		User: "User",
+	},
+	Outbox: struct {
+		ID, Topic, Payload, Attempts, LastError, NextAttemptAt, CreatedAt, DeliveredAt, FailedAt string
+	}{
+		ID:            "id",
+		Topic:         "topic",
+		Payload:       "payload",
+		Attempts:      "attempts",
+		LastError:     "last_error",
+		NextAttemptAt: "next_attempt_at",
+		CreatedAt:     "created_at",
+		DeliveredAt:   "delivered_at",
+		FailedAt:      "failed_at",
+	},
+	Payment: struct {
+		ID, UserID, Amount, Provider, ProviderPaymentID, CheckoutURL, Status, CreatedAt, ConfirmedAt string
+
+		User string
+	}{
+		ID:                "id",
+		UserID:            "user_id",
+		Amount:            "amount",
+		Provider:          "provider",
+		ProviderPaymentID: "provider_payment_id",
+		CheckoutURL:       "checkout_url",
+		Status:            "status",
+		CreatedAt:         "created_at",
+		ConfirmedAt:       "confirmed_at",
+
+		User: "User",
+	},
+	Settlement: struct {
+		AuctionID, WinnerID, WinningBidID, Price, Losers, SellerID, Fee, SettledAt string
+
+		Auction, Winner, WinningBid, Seller string
+	}{
+		AuctionID:    "auction_id",
+		WinnerID:     "winner_id",
+		WinningBidID: "winning_bid_id",
+		Price:        "price",
+		Losers:       "losers",
+		SellerID:     "seller_id",
+		Fee:          "fee",
+		SettledAt:    "settled_at",
+
+		Auction:    "Auction",
+		Winner:     "Winner",
+		WinningBid: "WinningBid",
+		Seller:     "Seller",
+	},
+	ShillReview: struct {
+		ID, UserID, SellerID, LotID, Reason, Details, Status, CreatedAt, ResolvedAt, ResolvedBy string
+
+		User, Seller, Lot, ResolvedByUser string
+	}{
+		ID:         "id",
+		UserID:     "user_id",
+		SellerID:   "seller_id",
+		LotID:      "lot_id",
+		Reason:     "reason",
+		Details:    "details",
+		Status:     "status",
+		CreatedAt:  "created_at",
+		ResolvedAt: "resolved_at",
+		ResolvedBy: "resolved_by",
+
+		User:           "User",
+		Seller:         "Seller",
+		Lot:            "Lot",
+		ResolvedByUser: "ResolvedByUser",
+	},
 	User: struct {
-		ID, Name, Email, Balance string
+		ID, Name, Email, Balance, Roles, CreatedAt, NotificationChannels, WebhookURL, Locale string
+	}{
+		ID:                   "id",
+		Name:                 "name",
+		Email:                "email",
+		Balance:              "balance",
+		Roles:                "roles",
+		CreatedAt:            "created_at",
+		NotificationChannels: "notification_channels",
+		WebhookURL:           "webhook_url",
+		Locale:               "locale",
+	},
+	WebhookDelivery: struct {
+		ID, SubscriptionID, OutboxMessageID, Event, Payload, Status, Attempts, ResponseStatus, LastError, NextAttemptAt, CreatedAt, DeliveredAt string
+
+		Subscription string
+	}{
+		ID:              "id",
+		SubscriptionID:  "subscription_id",
+		OutboxMessageID: "outbox_message_id",
+		Event:           "event",
+		Payload:         "payload",
+		Status:          "status",
+		Attempts:        "attempts",
+		ResponseStatus:  "response_status",
+		LastError:       "last_error",
+		NextAttemptAt:   "next_attempt_at",
+		CreatedAt:       "created_at",
+		DeliveredAt:     "delivered_at",
+
+		Subscription: "Subscription",
+	},
+	WebhookSubscription: struct {
+		ID, URL, Events, Secret, Active, ConsecutiveFailures, CreatedAt, DisabledAt string
 	}{
-		ID:      "id",
-		Name:    "name",
-		Email:   "email",
-		Balance: "balance",
+		ID:                  "id",
+		URL:                 "url",
+		Events:              "events",
+		Secret:              "secret",
+		Active:              "active",
+		ConsecutiveFailures: "consecutive_failures",
+		CreatedAt:           "created_at",
+		DisabledAt:          "disabled_at",
+	},
+	Withdrawal: struct {
+		ID, UserID, Amount, Destination, Status, ProviderReference, LastError, CreatedAt, ResolvedAt, ResolvedBy, PaidAt string
+
+		User, ResolvedByUser string
+	}{
+		ID:                "id",
+		UserID:            "user_id",
+		Amount:            "amount",
+		Destination:       "destination",
+		Status:            "status",
+		ProviderReference: "provider_reference",
+		LastError:         "last_error",
+		CreatedAt:         "created_at",
+		ResolvedAt:        "resolved_at",
+		ResolvedBy:        "resolved_by",
+		PaidAt:            "paid_at",
+
+		User:           "User",
+		ResolvedByUser: "ResolvedByUser",
 	},
 }
 
 var Tables = struct {
+	AuditEvent struct {
+		Name, Alias string
+	}
 	Auction struct {
 		Name, Alias string
 	}
+	AuctionWatch struct {
+		Name, Alias string
+	}
+	BalanceTransaction struct {
+		Name, Alias string
+	}
 	Bid struct {
 		Name, Alias string
 	}
 	Lot struct {
 		Name, Alias string
 	}
+	Notification struct {
+		Name, Alias string
+	}
+	NotificationPreferences struct {
+		Name, Alias string
+	}
+	Outbox struct {
+		Name, Alias string
+	}
+	Payment struct {
+		Name, Alias string
+	}
+	Settlement struct {
+		Name, Alias string
+	}
+	ShillReview struct {
+		Name, Alias string
+	}
 	User struct {
 		Name, Alias string
 	}
+	WebhookDelivery struct {
+		Name, Alias string
+	}
+	WebhookSubscription struct {
+		Name, Alias string
+	}
+	Withdrawal struct {
+		Name, Alias string
+	}
 }{
+	AuditEvent: struct {
+		Name, Alias string
+	}{
+		Name:  "audit_event",
+		Alias: "t",
+	},
 	Auction: struct {
 		Name, Alias string
 	}{
 		Name:  "auction",
 		Alias: "t",
 	},
+	AuctionWatch: struct {
+		Name, Alias string
+	}{
+		Name:  "auction_watch",
+		Alias: "t",
+	},
+	BalanceTransaction: struct {
+		Name, Alias string
+	}{
+		Name:  "balance_transaction",
+		Alias: "t",
+	},
 	Bid: struct {
 		Name, Alias string
 	}{
@@ -116,27 +440,131 @@ var Tables = struct {
 		Name:  "lot",
 		Alias: "t",
 	},
+	Notification: struct {
+		Name, Alias string
+	}{
+		Name:  "notification",
+		Alias: "t",
+	},
+	NotificationPreferences: struct {
+		Name, Alias string
+	}{
+		Name:  "notification_preferences",
+		Alias: "t",
+	},
+	Outbox: struct {
+		Name, Alias string
+	}{
+		Name:  "outbox",
+		Alias: "t",
+	},
+	Payment: struct {
+		Name, Alias string
+	}{
+		Name:  "payment",
+		Alias: "t",
+	},
+	Settlement: struct {
+		Name, Alias string
+	}{
+		Name:  "settlement",
+		Alias: "t",
+	},
+	ShillReview: struct {
+		Name, Alias string
+	}{
+		Name:  "shill_review",
+		Alias: "t",
+	},
 	User: struct {
 		Name, Alias string
 	}{
 		Name:  "user",
 		Alias: "t",
 	},
+	WebhookDelivery: struct {
+		Name, Alias string
+	}{
+		Name:  "webhook_delivery",
+		Alias: "t",
+	},
+	WebhookSubscription: struct {
+		Name, Alias string
+	}{
+		Name:  "webhook_subscription",
+		Alias: "t",
+	},
+	Withdrawal: struct {
+		Name, Alias string
+	}{
+		Name:  "withdrawal",
+		Alias: "t",
+	},
+}
+
+type AuditEvent struct {
+	tableName struct{} `pg:"audit_event,alias:t,discard_unknown_columns"`
+
+	ID         int64           `pg:"id,pk"`
+	ActorID    *int            `pg:"actor_id"`
+	Action     string          `pg:"action,use_zero"`
+	EntityType string          `pg:"entity_type,use_zero"`
+	EntityID   int             `pg:"entity_id,use_zero"`
+	Before     json.RawMessage `pg:"before,type:jsonb"`
+	After      json.RawMessage `pg:"after,type:jsonb"`
+	RequestID  *string         `pg:"request_id"`
+	ClientIP   *string         `pg:"client_ip"`
+	CreatedAt  time.Time       `pg:"created_at,use_zero"`
+
+	Actor *User `pg:"fk:actor_id,rel:has-one"`
 }
 
 type Auction struct {
 	tableName struct{} `pg:"auction,alias:t,discard_unknown_columns"`
 
-	ID        int        `pg:"id,pk"`
-	CreatedAt time.Time  `pg:"created_at,use_zero"`
-	ClosedAt  *time.Time `pg:"closed_at"`
-	UserID    *int       `pg:"user_id"`
-	WinnerID  *int       `pg:"winner_id"`
+	ID          int        `pg:"id,pk"`
+	CreatedAt   time.Time  `pg:"created_at,use_zero"`
+	ClosedAt    *time.Time `pg:"closed_at"`
+	UserID      *int       `pg:"user_id"`
+	WinnerID    *int       `pg:"winner_id"`
+	CancelledAt *time.Time `pg:"cancelled_at"`
+	AnnouncedAt *time.Time `pg:"announced_at"`
+	RemindedAt  *time.Time `pg:"reminded_at"`
 
 	User   *User `pg:"fk:user_id,rel:has-one"`
 	Winner *User `pg:"fk:winner_id,rel:has-one"`
 }
 
+type AuctionWatch struct {
+	tableName struct{} `pg:"auction_watch,alias:t,discard_unknown_columns"`
+
+	AuctionID int       `pg:"auction_id,pk"`
+	UserID    int       `pg:"user_id,pk"`
+	CreatedAt time.Time `pg:"created_at,use_zero"`
+
+	Auction *Auction `pg:"fk:auction_id,rel:has-one"`
+	User    *User    `pg:"fk:user_id,rel:has-one"`
+}
+
+type BalanceTransaction struct {
+	tableName struct{} `pg:"balance_transaction,alias:t,discard_unknown_columns"`
+
+	ID           int64     `pg:"id,pk"`
+	UserID       int       `pg:"user_id,use_zero"`
+	Type         string    `pg:"type,use_zero"`
+	Amount       int64     `pg:"amount,use_zero"`
+	AuctionID    *int      `pg:"auction_id"`
+	WithdrawalID *int      `pg:"withdrawal_id"`
+	PaymentID    *int      `pg:"payment_id"`
+	BalanceAfter int64     `pg:"balance_after,use_zero"`
+	CreatedAt    time.Time `pg:"created_at,use_zero"`
+
+	User       *User       `pg:"fk:user_id,rel:has-one"`
+	Auction    *Auction    `pg:"fk:auction_id,rel:has-one"`
+	Withdrawal *Withdrawal `pg:"fk:withdrawal_id,rel:has-one"`
+	Payment    *Payment    `pg:"fk:payment_id,rel:has-one"`
+}
+
 type Bid struct {
 	tableName struct{} `pg:"bid,alias:t,discard_unknown_columns"`
 
@@ -167,11 +595,167 @@ type Lot struct {
 	User    *User    `pg:"fk:user_id,rel:has-one"`
 }
 
+type Notification struct {
+	tableName struct{} `pg:"notification,alias:t,discard_unknown_columns"`
+
+	ID        int64      `pg:"id,pk"`
+	UserID    int        `pg:"user_id,use_zero"`
+	Event     *string    `pg:"event"`
+	Channel   string     `pg:"channel,use_zero"`
+	Subject   string     `pg:"subject,use_zero"`
+	Body      string     `pg:"body,use_zero"`
+	Status    string     `pg:"status,use_zero"`
+	Error     *string    `pg:"error"`
+	CreatedAt time.Time  `pg:"created_at,use_zero"`
+	ReadAt    *time.Time `pg:"read_at"`
+
+	User *User `pg:"fk:user_id,rel:has-one"`
+}
+
+type NotificationPreferences struct {
+	tableName struct{} `pg:"notification_preferences,alias:t,discard_unknown_columns"`
+
+	UserID           int                 `pg:"user_id,pk"`
+	DisabledEvents   []string            `pg:"disabled_events,array,use_zero"`
+	EventChannels    map[string][]string `pg:"event_channels,type:jsonb,use_zero"`
+	QuietStartMinute *int                `pg:"quiet_start_minute"`
+	QuietEndMinute   *int                `pg:"quiet_end_minute"`
+	Timezone         string              `pg:"timezone,use_zero"`
+	UpdatedAt        time.Time           `pg:"updated_at,use_zero"`
+
+	User *User `pg:"fk:user_id,rel:has-one"`
+}
+
+type Outbox struct {
+	tableName struct{} `pg:"outbox,alias:t,discard_unknown_columns"`
+
+	ID            int64           `pg:"id,pk"`
+	Topic         string          `pg:"topic,use_zero"`
+	Payload       json.RawMessage `pg:"payload,type:jsonb,use_zero"`
+	Attempts      int             `pg:"attempts,use_zero"`
+	LastError     *string         `pg:"last_error"`
+	NextAttemptAt time.Time       `pg:"next_attempt_at,use_zero"`
+	CreatedAt     time.Time       `pg:"created_at,use_zero"`
+	DeliveredAt   *time.Time      `pg:"delivered_at"`
+	FailedAt      *time.Time      `pg:"failed_at"`
+}
+
+type Payment struct {
+	tableName struct{} `pg:"payment,alias:t,discard_unknown_columns"`
+
+	ID                int        `pg:"id,pk"`
+	UserID            int        `pg:"user_id,use_zero"`
+	Amount            int64      `pg:"amount,use_zero"`
+	Provider          string     `pg:"provider,use_zero"`
+	ProviderPaymentID *string    `pg:"provider_payment_id"`
+	CheckoutURL       *string    `pg:"checkout_url"`
+	Status            string     `pg:"status,use_zero"`
+	CreatedAt         time.Time  `pg:"created_at,use_zero"`
+	ConfirmedAt       *time.Time `pg:"confirmed_at"`
+
+	User *User `pg:"fk:user_id,rel:has-one"`
+}
+
+type Settlement struct {
+	tableName struct{} `pg:"settlement,alias:t,discard_unknown_columns"`
+
+	AuctionID    int       `pg:"auction_id,pk"`
+	WinnerID     *int      `pg:"winner_id"`
+	WinningBidID *int      `pg:"winning_bid_id"`
+	Price        int64     `pg:"price,use_zero"`
+	Losers       []int     `pg:"losers,array,use_zero"`
+	SellerID     *int      `pg:"seller_id"`
+	Fee          int64     `pg:"fee,use_zero"`
+	SettledAt    time.Time `pg:"settled_at,use_zero"`
+
+	Auction    *Auction `pg:"fk:auction_id,rel:has-one"`
+	Winner     *User    `pg:"fk:winner_id,rel:has-one"`
+	WinningBid *Bid     `pg:"fk:winning_bid_id,rel:has-one"`
+	Seller     *User    `pg:"fk:seller_id,rel:has-one"`
+}
+
+type ShillReview struct {
+	tableName struct{} `pg:"shill_review,alias:t,discard_unknown_columns"`
+
+	ID         int        `pg:"id,pk"`
+	UserID     int        `pg:"user_id,use_zero"`
+	SellerID   *int       `pg:"seller_id"`
+	LotID      *int       `pg:"lot_id"`
+	Reason     string     `pg:"reason,use_zero"`
+	Details    string     `pg:"details,use_zero"`
+	Status     string     `pg:"status,use_zero"`
+	CreatedAt  time.Time  `pg:"created_at,use_zero"`
+	ResolvedAt *time.Time `pg:"resolved_at"`
+	ResolvedBy *int       `pg:"resolved_by"`
+
+	User           *User `pg:"fk:user_id,rel:has-one"`
+	Seller         *User `pg:"fk:seller_id,rel:has-one"`
+	Lot            *Lot  `pg:"fk:lot_id,rel:has-one"`
+	ResolvedByUser *User `pg:"fk:resolved_by,rel:has-one"`
+}
+
 type User struct {
 	tableName struct{} `pg:"user,alias:t,discard_unknown_columns"`
 
-	ID      int    `pg:"id,pk"`
-	Name    string `pg:"name,use_zero"`
-	Email   string `pg:"email,use_zero"`
-	Balance *int64 `pg:"balance"`
+	ID                   int       `pg:"id,pk"`
+	Name                 string    `pg:"name,use_zero"`
+	Email                string    `pg:"email,use_zero"`
+	Balance              *int64    `pg:"balance"`
+	Roles                []string  `pg:"roles,array"`
+	CreatedAt            time.Time `pg:"created_at,use_zero"`
+	NotificationChannels []string  `pg:"notification_channels,array"`
+	WebhookURL           *string   `pg:"webhook_url"`
+	Locale               string    `pg:"locale,use_zero"`
+}
+
+type WebhookDelivery struct {
+	tableName struct{} `pg:"webhook_delivery,alias:t,discard_unknown_columns"`
+
+	ID              int64           `pg:"id,pk"`
+	SubscriptionID  int             `pg:"subscription_id,use_zero"`
+	OutboxMessageID int64           `pg:"outbox_message_id,use_zero"`
+	Event           string          `pg:"event,use_zero"`
+	Payload         json.RawMessage `pg:"payload,type:jsonb,use_zero"`
+	Status          string          `pg:"status,use_zero"`
+	Attempts        int             `pg:"attempts,use_zero"`
+	ResponseStatus  *int            `pg:"response_status"`
+	LastError       *string         `pg:"last_error"`
+	NextAttemptAt   time.Time       `pg:"next_attempt_at,use_zero"`
+	CreatedAt       time.Time       `pg:"created_at,use_zero"`
+	DeliveredAt     *time.Time      `pg:"delivered_at"`
+
+	Subscription *WebhookSubscription `pg:"fk:subscription_id,rel:has-one"`
+}
+
+type WebhookSubscription struct {
+	tableName struct{} `pg:"webhook_subscription,alias:t,discard_unknown_columns"`
+
+	ID                  int        `pg:"id,pk"`
+	URL                 string     `pg:"url,use_zero"`
+	Events              []string   `pg:"events,array,use_zero"`
+	Secret              string     `pg:"secret,use_zero"`
+	Active              bool       `pg:"active,use_zero"`
+	ConsecutiveFailures int        `pg:"consecutive_failures,use_zero"`
+	CreatedAt           time.Time  `pg:"created_at,use_zero"`
+	DisabledAt          *time.Time `pg:"disabled_at"`
+}
+
+type Withdrawal struct {
+	tableName struct{} `pg:"withdrawal,alias:t,discard_unknown_columns"`
+
+	ID                int        `pg:"id,pk"`
+	UserID            int        `pg:"user_id,use_zero"`
+	Amount            int64      `pg:"amount,use_zero"`
+	Destination       string     `pg:"destination,use_zero"`
+	Status            string     `pg:"status,use_zero"`
+	ProviderReference *string    `pg:"provider_reference"`
+	LastError         *string    `pg:"last_error"`
+	CreatedAt         time.Time  `pg:"created_at,use_zero"`
+	ResolvedAt        *time.Time `pg:"resolved_at"`
+	ResolvedBy        *int       `pg:"resolved_by"`
+	PaidAt            *time.Time `pg:"paid_at"`
+	PayoutStartedAt   *time.Time `pg:"payout_started_at"`
+
+	User           *User `pg:"fk:user_id,rel:has-one"`
+	ResolvedByUser *User `pg:"fk:resolved_by,rel:has-one"`
 }
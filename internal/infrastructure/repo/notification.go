@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notifications ...domain.Notification) error
+	ListInbox(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, userID int, notificationID int64) error
+}
+
+type NotificationRepo struct {
+	db *pg.DB
+}
+
+func NewNotificationRepository(db *pg.DB) *NotificationRepo {
+	return &NotificationRepo{db: db}
+}
+
+// Create сохраняет уведомления вместе с итогом доставки по каждому каналу
+func (r *NotificationRepo) Create(ctx context.Context, notifications ...domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dbNotifications := make([]*Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		dbNotification := NewDatabaseNotification(notification)
+		if dbNotification.CreatedAt.IsZero() {
+			dbNotification.CreatedAt = now
+		}
+		dbNotifications = append(dbNotifications, dbNotification)
+	}
+
+	_, err := conn(ctx, r.db).ModelContext(ctx, &dbNotifications).Insert()
+	return err
+}
+
+// ListInbox возвращает ленту уведомлений пользователя в приложении, новые первыми
+func (r *NotificationRepo) ListInbox(ctx context.Context, userID int, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	var dbNotifications []*Notification
+	query := conn(ctx, r.db).ModelContext(ctx, &dbNotifications).
+		Where("user_id = ? AND channel = ?", userID, domain.ChannelInbox).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	return NewDomainNotifications(dbNotifications), nil
+}
+
+// MarkRead отмечает уведомление из ленты прочитанным. Повторная отметка не меняет время прочтения.
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID int, notificationID int64) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, (*Notification)(nil)).
+		Set("read_at = coalesce(read_at, now())").
+		Where("id = ? AND user_id = ? AND channel = ?", notificationID, userID, domain.ChannelInbox).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type NotificationPreferencesRepository interface {
+	// Get возвращает настройки пользователя или пустые настройки, если он их не менял
+	Get(ctx context.Context, userID int) (domain.NotificationPreferences, error)
+	GetForUsers(ctx context.Context, userIDs []int) (map[int]domain.NotificationPreferences, error)
+	Save(ctx context.Context, preferences domain.NotificationPreferences) error
+}
+
+type NotificationPreferencesRepo struct {
+	db *pg.DB
+}
+
+func NewNotificationPreferencesRepository(db *pg.DB) *NotificationPreferencesRepo {
+	return &NotificationPreferencesRepo{db: db}
+}
+
+func (r *NotificationPreferencesRepo) Get(ctx context.Context, userID int) (domain.NotificationPreferences, error) {
+	preferences, err := r.GetForUsers(ctx, []int{userID})
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	if result, ok := preferences[userID]; ok {
+		return result, nil
+	}
+	return domain.NotificationPreferences{UserID: userID}, nil
+}
+
+// GetForUsers возвращает сохранённые настройки. Пользователей без настроек в результате нет.
+func (r *NotificationPreferencesRepo) GetForUsers(ctx context.Context, userIDs []int) (map[int]domain.NotificationPreferences, error) {
+	result := make(map[int]domain.NotificationPreferences, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var dbPreferences []*NotificationPreferences
+	err := conn(ctx, r.db).ModelContext(ctx, &dbPreferences).
+		Where("user_id IN (?)", pg.In(userIDs)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	for _, preferences := range dbPreferences {
+		result[preferences.UserID] = NewDomainNotificationPreferences(preferences)
+	}
+	return result, nil
+}
+
+// Save заменяет настройки пользователя целиком
+func (r *NotificationPreferencesRepo) Save(ctx context.Context, preferences domain.NotificationPreferences) error {
+	dbPreferences := NewDatabaseNotificationPreferences(preferences)
+	dbPreferences.UpdatedAt = time.Now()
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbPreferences).
+		OnConflict("(user_id) DO UPDATE").
+		Set("disabled_events = EXCLUDED.disabled_events").
+		Set("event_channels = EXCLUDED.event_channels").
+		Set("quiet_start_minute = EXCLUDED.quiet_start_minute").
+		Set("quiet_end_minute = EXCLUDED.quiet_end_minute").
+		Set("timezone = EXCLUDED.timezone").
+		Set("updated_at = EXCLUDED.updated_at").
+		Insert()
+	return err
+}
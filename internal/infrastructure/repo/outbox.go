@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, messages ...domain.OutboxMessage) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id int64, lastError string) error
+}
+
+type OutboxRepo struct {
+	db *pg.DB
+}
+
+func NewOutboxRepository(db *pg.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+// Enqueue записывает сообщения в транзакции из контекста, чтобы они фиксировались вместе с изменением.
+// Сообщение без NextAttemptAt готово к доставке сразу.
+func (r *OutboxRepo) Enqueue(ctx context.Context, messages ...domain.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dbMessages := make([]*Outbox, 0, len(messages))
+	for _, message := range messages {
+		dbMessage := NewDatabaseOutboxMessage(message)
+		dbMessage.CreatedAt = now
+		if dbMessage.NextAttemptAt.IsZero() {
+			dbMessage.NextAttemptAt = now
+		}
+		dbMessages = append(dbMessages, dbMessage)
+	}
+
+	_, err := conn(ctx, r.db).ModelContext(ctx, &dbMessages).Insert()
+	return err
+}
+
+// Claim выбирает готовые к доставке сообщения и откладывает их следующую попытку на lease.
+// Параллельные обработчики пропускают заблокированные строки и не получают одни и те же
+// сообщения; если обработчик упал, сообщения вернутся в очередь по истечении lease.
+func (r *OutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	var dbMessages []*Outbox
+	_, err := conn(ctx, r.db).QueryContext(ctx, &dbMessages, `
+		UPDATE outbox SET next_attempt_at = now() + ? * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, lease.Milliseconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDomainOutboxMessages(dbMessages), nil
+}
+
+func (r *OutboxRepo) MarkDelivered(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, (*Outbox)(nil)).
+		Set("delivered_at = now()").
+		Set("attempts = attempts + 1").
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
+// MarkFailed сохраняет ошибку попытки и время следующей
+func (r *OutboxRepo) MarkFailed(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, (*Outbox)(nil)).
+		Set("attempts = attempts + 1").
+		Set("last_error = ?", lastError).
+		Set("next_attempt_at = ?", nextAttemptAt).
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
+// MarkDead снимает сообщение с доставки после исчерпания попыток
+func (r *OutboxRepo) MarkDead(ctx context.Context, id int64, lastError string) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, (*Outbox)(nil)).
+		Set("attempts = attempts + 1").
+		Set("last_error = ?", lastError).
+		Set("failed_at = now()").
+		Where("id = ?", id).
+		Update()
+	return err
+}
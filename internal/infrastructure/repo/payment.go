@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment domain.Payment) (int, error)
+	SetIntent(ctx context.Context, paymentID int, providerPaymentID, checkoutURL string) error
+	Get(ctx context.Context, paymentID int) (domain.Payment, error)
+	GetByProviderIDForUpdate(ctx context.Context, provider, providerPaymentID string) (domain.Payment, error)
+	Confirm(ctx context.Context, paymentID int, status domain.PaymentStatus) error
+}
+
+type PaymentRepo struct {
+	db *pg.DB
+}
+
+func NewPaymentRepository(db *pg.DB) *PaymentRepo {
+	return &PaymentRepo{db: db}
+}
+
+func (r *PaymentRepo) Create(ctx context.Context, payment domain.Payment) (int, error) {
+	dbPayment := NewDatabasePayment(payment)
+	if _, err := conn(ctx, r.db).ModelContext(ctx, dbPayment).Insert(); err != nil {
+		return 0, err
+	}
+	return dbPayment.ID, nil
+}
+
+// SetIntent сохраняет намерение оплаты, созданное провайдером для платежа
+func (r *PaymentRepo) SetIntent(ctx context.Context, paymentID int, providerPaymentID, checkoutURL string) error {
+	return r.update(ctx, paymentID, func(q *pg.Query) *pg.Query {
+		return q.Set("provider_payment_id = ?", providerPaymentID).
+			Set("checkout_url = ?", stringPtr(checkoutURL))
+	})
+}
+
+func (r *PaymentRepo) Get(ctx context.Context, paymentID int) (domain.Payment, error) {
+	var dbPayment Payment
+	err := conn(ctx, r.db).ModelContext(ctx, &dbPayment).Where("id = ?", paymentID).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Payment{}, domain.ErrPaymentNotFound
+		}
+		return domain.Payment{}, err
+	}
+	return NewDomainPayment(&dbPayment), nil
+}
+
+// GetByProviderIDForUpdate блокирует платёж до конца транзакции, чтобы повторное
+// уведомление провайдера не пополнило баланс дважды
+func (r *PaymentRepo) GetByProviderIDForUpdate(ctx context.Context, provider, providerPaymentID string) (domain.Payment, error) {
+	var dbPayment Payment
+	err := conn(ctx, r.db).ModelContext(ctx, &dbPayment).
+		Where("provider = ?", provider).
+		Where("provider_payment_id = ?", providerPaymentID).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Payment{}, domain.ErrPaymentNotFound
+		}
+		return domain.Payment{}, err
+	}
+	return NewDomainPayment(&dbPayment), nil
+}
+
+// Confirm сохраняет окончательный результат оплаты
+func (r *PaymentRepo) Confirm(ctx context.Context, paymentID int, status domain.PaymentStatus) error {
+	return r.update(ctx, paymentID, func(q *pg.Query) *pg.Query {
+		return q.Set("status = ?", status).
+			Set("confirmed_at = ?", time.Now())
+	})
+}
+
+func (r *PaymentRepo) update(ctx context.Context, paymentID int, set func(q *pg.Query) *pg.Query) error {
+	res, err := set(conn(ctx, r.db).ModelContext(ctx, (*Payment)(nil))).
+		Where("id = ?", paymentID).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrPaymentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"github.com/go-pg/pg/v10"
+)
+
+type SettlementRepository interface {
+	Create(ctx context.Context, settlement domain.Settlement) (bool, error)
+	GetByAuctionID(ctx context.Context, auctionID int) (domain.Settlement, error)
+}
+
+type SettlementRepo struct {
+	db *pg.DB
+}
+
+func NewSettlementRepository(db *pg.DB) *SettlementRepo {
+	return &SettlementRepo{db: db}
+}
+
+// Create сохраняет итог расчёта. Возвращает false, если аукцион уже рассчитан.
+func (r *SettlementRepo) Create(ctx context.Context, settlement domain.Settlement) (bool, error) {
+	res, err := conn(ctx, r.db).ModelContext(ctx, NewDatabaseSettlement(settlement)).
+		OnConflict("(auction_id) DO NOTHING").
+		Insert()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func (r *SettlementRepo) GetByAuctionID(ctx context.Context, auctionID int) (domain.Settlement, error) {
+	var dbSettlement Settlement
+	err := conn(ctx, r.db).ModelContext(ctx, &dbSettlement).Where("auction_id = ?", auctionID).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Settlement{}, domain.ErrSettlementNotFound
+		}
+		return domain.Settlement{}, err
+	}
+	return NewDomainSettlement(&dbSettlement), nil
+}
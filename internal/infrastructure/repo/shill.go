@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"github.com/go-pg/pg/v10"
+	"time"
+)
+
+type ShillRepository interface {
+	GetBidderStats(ctx context.Context, userID int) (domain.BidderStats, error)
+	CreateReview(ctx context.Context, review domain.ShillReview) error
+	ListReviews(ctx context.Context, status domain.ShillReviewStatus, limit, offset int) ([]domain.ShillReview, error)
+	ResolveReview(ctx context.Context, reviewID int, status domain.ShillReviewStatus, resolvedBy int) error
+}
+
+type ShillRepo struct {
+	db *pg.DB
+}
+
+func NewShillRepository(db *pg.DB) *ShillRepo {
+	return &ShillRepo{db: db}
+}
+
+func (r *ShillRepo) GetBidderStats(ctx context.Context, userID int) (domain.BidderStats, error) {
+	stats := domain.BidderStats{UserID: userID}
+
+	_, err := conn(ctx, r.db).QueryOneContext(ctx, pg.Scan(&stats.AccountCreatedAt),
+		`SELECT created_at FROM "user" WHERE id = ?`, userID)
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return stats, domain.ErrUserNotFound
+		}
+		return stats, err
+	}
+
+	// Ставка считается "чуть ниже", если позже её перебил другой участник не больше чем на шаг лота
+	_, err = conn(ctx, r.db).QueryOneContext(ctx, pg.Scan(&stats.TotalBids, &stats.JustBelowBids), `
+		SELECT count(*),
+		       count(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM bid o
+		           WHERE o.auction_id = b.auction_id
+		             AND o.user_id <> b.user_id
+		             AND o.created_at > b.created_at
+		             AND o.price > b.price
+		             AND o.price <= b.price + l.step))
+		FROM bid b
+		JOIN lot l ON l.id = b.lot_id
+		WHERE b.user_id = ?`, userID)
+	if err != nil {
+		return stats, err
+	}
+
+	_, err = conn(ctx, r.db).QueryContext(ctx, pg.Scan(&stats.TopSellerID, &stats.TopSellerBids), `
+		SELECT l.user_id, count(*)
+		FROM bid b
+		JOIN lot l ON l.id = b.lot_id
+		WHERE b.user_id = ?
+		GROUP BY l.user_id
+		ORDER BY count(*) DESC
+		LIMIT 1`, userID)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// CreateReview добавляет запись в очередь проверки, если открытой записи с той же причиной ещё нет
+func (r *ShillRepo) CreateReview(ctx context.Context, review domain.ShillReview) error {
+	dbReview := NewDatabaseShillReview(review)
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbReview).
+		OnConflict("(user_id, reason) WHERE status = 'pending' DO NOTHING").
+		Insert()
+	return err
+}
+
+func (r *ShillRepo) ListReviews(ctx context.Context, status domain.ShillReviewStatus, limit, offset int) ([]domain.ShillReview, error) {
+	var dbReviews []*ShillReview
+	query := conn(ctx, r.db).ModelContext(ctx, &dbReviews).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	return NewDomainShillReviews(dbReviews), nil
+}
+
+func (r *ShillRepo) ResolveReview(ctx context.Context, reviewID int, status domain.ShillReviewStatus, resolvedBy int) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, &ShillReview{}).
+		Set("status = ?", status).
+		Set("resolved_at = ?", time.Now()).
+		Set("resolved_by = ?", resolvedBy).
+		Where("id = ? AND status = ?", reviewID, domain.ShillReviewPending).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrShillReviewNotFound
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "auction/internal/infrastructure/repo"
+
+// QueryHook создаёт span на каждый запрос к базе. В span пишется запрос без подставленных
+// параметров, чтобы значения из запросов не попадали в трассировки.
+type QueryHook struct{}
+
+var _ pg.QueryHook = QueryHook{}
+
+type spanKey struct{}
+
+func (QueryHook) BeforeQuery(ctx context.Context, event *pg.QueryEvent) (context.Context, error) {
+	query, err := event.UnformattedQuery()
+	if err != nil {
+		return ctx, nil
+	}
+	statement := string(query)
+	operation := queryOperation(statement)
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+	if event.Stash == nil {
+		event.Stash = make(map[interface{}]interface{})
+	}
+	event.Stash[spanKey{}] = span
+	return ctx, nil
+}
+
+func (QueryHook) AfterQuery(_ context.Context, event *pg.QueryEvent) error {
+	span, ok := event.Stash[spanKey{}].(trace.Span)
+	if !ok {
+		return nil
+	}
+	if event.Err != nil && !errors.Is(event.Err, pg.ErrNoRows) {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	}
+	if event.Result != nil {
+		span.SetAttributes(attribute.Int("db.rows_affected", event.Result.RowsAffected()))
+	}
+	span.End()
+	return nil
+}
+
+// queryOperation возвращает первое слово запроса: SELECT, UPDATE, BEGIN и т.д.
+func queryOperation(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexFunc(query, func(r rune) bool { return r == ' ' || r == '\n' || r == '\t' }); i > 0 {
+		query = query[:i]
+	}
+	return strings.ToUpper(query)
+}
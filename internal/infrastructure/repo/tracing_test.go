@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryHook(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	defer provider.Shutdown(context.Background())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "AuctionService.PlaceBid")
+	hook := QueryHook{}
+	run := func(query string, err error) {
+		event := &pg.QueryEvent{Query: query}
+		queryCtx, herr := hook.BeforeQuery(ctx, event)
+		require.NoError(t, herr)
+		event.Err = err
+		require.NoError(t, hook.AfterQuery(queryCtx, event))
+	}
+
+	run("SELECT * FROM users WHERE id = ?", nil)
+	run("UPDATE users SET balance = balance - ? WHERE id = ?", errors.New("deadlock detected"))
+	run("SELECT * FROM lots WHERE id = ?", pg.ErrNoRows)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+
+	assert.Equal(t, "db.SELECT", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.statement", "SELECT * FROM users WHERE id = ?"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("db.system", "postgresql"))
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	assert.Equal(t, "db.UPDATE", spans[1].Name)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "deadlock detected", spans[1].Status.Description)
+
+	// Пустой результат - не ошибка запроса
+	assert.Equal(t, codes.Unset, spans[2].Status.Code)
+	assert.Equal(t, "AuctionService.PlaceBid", spans[3].Name)
+}
+
+func TestQueryHookWithoutSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	// Запрос, для которого BeforeQuery не создал span, не должен завершать родительский span
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	require.NoError(t, QueryHook{}.AfterQuery(ctx, &pg.QueryEvent{}))
+
+	assert.True(t, parent.IsRecording())
+	assert.Empty(t, exporter.GetSpans())
+}
@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// Transactor выполняет функцию в транзакции. Репозитории, вызванные с контекстом
+// из fn, работают в этой же транзакции.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type TxManager struct {
+	db *pg.DB
+}
+
+func NewTransactor(db *pg.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction открывает транзакцию или присоединяется к уже открытой в контексте
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return fn(ctx)
+	}
+	return m.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn возвращает транзакцию из контекста или подключение к базе
+func conn(ctx context.Context, db *pg.DB) orm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*pg.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -1,15 +1,21 @@
 package repo
 
 import (
+	"auction/internal/domain"
 	"context"
 	"errors"
 	"github.com/go-pg/pg/v10"
 )
 
 type UserRepository interface {
-	RefillBalance(ctx context.Context, userID int, amount int64) error
+	Debit(ctx context.Context, userID int, amount int64) error
+	Credit(ctx context.Context, userID int, amount int64) error
 	GetBalance(ctx context.Context, userID int) (*int64, error)
-	GetAllUsers(ctx context.Context) ([]User, error)
+	GetBalanceForUpdate(ctx context.Context, userID int) (*int64, error)
+	GetUser(ctx context.Context, userID int) (domain.User, error)
+	GetAllUsers(ctx context.Context) ([]domain.User, error)
+	GetRoles(ctx context.Context, userID int) ([]domain.Role, error)
+	UpdateNotificationSettings(ctx context.Context, userID int, settings domain.NotificationSettings) error
 }
 
 type UserRepo struct {
@@ -20,26 +26,39 @@ func NewUserRepository(db *pg.DB) *UserRepo {
 	return &UserRepo{db: db}
 }
 
-func (r *UserRepo) RefillBalance(ctx context.Context, userID int, amount int64) error {
-	user := &User{}
-	err := r.db.Model(user).Where("id = ?", userID).Select()
+// Debit списывает средства, если их достаточно на балансе
+func (r *UserRepo) Debit(ctx context.Context, userID int, amount int64) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, &User{}).
+		Set("balance = balance - ?", amount).
+		Where("id = ? AND balance >= ?", userID, amount).
+		Update()
 	if err != nil {
-		if errors.Is(err, pg.ErrNoRows) {
-			return errors.New("user not found")
-		}
 		return err
 	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrInsufficientFunds
+	}
+	return nil
+}
 
-	_, err = r.db.Model(user).Set("balance = balance + ?", amount).Where("id = ?", userID).Update()
+// Credit зачисляет средства на баланс
+func (r *UserRepo) Credit(ctx context.Context, userID int, amount int64) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, &User{}).
+		Set("balance = balance + ?", amount).
+		Where("id = ?", userID).
+		Update()
 	if err != nil {
 		return err
 	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
 	return nil
 }
 
 func (r *UserRepo) GetBalance(ctx context.Context, userID int) (*int64, error) {
 	var user User
-	err := r.db.Model(&user).
+	err := conn(ctx, r.db).ModelContext(ctx, &user).
 		Column("balance").
 		Where("id = ?", userID).
 		Select()
@@ -49,11 +68,80 @@ func (r *UserRepo) GetBalance(ctx context.Context, userID int) (*int64, error) {
 	return user.Balance, nil
 }
 
-func (r *UserRepo) GetAllUsers(ctx context.Context) ([]User, error) {
-	var users []User
-	err := r.db.Model(&users).Select()
+// GetBalanceForUpdate блокирует пользователя до конца транзакции, чтобы параллельные ставки
+// и заявки на вывод проверяли свободный остаток по очереди
+func (r *UserRepo) GetBalanceForUpdate(ctx context.Context, userID int) (*int64, error) {
+	var user User
+	err := conn(ctx, r.db).ModelContext(ctx, &user).
+		Column("balance").
+		Where("id = ?", userID).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user.Balance, nil
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, userID int) (domain.User, error) {
+	var user User
+	err := conn(ctx, r.db).ModelContext(ctx, &user).Where("id = ?", userID).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.User{}, domain.ErrUserNotFound
+		}
+		return domain.User{}, err
+	}
+	return *NewDomainUser(&user), nil
+}
+
+func (r *UserRepo) GetAllUsers(ctx context.Context) ([]domain.User, error) {
+	var dbUsers []*User
+	err := conn(ctx, r.db).ModelContext(ctx, &dbUsers).Order("id").Select()
 	if err != nil {
 		return nil, err
 	}
+
+	users := make([]domain.User, len(dbUsers))
+	for i, user := range dbUsers {
+		users[i] = *NewDomainUser(user)
+	}
 	return users, nil
 }
+
+func (r *UserRepo) GetRoles(ctx context.Context, userID int) ([]domain.Role, error) {
+	var user User
+	err := conn(ctx, r.db).ModelContext(ctx, &user).
+		Column("roles").
+		Where("id = ?", userID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return NewDomainRoles(user.Roles), nil
+}
+
+// UpdateNotificationSettings сохраняет выбранные пользователем каналы уведомлений и язык.
+// Пустой язык не меняется.
+func (r *UserRepo) UpdateNotificationSettings(ctx context.Context, userID int, settings domain.NotificationSettings) error {
+	query := conn(ctx, r.db).ModelContext(ctx, (*User)(nil)).
+		Set("notification_channels = ?", pg.Array(NewDatabaseNotificationChannels(settings.Channels))).
+		Set("webhook_url = ?", stringPtr(settings.WebhookURL))
+	if settings.Locale != "" {
+		query = query.Set("locale = ?", settings.Locale)
+	}
+	res, err := query.Where("id = ?", userID).Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type WatchRepository interface {
+	Watch(ctx context.Context, auctionID, userID int) error
+	Unwatch(ctx context.Context, auctionID, userID int) error
+	GetWatchers(ctx context.Context, auctionID int) ([]int, error)
+}
+
+type WatchRepo struct {
+	db *pg.DB
+}
+
+func NewWatchRepository(db *pg.DB) *WatchRepo {
+	return &WatchRepo{db: db}
+}
+
+// Watch подписывает пользователя на аукцион. Повторная подписка ничего не меняет.
+func (r *WatchRepo) Watch(ctx context.Context, auctionID, userID int) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, &AuctionWatch{AuctionID: auctionID, UserID: userID, CreatedAt: time.Now()}).
+		OnConflict("DO NOTHING").
+		Insert()
+	return err
+}
+
+func (r *WatchRepo) Unwatch(ctx context.Context, auctionID, userID int) error {
+	_, err := conn(ctx, r.db).ModelContext(ctx, (*AuctionWatch)(nil)).
+		Where("auction_id = ? AND user_id = ?", auctionID, userID).
+		Delete()
+	return err
+}
+
+func (r *WatchRepo) GetWatchers(ctx context.Context, auctionID int) ([]int, error) {
+	var userIDs []int
+	err := conn(ctx, r.db).ModelContext(ctx, (*AuctionWatch)(nil)).
+		Column("user_id").
+		Where("auction_id = ?", auctionID).
+		Select(&userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
@@ -0,0 +1,252 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (int, error)
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+	EnableSubscription(ctx context.Context, id int) error
+	GetActiveSubscriptions(ctx context.Context, event domain.OutboxTopic) ([]domain.WebhookSubscription, error)
+
+	EnqueueDeliveries(ctx context.Context, deliveries ...domain.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, responseStatus int) error
+	MarkFailed(ctx context.Context, id int64, responseStatus int, lastError string, nextAttemptAt *time.Time, disableAfter int) (int, error)
+	ListDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error)
+}
+
+type WebhookRepo struct {
+	db *pg.DB
+}
+
+func NewWebhookRepository(db *pg.DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (r *WebhookRepo) CreateSubscription(ctx context.Context, subscription domain.WebhookSubscription) (int, error) {
+	dbSubscription := NewDatabaseWebhookSubscription(subscription)
+	dbSubscription.Active = true
+	dbSubscription.CreatedAt = time.Now()
+	_, err := conn(ctx, r.db).ModelContext(ctx, dbSubscription).Insert()
+	if err != nil {
+		return 0, err
+	}
+	return dbSubscription.ID, nil
+}
+
+func (r *WebhookRepo) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	var dbSubscriptions []*WebhookSubscription
+	err := conn(ctx, r.db).ModelContext(ctx, &dbSubscriptions).Order("id").Select()
+	if err != nil {
+		return nil, err
+	}
+	return NewDomainWebhookSubscriptions(dbSubscriptions), nil
+}
+
+func (r *WebhookRepo) GetSubscription(ctx context.Context, id int) (domain.WebhookSubscription, error) {
+	var dbSubscription WebhookSubscription
+	err := conn(ctx, r.db).ModelContext(ctx, &dbSubscription).Where("id = ?", id).Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.WebhookSubscription{}, domain.ErrWebhookSubscriptionNotFound
+		}
+		return domain.WebhookSubscription{}, err
+	}
+	return NewDomainWebhookSubscription(&dbSubscription), nil
+}
+
+// DeleteSubscription удаляет подписку вместе с журналом доставок
+func (r *WebhookRepo) DeleteSubscription(ctx context.Context, id int) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, (*WebhookSubscription)(nil)).Where("id = ?", id).Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+// EnableSubscription включает подписку и сбрасывает счётчик неудач. Отложенные доставки
+// продолжат отправляться.
+func (r *WebhookRepo) EnableSubscription(ctx context.Context, id int) error {
+	res, err := conn(ctx, r.db).ModelContext(ctx, (*WebhookSubscription)(nil)).
+		Set("active = true").
+		Set("consecutive_failures = 0").
+		Set("disabled_at = NULL").
+		Where("id = ?", id).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepo) GetActiveSubscriptions(ctx context.Context, event domain.OutboxTopic) ([]domain.WebhookSubscription, error) {
+	var dbSubscriptions []*WebhookSubscription
+	err := conn(ctx, r.db).ModelContext(ctx, &dbSubscriptions).
+		Where("active").
+		Where("? = ANY(events)", string(event)).
+		Order("id").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return NewDomainWebhookSubscriptions(dbSubscriptions), nil
+}
+
+// EnqueueDeliveries создаёт доставки. Доставка того же события outbox той же подписке
+// уже есть, если событие обрабатывается повторно, и второй раз не создаётся.
+func (r *WebhookRepo) EnqueueDeliveries(ctx context.Context, deliveries ...domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	dbDeliveries := make([]*WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		dbDelivery := NewDatabaseWebhookDelivery(delivery)
+		dbDelivery.Status = string(domain.WebhookDeliveryPending)
+		dbDelivery.CreatedAt = now
+		dbDelivery.NextAttemptAt = now
+		dbDeliveries = append(dbDeliveries, dbDelivery)
+	}
+
+	_, err := conn(ctx, r.db).ModelContext(ctx, &dbDeliveries).
+		OnConflict("(subscription_id, outbox_message_id) DO NOTHING").
+		Insert()
+	return err
+}
+
+// ClaimDeliveries выбирает готовые доставки активных подписок и откладывает их следующую
+// попытку на lease, как OutboxRepo.Claim. Подписка доставки заполняется.
+func (r *WebhookRepo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	var dbDeliveries []*WebhookDelivery
+	_, err := conn(ctx, r.db).QueryContext(ctx, &dbDeliveries, `
+		UPDATE webhook_delivery SET next_attempt_at = now() + ? * interval '1 millisecond'
+		WHERE id IN (
+			SELECT d.id FROM webhook_delivery d
+			JOIN webhook_subscription s ON s.id = d.subscription_id
+			WHERE d.status = 'pending' AND s.active AND d.next_attempt_at <= now()
+			ORDER BY d.id
+			LIMIT ?
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING *`, lease.Milliseconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbDeliveries) == 0 {
+		return nil, nil
+	}
+
+	subscriptionIDs := make([]int, 0, len(dbDeliveries))
+	for _, delivery := range dbDeliveries {
+		subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+	}
+	var dbSubscriptions []*WebhookSubscription
+	err = conn(ctx, r.db).ModelContext(ctx, &dbSubscriptions).
+		Where("id IN (?)", pg.In(subscriptionIDs)).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*WebhookSubscription, len(dbSubscriptions))
+	for _, subscription := range dbSubscriptions {
+		byID[subscription.ID] = subscription
+	}
+	for _, delivery := range dbDeliveries {
+		delivery.Subscription = byID[delivery.SubscriptionID]
+	}
+
+	return NewDomainWebhookDeliveries(dbDeliveries), nil
+}
+
+// MarkDelivered отмечает доставку успешной и сбрасывает счётчик неудач подписки
+func (r *WebhookRepo) MarkDelivered(ctx context.Context, id int64, responseStatus int) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `
+		WITH delivery AS (
+			UPDATE webhook_delivery
+			SET status = 'delivered', attempts = attempts + 1, response_status = ?, last_error = NULL, delivered_at = now()
+			WHERE id = ?
+			RETURNING subscription_id
+		)
+		UPDATE webhook_subscription SET consecutive_failures = 0
+		WHERE id IN (SELECT subscription_id FROM delivery)`, responseStatus, id)
+	return err
+}
+
+// MarkFailed сохраняет неудачную попытку. Без nextAttemptAt попытки исчерпаны и доставка
+// становится failed. Подписка отключается, когда неудач подряд становится disableAfter.
+// Возвращает число неудач подряд после этой попытки.
+func (r *WebhookRepo) MarkFailed(
+	ctx context.Context,
+	id int64,
+	responseStatus int,
+	lastError string,
+	nextAttemptAt *time.Time,
+	disableAfter int,
+) (int, error) {
+	var status *int
+	if responseStatus != 0 {
+		status = &responseStatus
+	}
+	var failures int
+	_, err := conn(ctx, r.db).QueryOneContext(ctx, pg.Scan(&failures), `
+		WITH delivery AS (
+			UPDATE webhook_delivery
+			SET attempts = attempts + 1, response_status = ?, last_error = ?,
+				status = CASE WHEN ?::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+				next_attempt_at = COALESCE(?::timestamptz, next_attempt_at)
+			WHERE id = ?
+			RETURNING subscription_id
+		)
+		UPDATE webhook_subscription
+		SET consecutive_failures = consecutive_failures + 1,
+			active = active AND consecutive_failures + 1 < ?,
+			disabled_at = CASE WHEN active AND consecutive_failures + 1 >= ? THEN now() ELSE disabled_at END
+		WHERE id IN (SELECT subscription_id FROM delivery)
+		RETURNING consecutive_failures`,
+		status, lastError, nextAttemptAt, nextAttemptAt, id, disableAfter, disableAfter)
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+// ListDeliveries возвращает журнал доставок подписки, начиная с новых. Для несуществующей
+// подписки возвращается ErrWebhookSubscriptionNotFound, а не пустой журнал.
+func (r *WebhookRepo) ListDeliveries(ctx context.Context, subscriptionID int, limit, offset int) ([]domain.WebhookDelivery, error) {
+	var dbDeliveries []*WebhookDelivery
+	err := conn(ctx, r.db).ModelContext(ctx, &dbDeliveries).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	if len(dbDeliveries) == 0 {
+		exists, err := conn(ctx, r.db).ModelContext(ctx, (*WebhookSubscription)(nil)).Where("id = ?", subscriptionID).Exists()
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, domain.ErrWebhookSubscriptionNotFound
+		}
+	}
+	return NewDomainWebhookDeliveries(dbDeliveries), nil
+}
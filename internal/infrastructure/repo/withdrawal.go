@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type WithdrawalRepository interface {
+	Create(ctx context.Context, withdrawal domain.Withdrawal) (int, error)
+	GetForUpdate(ctx context.Context, withdrawalID int) (domain.Withdrawal, error)
+	Resolve(ctx context.Context, withdrawalID int, status domain.WithdrawalStatus, resolvedBy int) error
+	StartPayout(ctx context.Context, withdrawalID int, from domain.WithdrawalStatus) error
+	MarkPaid(ctx context.Context, withdrawalID int, providerReference string) error
+	MarkPayoutFailed(ctx context.Context, withdrawalID int, lastError string) error
+	List(ctx context.Context, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error)
+}
+
+type WithdrawalRepo struct {
+	db *pg.DB
+}
+
+func NewWithdrawalRepository(db *pg.DB) *WithdrawalRepo {
+	return &WithdrawalRepo{db: db}
+}
+
+func (r *WithdrawalRepo) Create(ctx context.Context, withdrawal domain.Withdrawal) (int, error) {
+	dbWithdrawal := NewDatabaseWithdrawal(withdrawal)
+	if _, err := conn(ctx, r.db).ModelContext(ctx, dbWithdrawal).Insert(); err != nil {
+		return 0, err
+	}
+	return dbWithdrawal.ID, nil
+}
+
+// GetForUpdate блокирует заявку до конца транзакции, чтобы два администратора
+// не приняли по ней разные решения
+func (r *WithdrawalRepo) GetForUpdate(ctx context.Context, withdrawalID int) (domain.Withdrawal, error) {
+	var dbWithdrawal Withdrawal
+	err := conn(ctx, r.db).ModelContext(ctx, &dbWithdrawal).
+		Where("id = ?", withdrawalID).
+		For("UPDATE").
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return domain.Withdrawal{}, domain.ErrWithdrawalNotFound
+		}
+		return domain.Withdrawal{}, err
+	}
+	return NewDomainWithdrawal(&dbWithdrawal), nil
+}
+
+func (r *WithdrawalRepo) Resolve(ctx context.Context, withdrawalID int, status domain.WithdrawalStatus, resolvedBy int) error {
+	return r.update(ctx, withdrawalID, func(q *pg.Query) *pg.Query {
+		return q.Set("status = ?", status).
+			Set("resolved_at = ?", time.Now()).
+			Set("resolved_by = ?", resolvedBy)
+	})
+}
+
+// StartPayout переводит заявку из статуса from в processing перед обращением к провайдеру.
+// from - approved для новой попытки или processing для повтора зависшей выплаты.
+func (r *WithdrawalRepo) StartPayout(ctx context.Context, withdrawalID int, from domain.WithdrawalStatus) error {
+	return r.transition(ctx, withdrawalID, from, func(q *pg.Query) *pg.Query {
+		return q.Set("status = ?", domain.WithdrawalProcessing).
+			Set("payout_started_at = ?", time.Now())
+	})
+}
+
+func (r *WithdrawalRepo) MarkPaid(ctx context.Context, withdrawalID int, providerReference string) error {
+	return r.transition(ctx, withdrawalID, domain.WithdrawalProcessing, func(q *pg.Query) *pg.Query {
+		return q.Set("status = ?", domain.WithdrawalPaid).
+			Set("provider_reference = ?", providerReference).
+			Set("last_error = NULL").
+			Set("paid_at = ?", time.Now())
+	})
+}
+
+// MarkPayoutFailed возвращает заявку в approved с текстом ошибки: выплату можно повторить
+func (r *WithdrawalRepo) MarkPayoutFailed(ctx context.Context, withdrawalID int, lastError string) error {
+	return r.transition(ctx, withdrawalID, domain.WithdrawalProcessing, func(q *pg.Query) *pg.Query {
+		return q.Set("status = ?", domain.WithdrawalApproved).
+			Set("last_error = ?", lastError)
+	})
+}
+
+func (r *WithdrawalRepo) update(ctx context.Context, withdrawalID int, set func(q *pg.Query) *pg.Query) error {
+	res, err := set(conn(ctx, r.db).ModelContext(ctx, (*Withdrawal)(nil))).
+		Where("id = ?", withdrawalID).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrWithdrawalNotFound
+	}
+	return nil
+}
+
+// transition обновляет заявку, только если она в статусе from. Иначе статус уже изменил
+// другой вызов, и возвращается ErrWithdrawalResolved.
+func (r *WithdrawalRepo) transition(ctx context.Context, withdrawalID int, from domain.WithdrawalStatus, set func(q *pg.Query) *pg.Query) error {
+	res, err := set(conn(ctx, r.db).ModelContext(ctx, (*Withdrawal)(nil))).
+		Where("id = ?", withdrawalID).
+		Where("status = ?", from).
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return domain.ErrWithdrawalResolved
+	}
+	return nil
+}
+
+func (r *WithdrawalRepo) List(ctx context.Context, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
+	var dbWithdrawals []*Withdrawal
+	query := conn(ctx, r.db).ModelContext(ctx, &dbWithdrawals).
+		Order("created_at DESC", "id DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+	return NewDomainWithdrawals(dbWithdrawals), nil
+}
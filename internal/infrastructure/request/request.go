@@ -0,0 +1,32 @@
+package request
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Info - сведения о входящем запросе, которые попадают в журнал аудита и логи
+type Info struct {
+	ID       string
+	ClientIP string
+}
+
+type infoKey struct{}
+
+func ContextWithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// InfoFromContext возвращает сведения о запросе или пустую структуру для фоновых операций
+func InfoFromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
+
+// NewID генерирует идентификатор запроса
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
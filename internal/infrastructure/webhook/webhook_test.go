@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"auction/internal/domain"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		Sign("secret", 1700000000, []byte(`{"a":1}`)))
+}
+
+func TestSenderSignsRequest(t *testing.T) {
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	var (
+		header http.Header
+		body   []byte
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	sender := NewSender(receiver.Client())
+	sender.now = func() time.Time { return now }
+	payload := json.RawMessage(`{"event":"bid.placed","data":{"bid_id":7}}`)
+	delivery := domain.WebhookDelivery{
+		DeliveryID:   42,
+		Event:        domain.OutboxBidPlaced,
+		Payload:      payload,
+		Subscription: &domain.WebhookSubscription{URL: receiver.URL, Secret: "partner-secret"},
+	}
+
+	status, err := sender.Send(context.Background(), delivery)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.JSONEq(t, string(payload), string(body))
+	assert.Equal(t, "bid.placed", header.Get(HeaderEvent))
+	assert.Equal(t, "42", header.Get(HeaderDelivery))
+	assert.Equal(t, strconv.FormatInt(now.Unix(), 10), header.Get(HeaderTimestamp))
+	assert.NoError(t, Verify("partner-secret", header, body, now.Add(time.Minute), 5*time.Minute))
+
+	assert.ErrorIs(t, Verify("other-secret", header, body, now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("partner-secret", header, []byte(`{}`), now, 5*time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("partner-secret", header, body, now.Add(10*time.Minute), 5*time.Minute), ErrExpiredTimestamp)
+}
+
+func TestSenderErrors(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+	sender := NewSender(receiver.Client())
+	delivery := domain.WebhookDelivery{Payload: json.RawMessage(`{}`), Subscription: &domain.WebhookSubscription{URL: receiver.URL}}
+
+	status, err := sender.Send(context.Background(), delivery)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.EqualError(t, err, "webhook responded with 503 Service Unavailable")
+
+	_, err = sender.Send(context.Background(), domain.WebhookDelivery{})
+	assert.EqualError(t, err, "delivery has no subscription")
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	require.NoError(t, err)
+	second, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, first, 64)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/logging"
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationHeader = "authorization"
+
+type TokenVerifier interface {
+	Verify(token string) (auth.User, error)
+}
+
+// AuthUnaryInterceptor проверяет bearer-токен и кладёт пользователя в контекст запроса.
+// Методы из publicMethods вызываются без токена.
+func AuthUnaryInterceptor(verifier TokenVerifier, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := methodSet(publicMethods)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, verifier)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamInterceptor(verifier TokenVerifier, publicMethods ...string) grpc.StreamServerInterceptor {
+	public := methodSet(publicMethods)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if public[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), verifier)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, verifier TokenVerifier) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var header string
+	if values := md.Get(authorizationHeader); len(values) > 0 {
+		header = values[0]
+	}
+
+	user, err := verifyBearer(verifier, header)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = logging.With(ctx, "user_id", user.ID)
+	return auth.ContextWithUser(ctx, user), nil
+}
+
+// AuthHTTPHandler проверяет bearer-токен запроса к обработчику, который идёт мимо gRPC,
+// и кладёт пользователя в контекст запроса
+func AuthHTTPHandler(verifier TokenVerifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := verifyBearer(verifier, r.Header.Get(authorizationHeader))
+		if err != nil {
+			http.Error(w, status.Convert(err).Message(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := logging.With(r.Context(), "user_id", user.ID)
+		next.ServeHTTP(w, r.WithContext(auth.ContextWithUser(ctx, user)))
+	})
+}
+
+// verifyBearer проверяет значение заголовка authorization
+func verifyBearer(verifier TokenVerifier, header string) (auth.User, error) {
+	if header == "" {
+		return auth.User{}, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return auth.User{}, status.Error(codes.Unauthenticated, "authorization must use the Bearer scheme")
+	}
+
+	user, err := verifier.Verify(token)
+	if err != nil {
+		return auth.User{}, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return user, nil
+}
+
+// callerID возвращает ID пользователя, от имени которого выполняется запрос
+func callerID(ctx context.Context) (int, error) {
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return 0, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return user.ID, nil
+}
+
+func methodSet(methods []string) map[string]bool {
+	set := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		set[m] = true
+	}
+	return set
+}
+
+// serverStream подменяет контекст потока контекстом с данными аутентификации
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,281 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	v1 "auction/internal/interfaces/rpc/pb"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "auction"
+
+// Причины отказа в доступе, передаваемые в ErrorInfo.Reason
+const (
+	ReasonNoPolicy     = "NO_POLICY"
+	ReasonRoleRequired = "ROLE_REQUIRED"
+	ReasonOwnLot       = "OWN_LOT"
+	ReasonNotOwner     = "NOT_OWNER"
+)
+
+type RoleSource interface {
+	GetRoles(ctx context.Context, userID int) ([]domain.Role, error)
+}
+
+type LotSource interface {
+	GetLotByID(ctx context.Context, id int) (domain.Lot, error)
+	GetLotByAuctionID(ctx context.Context, auctionID int) (domain.Lot, error)
+}
+
+// Policy - правило доступа к RPC-методу
+type Policy struct {
+	// Roles - роли, любой из которых достаточно для вызова. Пустой список - любой пользователь.
+	Roles []domain.Role
+	// Check - дополнительная проверка конкретного запроса, например владения лотом
+	Check func(ctx context.Context, lots LotSource, caller auth.User, req any) error
+}
+
+// AuctionPolicies - таблица политик доступа для методов AuctionService
+func AuctionPolicies() map[string]Policy {
+	return map[string]Policy{
+		v1.AuctionService_CreateLot_FullMethodName: {
+			Roles: []domain.Role{domain.RoleSeller},
+		},
+		v1.AuctionService_PlaceBid_FullMethodName: {
+			Roles: []domain.Role{domain.RoleBidder},
+			Check: checkNotOwnLot,
+		},
+		v1.AuctionService_RefillBalance_FullMethodName: {
+			Check: checkBalanceOwner,
+		},
+		// Платёж проверяет на принадлежность автору запроса обработчик
+		v1.AuctionService_GetPayment_FullMethodName: {},
+		v1.AuctionService_ListBalanceTransactions_FullMethodName: {
+			Check: checkBalanceOwner,
+		},
+		v1.AuctionService_ExportBalanceTransactions_FullMethodName: {
+			Check: checkBalanceOwner,
+		},
+		v1.AuctionService_CancelAuction_FullMethodName: {
+			Roles: []domain.Role{domain.RoleSeller, domain.RoleAdmin},
+			Check: checkAuctionOwner,
+		},
+		// Итог расчёта видят продавец лота и администраторы
+		v1.AuctionService_GetSettlementBreakdown_FullMethodName: {
+			Roles: []domain.Role{domain.RoleSeller, domain.RoleAdmin},
+			Check: checkAuctionOwner,
+		},
+		v1.AuctionService_WatchAuction_FullMethodName:   {},
+		v1.AuctionService_UnwatchAuction_FullMethodName: {},
+		v1.AuctionService_ListShillReviews_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_ResolveShillReview_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_ListAuditEvents_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		// Уведомления всегда относятся к автору запроса
+		v1.AuctionService_ListNotifications_FullMethodName:             {},
+		v1.AuctionService_MarkNotificationRead_FullMethodName:          {},
+		v1.AuctionService_UpdateNotificationChannels_FullMethodName:    {},
+		v1.AuctionService_GetNotificationPreferences_FullMethodName:    {},
+		v1.AuctionService_UpdateNotificationPreferences_FullMethodName: {},
+		v1.AuctionService_CreateWebhookSubscription_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_ListWebhookSubscriptions_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_DeleteWebhookSubscription_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_EnableWebhookSubscription_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_ListWebhookDeliveries_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		// Свои заявки на вывод создаёт и видит любой пользователь, решения принимают администраторы
+		v1.AuctionService_RequestWithdrawal_FullMethodName: {},
+		v1.AuctionService_ListWithdrawals_FullMethodName:   {},
+		v1.AuctionService_ListAllWithdrawals_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+		v1.AuctionService_ResolveWithdrawal_FullMethodName: {
+			Roles: []domain.Role{domain.RoleAdmin},
+		},
+	}
+}
+
+// Authorizer проверяет права аутентифицированного пользователя по таблице политик.
+// Методы без политики запрещены.
+type Authorizer struct {
+	roles    RoleSource
+	lots     LotSource
+	policies map[string]Policy
+	public   map[string]bool
+}
+
+func NewAuthorizer(roles RoleSource, lots LotSource, policies map[string]Policy, publicMethods ...string) *Authorizer {
+	return &Authorizer{
+		roles:    roles,
+		lots:     lots,
+		policies: policies,
+		public:   methodSet(publicMethods),
+	}
+}
+
+func (a *Authorizer) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if a.public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		ctx, err := a.authorize(ctx, info.FullMethod, req)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor проверяет только роли: сообщения потока на момент вызова ещё не получены
+func (a *Authorizer) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if a.public[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		ctx, err := a.authorize(ss.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authorizer) authorize(ctx context.Context, method string, req any) (context.Context, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	policy, ok := a.policies[method]
+	if !ok {
+		return nil, permissionDenied(ReasonNoPolicy, "method is not allowed")
+	}
+
+	roles, err := a.roles.GetRoles(ctx, caller.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "user not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to load user roles: %v", err)
+	}
+	caller.Roles = roles
+
+	if !hasAnyRole(caller, policy.Roles) {
+		return nil, permissionDenied(ReasonRoleRequired, fmt.Sprintf("one of roles %v is required", policy.Roles))
+	}
+
+	if policy.Check != nil && req != nil {
+		if err := policy.Check(ctx, a.lots, caller, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return auth.ContextWithUser(ctx, caller), nil
+}
+
+func hasAnyRole(user auth.User, roles []domain.Role) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if user.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkNotOwnLot(ctx context.Context, lots LotSource, caller auth.User, req any) error {
+	r, ok := req.(*v1.PlaceBidRequest)
+	if !ok {
+		return nil
+	}
+	lotID, err := strconv.Atoi(r.LotId)
+	if err != nil {
+		return nil
+	}
+
+	lot, err := lots.GetLotByID(ctx, lotID)
+	if err != nil {
+		return lookupError(err)
+	}
+	if lot.UserID == caller.ID {
+		return permissionDenied(ReasonOwnLot, "sellers may not bid on their own lots")
+	}
+	return nil
+}
+
+// checkBalanceOwner пропускает запросы к своему балансу: пополнение и журнал движений
+// другого пользователя доступны только администраторам
+func checkBalanceOwner(_ context.Context, _ LotSource, caller auth.User, req any) error {
+	r, ok := req.(interface{ GetUserId() string })
+	if !ok || r.GetUserId() == "" || r.GetUserId() == strconv.Itoa(caller.ID) {
+		return nil
+	}
+	if !caller.HasRole(domain.RoleAdmin) {
+		return permissionDenied(ReasonRoleRequired, "only admins may access another user's balance")
+	}
+	return nil
+}
+
+func checkAuctionOwner(ctx context.Context, lots LotSource, caller auth.User, req any) error {
+	r, ok := req.(interface{ GetAuctionId() string })
+	if !ok || caller.HasRole(domain.RoleAdmin) {
+		return nil
+	}
+	auctionID, err := strconv.Atoi(r.GetAuctionId())
+	if err != nil {
+		return nil
+	}
+
+	lot, err := lots.GetLotByAuctionID(ctx, auctionID)
+	if errors.Is(err, domain.ErrLotNotFound) {
+		// Без лота продавца не проверить, поэтому такой аукцион доступен только администраторам
+		return permissionDenied(ReasonNotOwner, "only admins may manage an auction without a lot")
+	}
+	if err != nil {
+		return lookupError(err)
+	}
+	if lot.UserID != caller.ID {
+		return permissionDenied(ReasonNotOwner, "only admins may manage another seller's auction")
+	}
+	return nil
+}
+
+// lookupError пропускает запрос к несуществующему лоту дальше, чтобы обработчик вернул NotFound
+func lookupError(err error) error {
+	if errors.Is(err, domain.ErrLotNotFound) {
+		return nil
+	}
+	return status.Errorf(codes.Internal, "failed to check lot owner: %v", err)
+}
+
+func permissionDenied(reason, message string) error {
+	st := status.New(codes.PermissionDenied, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
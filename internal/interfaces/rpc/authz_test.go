@@ -0,0 +1,230 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	v1 "auction/internal/interfaces/rpc/pb"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeAuthzStore struct {
+	roles map[int][]domain.Role
+	lots  map[int]domain.Lot
+}
+
+func (f *fakeAuthzStore) GetRoles(_ context.Context, userID int) ([]domain.Role, error) {
+	roles, ok := f.roles[userID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return roles, nil
+}
+
+func (f *fakeAuthzStore) GetLotByID(_ context.Context, id int) (domain.Lot, error) {
+	lot, ok := f.lots[id]
+	if !ok {
+		return domain.Lot{}, domain.ErrLotNotFound
+	}
+	return lot, nil
+}
+
+func (f *fakeAuthzStore) GetLotByAuctionID(_ context.Context, auctionID int) (domain.Lot, error) {
+	for _, lot := range f.lots {
+		if lot.AuctionID == auctionID {
+			return lot, nil
+		}
+	}
+	return domain.Lot{}, domain.ErrLotNotFound
+}
+
+func TestAuthorizer(t *testing.T) {
+	const (
+		admin  = 1
+		seller = 2
+		bidder = 3
+	)
+	store := &fakeAuthzStore{
+		roles: map[int][]domain.Role{
+			admin:  {domain.RoleAdmin},
+			seller: {domain.RoleSeller, domain.RoleBidder},
+			bidder: {domain.RoleBidder},
+		},
+		lots: map[int]domain.Lot{
+			10: {LotID: 10, AuctionID: 100, UserID: seller},
+			20: {LotID: 20, AuctionID: 200, UserID: 4},
+		},
+	}
+	authorizer := NewAuthorizer(store, store, AuctionPolicies())
+	interceptor := authorizer.UnaryInterceptor()
+
+	tests := []struct {
+		name       string
+		userID     int
+		method     string
+		req        any
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name:   "Seller creates lot",
+			userID: seller,
+			method: v1.AuctionService_CreateLot_FullMethodName,
+			req:    &v1.CreateLotRequest{},
+		},
+		{
+			name:       "Bidder cannot create lot",
+			userID:     bidder,
+			method:     v1.AuctionService_CreateLot_FullMethodName,
+			req:        &v1.CreateLotRequest{},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonRoleRequired,
+		},
+		{
+			name:   "Bidder bids on someone else's lot",
+			userID: bidder,
+			method: v1.AuctionService_PlaceBid_FullMethodName,
+			req:    &v1.PlaceBidRequest{LotId: "10"},
+		},
+		{
+			name:       "Seller cannot bid on own lot",
+			userID:     seller,
+			method:     v1.AuctionService_PlaceBid_FullMethodName,
+			req:        &v1.PlaceBidRequest{LotId: "10"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonOwnLot,
+		},
+		{
+			name:   "Unknown lot is left to the handler",
+			userID: bidder,
+			method: v1.AuctionService_PlaceBid_FullMethodName,
+			req:    &v1.PlaceBidRequest{LotId: "404"},
+		},
+		{
+			name:   "User refills own balance",
+			userID: bidder,
+			method: v1.AuctionService_RefillBalance_FullMethodName,
+			req:    &v1.RefillRequest{UserId: "3"},
+		},
+		{
+			name:       "User cannot refill another balance",
+			userID:     bidder,
+			method:     v1.AuctionService_RefillBalance_FullMethodName,
+			req:        &v1.RefillRequest{UserId: "2"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonRoleRequired,
+		},
+		{
+			name:   "Admin refills another balance",
+			userID: admin,
+			method: v1.AuctionService_RefillBalance_FullMethodName,
+			req:    &v1.RefillRequest{UserId: "2"},
+		},
+		{
+			name:       "User cannot read another balance history",
+			userID:     bidder,
+			method:     v1.AuctionService_ExportBalanceTransactions_FullMethodName,
+			req:        &v1.ListBalanceTransactionsRequest{UserId: "2"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonRoleRequired,
+		},
+		{
+			name:   "Admin reads another balance history",
+			userID: admin,
+			method: v1.AuctionService_ListBalanceTransactions_FullMethodName,
+			req:    &v1.ListBalanceTransactionsRequest{UserId: "2"},
+		},
+		{
+			name:   "Seller cancels own auction",
+			userID: seller,
+			method: v1.AuctionService_CancelAuction_FullMethodName,
+			req:    &v1.CancelAuctionRequest{AuctionId: "100"},
+		},
+		{
+			name:   "Admin cancels another seller's auction",
+			userID: admin,
+			method: v1.AuctionService_CancelAuction_FullMethodName,
+			req:    &v1.CancelAuctionRequest{AuctionId: "100"},
+		},
+		{
+			name:       "Bidder cannot cancel auction",
+			userID:     bidder,
+			method:     v1.AuctionService_CancelAuction_FullMethodName,
+			req:        &v1.CancelAuctionRequest{AuctionId: "100"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonRoleRequired,
+		},
+		{
+			name:   "Seller views own settlement",
+			userID: seller,
+			method: v1.AuctionService_GetSettlementBreakdown_FullMethodName,
+			req:    &v1.GetSettlementBreakdownRequest{AuctionId: "100"},
+		},
+		{
+			name:       "Another seller cannot view settlement",
+			userID:     seller,
+			method:     v1.AuctionService_GetSettlementBreakdown_FullMethodName,
+			req:        &v1.GetSettlementBreakdownRequest{AuctionId: "200"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonNotOwner,
+		},
+		{
+			name:       "Seller cannot view settlement of auction without lot",
+			userID:     seller,
+			method:     v1.AuctionService_GetSettlementBreakdown_FullMethodName,
+			req:        &v1.GetSettlementBreakdownRequest{AuctionId: "404"},
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonNotOwner,
+		},
+		{
+			name:   "Admin views settlement of auction without lot",
+			userID: admin,
+			method: v1.AuctionService_GetSettlementBreakdown_FullMethodName,
+			req:    &v1.GetSettlementBreakdownRequest{AuctionId: "404"},
+		},
+		{
+			name:       "Method without policy is denied",
+			userID:     admin,
+			method:     "/auction.v1.AuctionService/Unknown",
+			wantCode:   codes.PermissionDenied,
+			wantReason: ReasonNoPolicy,
+		},
+		{
+			name:     "Unknown user",
+			userID:   99,
+			method:   v1.AuctionService_CreateLot_FullMethodName,
+			req:      &v1.CreateLotRequest{},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := auth.ContextWithUser(context.Background(), auth.User{ID: tt.userID})
+			var called bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				user, _ := auth.UserFromContext(ctx)
+				assert.Equal(t, store.roles[tt.userID], user.Roles)
+				return nil, nil
+			}
+
+			_, err := interceptor(ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCode == codes.OK, called)
+			if tt.wantReason != "" {
+				details := status.Convert(err).Details()
+				if assert.Len(t, details, 1) {
+					assert.Equal(t, tt.wantReason, details[0].(*errdetails.ErrorInfo).Reason)
+				}
+			}
+		})
+	}
+}
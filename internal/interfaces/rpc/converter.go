@@ -3,11 +3,26 @@ package rpc
 import (
 	"auction/internal/domain"
 	v1 "auction/internal/interfaces/rpc/pb"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
 	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
 )
 
-func NewDomainLotFromRequest(req *v1.CreateLotRequest) domain.Lot {
-	userID, _ := strconv.Atoi(req.UserId)
+func NewDomainLotFromRequest(req *v1.CreateLotRequest, userID int) domain.Lot {
 	closedAt := req.ClosingTime.AsTime()
 	return domain.Lot{
 		Title:      req.Title,
@@ -18,8 +33,7 @@ func NewDomainLotFromRequest(req *v1.CreateLotRequest) domain.Lot {
 	}
 }
 
-func NewDomainBidFromRequest(req *v1.PlaceBidRequest) domain.Bid {
-	userID, _ := strconv.Atoi(req.UserId)
+func NewDomainBidFromRequest(req *v1.PlaceBidRequest, userID int) domain.Bid {
 	lotID, _ := strconv.Atoi(req.LotId)
 	return domain.Bid{
 		LotID:  lotID,
@@ -27,3 +41,385 @@ func NewDomainBidFromRequest(req *v1.PlaceBidRequest) domain.Bid {
 		Price:  req.Amount,
 	}
 }
+
+func NewSettlementBreakdownResponse(settlement domain.Settlement) *v1.GetSettlementBreakdownResponse {
+	return &v1.GetSettlementBreakdownResponse{
+		AuctionId: strconv.Itoa(settlement.AuctionID),
+		WinnerId:  optionalID(settlement.WinnerID),
+		SellerId:  optionalID(settlement.SellerID),
+		Gross:     settlement.Price,
+		Fee:       settlement.Fee,
+		Net:       settlement.SellerPayout(),
+		SettledAt: timestamppb.New(settlement.SettledAt),
+	}
+}
+
+func NewShillReviewsResponse(reviews []domain.ShillReview) []*v1.ShillReview {
+	resp := make([]*v1.ShillReview, len(reviews))
+	for i, review := range reviews {
+		resp[i] = &v1.ShillReview{
+			ReviewId:   strconv.Itoa(review.ReviewID),
+			UserId:     strconv.Itoa(review.UserID),
+			SellerId:   optionalID(review.SellerID),
+			LotId:      optionalID(review.LotID),
+			Reason:     string(review.Reason),
+			Details:    review.Details,
+			Status:     string(review.Status),
+			CreatedAt:  timestamppb.New(review.CreatedAt),
+			ResolvedAt: optionalTimestamp(review.ResolvedAt),
+			ResolvedBy: optionalID(review.ResolvedBy),
+		}
+	}
+	return resp
+}
+
+func NewAuditFilterFromRequest(req *v1.ListAuditEventsRequest) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		EntityType: req.EntityType,
+		Limit:      pageLimit(req.Limit),
+		Offset:     int(req.Offset),
+	}
+	if req.EntityId != "" {
+		entityID, err := strconv.Atoi(req.EntityId)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "invalid entity_id")
+		}
+		filter.EntityID = entityID
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		filter.To = &to
+	}
+	return filter, nil
+}
+
+func NewAuditEventsResponse(events []domain.AuditEvent) ([]*v1.AuditEvent, error) {
+	resp := make([]*v1.AuditEvent, len(events))
+	for i, event := range events {
+		before, err := snapshotStruct(event.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := snapshotStruct(event.After)
+		if err != nil {
+			return nil, err
+		}
+
+		resp[i] = &v1.AuditEvent{
+			EventId:    strconv.FormatInt(event.EventID, 10),
+			ActorId:    optionalID(event.ActorID),
+			Action:     string(event.Action),
+			EntityType: event.EntityType,
+			EntityId:   strconv.Itoa(event.EntityID),
+			Before:     before,
+			After:      after,
+			RequestId:  event.RequestID,
+			ClientIp:   event.ClientIP,
+			CreatedAt:  timestamppb.New(event.CreatedAt),
+		}
+	}
+	return resp, nil
+}
+
+func NewNotificationsResponse(notifications []domain.Notification) []*v1.Notification {
+	resp := make([]*v1.Notification, len(notifications))
+	for i, notification := range notifications {
+		resp[i] = &v1.Notification{
+			NotificationId: strconv.FormatInt(notification.NotificationID, 10),
+			Event:          string(notification.Event),
+			Subject:        notification.Subject,
+			Body:           notification.Body,
+			CreatedAt:      timestamppb.New(notification.CreatedAt),
+			ReadAt:         optionalTimestamp(notification.ReadAt),
+		}
+	}
+	return resp
+}
+
+func NewNotificationSettingsFromRequest(req *v1.UpdateNotificationChannelsRequest) domain.NotificationSettings {
+	channels := make([]domain.NotificationChannel, len(req.Channels))
+	for i, channel := range req.Channels {
+		channels[i] = domain.NotificationChannel(channel)
+	}
+	return domain.NotificationSettings{Channels: channels, WebhookURL: req.WebhookUrl, Locale: domain.Locale(req.Locale)}
+}
+
+func NewNotificationPreferencesResponse(preferences domain.NotificationPreferences) *v1.GetNotificationPreferencesResponse {
+	resp := &v1.GetNotificationPreferencesResponse{Events: make([]*v1.EventPreference, len(domain.NotificationEvents))}
+	for i, event := range domain.NotificationEvents {
+		channels := preferences.EventChannels[event]
+		resp.Events[i] = &v1.EventPreference{
+			Event:    string(event),
+			Enabled:  !slices.Contains(preferences.DisabledEvents, event),
+			Channels: channelNames(channels),
+		}
+	}
+	if q := preferences.QuietHours; q != nil {
+		resp.QuietHours = &v1.QuietHours{Start: formatMinute(q.StartMinute), End: formatMinute(q.EndMinute), Timezone: q.Timezone}
+	}
+	return resp
+}
+
+func NewNotificationPreferencesFromRequest(userID int, req *v1.UpdateNotificationPreferencesRequest) (domain.NotificationPreferences, error) {
+	preferences := domain.NotificationPreferences{
+		UserID:        userID,
+		EventChannels: make(map[domain.NotificationEvent][]domain.NotificationChannel),
+	}
+	for _, eventPreference := range req.Events {
+		event := domain.NotificationEvent(eventPreference.Event)
+		if !eventPreference.Enabled {
+			preferences.DisabledEvents = append(preferences.DisabledEvents, event)
+		}
+		if len(eventPreference.Channels) > 0 {
+			channels := make([]domain.NotificationChannel, len(eventPreference.Channels))
+			for i, channel := range eventPreference.Channels {
+				channels[i] = domain.NotificationChannel(channel)
+			}
+			preferences.EventChannels[event] = channels
+		}
+	}
+
+	if q := req.QuietHours; q != nil {
+		start, err := parseMinute(q.Start)
+		if err != nil {
+			return domain.NotificationPreferences{}, err
+		}
+		end, err := parseMinute(q.End)
+		if err != nil {
+			return domain.NotificationPreferences{}, err
+		}
+		preferences.QuietHours = &domain.QuietHours{StartMinute: start, EndMinute: end, Timezone: q.Timezone}
+	}
+	return preferences, nil
+}
+
+func NewWebhookSubscriptionFromRequest(req *v1.CreateWebhookSubscriptionRequest) domain.WebhookSubscription {
+	subscription := domain.WebhookSubscription{URL: req.Url, Secret: req.Secret}
+	for _, event := range req.Events {
+		subscription.Events = append(subscription.Events, domain.OutboxTopic(event))
+	}
+	return subscription
+}
+
+func NewWebhookSubscriptionsResponse(subscriptions []domain.WebhookSubscription) []*v1.WebhookSubscription {
+	resp := make([]*v1.WebhookSubscription, len(subscriptions))
+	for i, subscription := range subscriptions {
+		events := make([]string, len(subscription.Events))
+		for j, event := range subscription.Events {
+			events[j] = string(event)
+		}
+		resp[i] = &v1.WebhookSubscription{
+			SubscriptionId:      strconv.Itoa(subscription.SubscriptionID),
+			Url:                 subscription.URL,
+			Events:              events,
+			Active:              subscription.Active,
+			ConsecutiveFailures: int32(subscription.ConsecutiveFailures),
+			CreatedAt:           timestamppb.New(subscription.CreatedAt),
+			DisabledAt:          optionalTimestamp(subscription.DisabledAt),
+		}
+	}
+	return resp
+}
+
+func NewWebhookDeliveriesResponse(deliveries []domain.WebhookDelivery) []*v1.WebhookDelivery {
+	resp := make([]*v1.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		resp[i] = &v1.WebhookDelivery{
+			DeliveryId:     strconv.FormatInt(delivery.DeliveryID, 10),
+			Event:          string(delivery.Event),
+			Status:         string(delivery.Status),
+			Attempts:       int32(delivery.Attempts),
+			ResponseStatus: int32(delivery.ResponseStatus),
+			LastError:      delivery.LastError,
+			CreatedAt:      timestamppb.New(delivery.CreatedAt),
+			DeliveredAt:    optionalTimestamp(delivery.DeliveredAt),
+		}
+		// Время следующей попытки имеет смысл только для ожидающих доставок
+		if delivery.Status == domain.WebhookDeliveryPending {
+			resp[i].NextAttemptAt = timestamppb.New(delivery.NextAttemptAt)
+		}
+	}
+	return resp
+}
+
+// NewBalanceTransactionFilterFromRequest собирает фильтр журнала пользователя userID.
+// Право смотреть чужой журнал проверено интерцептором авторизации.
+func NewBalanceTransactionFilterFromRequest(req *v1.ListBalanceTransactionsRequest, userID int) (domain.BalanceTransactionFilter, error) {
+	filter := domain.BalanceTransactionFilter{
+		UserID: userID,
+		Limit:  pageLimit(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if req.UserId != "" {
+		var err error
+		filter.UserID, err = strconv.Atoi(req.UserId)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "invalid user_id")
+		}
+	}
+	for _, transactionType := range req.Types {
+		filter.Types = append(filter.Types, domain.BalanceTransactionType(transactionType))
+	}
+	if req.From != nil {
+		from := req.From.AsTime()
+		filter.From = &from
+	}
+	if req.To != nil {
+		to := req.To.AsTime()
+		filter.To = &to
+	}
+	return filter, nil
+}
+
+func NewBalanceTransactionsResponse(transactions []domain.BalanceTransaction) []*v1.BalanceTransaction {
+	resp := make([]*v1.BalanceTransaction, len(transactions))
+	for i, transaction := range transactions {
+		resp[i] = &v1.BalanceTransaction{
+			TransactionId: strconv.FormatInt(transaction.TransactionID, 10),
+			Type:          string(transaction.Type),
+			Amount:        transaction.Amount,
+			BalanceAfter:  transaction.BalanceAfter,
+			AuctionId:     optionalID(transaction.AuctionID),
+			WithdrawalId:  optionalID(transaction.WithdrawalID),
+			CreatedAt:     timestamppb.New(transaction.CreatedAt),
+		}
+	}
+	return resp
+}
+
+// balanceTransactionsCSVHeader - колонки выгрузки журнала для бухгалтерии
+var balanceTransactionsCSVHeader = []string{
+	"transaction_id", "created_at", "type", "amount", "balance_after", "auction_id", "withdrawal_id",
+}
+
+// NewBalanceTransactionsCSV выгружает журнал в CSV. Время в UTC в формате RFC 3339.
+func NewBalanceTransactionsCSV(transactions []domain.BalanceTransaction) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(balanceTransactionsCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		err := w.Write([]string{
+			strconv.FormatInt(transaction.TransactionID, 10),
+			transaction.CreatedAt.UTC().Format(time.RFC3339),
+			string(transaction.Type),
+			strconv.FormatInt(transaction.Amount, 10),
+			strconv.FormatInt(transaction.BalanceAfter, 10),
+			optionalID(transaction.AuctionID),
+			optionalID(transaction.WithdrawalID),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func NewPaymentResponse(payment domain.Payment) *v1.Payment {
+	return &v1.Payment{
+		PaymentId:   strconv.Itoa(payment.PaymentID),
+		Amount:      payment.Amount,
+		Status:      string(payment.Status),
+		CheckoutUrl: payment.CheckoutURL,
+		CreatedAt:   timestamppb.New(payment.CreatedAt),
+		ConfirmedAt: optionalTimestamp(payment.ConfirmedAt),
+	}
+}
+
+func NewAllWithdrawalsFilterFromRequest(req *v1.ListAllWithdrawalsRequest) (domain.WithdrawalFilter, error) {
+	filter := domain.WithdrawalFilter{
+		Status: domain.WithdrawalStatus(req.Status),
+		Limit:  pageLimit(req.Limit),
+		Offset: int(req.Offset),
+	}
+	if req.UserId != "" {
+		userID, err := strconv.Atoi(req.UserId)
+		if err != nil {
+			return filter, status.Error(codes.InvalidArgument, "invalid user_id")
+		}
+		filter.UserID = userID
+	}
+	return filter, nil
+}
+
+func NewWithdrawalResponse(withdrawal domain.Withdrawal) *v1.Withdrawal {
+	return &v1.Withdrawal{
+		WithdrawalId:      strconv.Itoa(withdrawal.WithdrawalID),
+		UserId:            strconv.Itoa(withdrawal.UserID),
+		Amount:            withdrawal.Amount,
+		Destination:       withdrawal.Destination,
+		Status:            string(withdrawal.Status),
+		ProviderReference: withdrawal.ProviderReference,
+		LastError:         withdrawal.LastError,
+		CreatedAt:         timestamppb.New(withdrawal.CreatedAt),
+		ResolvedAt:        optionalTimestamp(withdrawal.ResolvedAt),
+		PaidAt:            optionalTimestamp(withdrawal.PaidAt),
+	}
+}
+
+func NewWithdrawalsResponse(withdrawals []domain.Withdrawal) []*v1.Withdrawal {
+	resp := make([]*v1.Withdrawal, len(withdrawals))
+	for i, withdrawal := range withdrawals {
+		resp[i] = NewWithdrawalResponse(withdrawal)
+	}
+	return resp
+}
+
+func channelNames(channels []domain.NotificationChannel) []string {
+	result := make([]string, len(channels))
+	for i, channel := range channels {
+		result[i] = string(channel)
+	}
+	return result
+}
+
+// parseMinute разбирает время суток ЧЧ:ММ в минуты от полуночи
+func parseMinute(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, domain.ErrInvalidQuietHours
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func snapshotStruct(raw json.RawMessage) (*structpb.Struct, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(fields)
+}
+
+func optionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func pageLimit(limit int32) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	return min(int(limit), maxPageLimit)
+}
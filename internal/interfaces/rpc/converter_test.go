@@ -0,0 +1,67 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	v1 "auction/internal/interfaces/rpc/pb"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPreferencesConversion(t *testing.T) {
+	req := &v1.UpdateNotificationPreferencesRequest{
+		Events: []*v1.EventPreference{
+			{Event: "new_auctions", Enabled: false},
+			{Event: "outbid", Enabled: true, Channels: []string{"webhook"}},
+		},
+		QuietHours: &v1.QuietHours{Start: "22:30", End: "07:00", Timezone: "Europe/Moscow"},
+	}
+
+	preferences, err := NewNotificationPreferencesFromRequest(7, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotificationPreferences{
+		UserID:         7,
+		DisabledEvents: []domain.NotificationEvent{domain.EventNewAuctions},
+		EventChannels:  map[domain.NotificationEvent][]domain.NotificationChannel{domain.EventOutbid: {domain.ChannelWebhook}},
+		QuietHours:     &domain.QuietHours{StartMinute: 22*60 + 30, EndMinute: 7 * 60, Timezone: "Europe/Moscow"},
+	}, preferences)
+
+	resp := NewNotificationPreferencesResponse(preferences)
+	require.Len(t, resp.Events, len(domain.NotificationEvents))
+	for _, event := range resp.Events {
+		switch event.Event {
+		case "new_auctions":
+			assert.False(t, event.Enabled)
+		case "outbid":
+			assert.True(t, event.Enabled)
+			assert.Equal(t, []string{"webhook"}, event.Channels)
+		default:
+			assert.True(t, event.Enabled, event.Event)
+			assert.Empty(t, event.Channels, event.Event)
+		}
+	}
+	assert.Equal(t, "22:30", resp.QuietHours.Start)
+	assert.Equal(t, "07:00", resp.QuietHours.End)
+
+	req.QuietHours.End = "7 утра"
+	_, err = NewNotificationPreferencesFromRequest(7, req)
+	assert.ErrorIs(t, err, domain.ErrInvalidQuietHours)
+}
+
+func TestBalanceTransactionsCSV(t *testing.T) {
+	auctionID := 7
+	createdAt := time.Date(2024, 10, 1, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	data, err := NewBalanceTransactionsCSV([]domain.BalanceTransaction{
+		{TransactionID: 2, Type: domain.TransactionCharge, Amount: -150, BalanceAfter: 850, AuctionID: &auctionID, CreatedAt: createdAt},
+		{TransactionID: 1, Type: domain.TransactionRefill, Amount: 1000, BalanceAfter: 1000, CreatedAt: createdAt},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "transaction_id,created_at,type,amount,balance_after,auction_id,withdrawal_id\n"+
+		"2,2024-10-01T09:30:00Z,charge,-150,850,7,\n"+
+		"1,2024-10-01T09:30:00Z,refill,1000,1000,,\n", string(data))
+}
@@ -0,0 +1,79 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// domainCodes - коды gRPC для ошибок предметной области. REST-шлюз переводит их
+// в HTTP-статусы: NotFound - 404, InvalidArgument - 400, FailedPrecondition - 400, Aborted - 409.
+var domainCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{domain.ErrLotNotFound, codes.NotFound},
+	{domain.ErrUserNotFound, codes.NotFound},
+	{domain.ErrAuctionNotFound, codes.NotFound},
+	{domain.ErrSettlementNotFound, codes.NotFound},
+	{domain.ErrShillReviewNotFound, codes.NotFound},
+	{domain.ErrNotificationNotFound, codes.NotFound},
+	{domain.ErrWithdrawalNotFound, codes.NotFound},
+	{domain.ErrPaymentNotFound, codes.NotFound},
+	{domain.ErrWebhookSubscriptionNotFound, codes.NotFound},
+
+	{domain.ErrInvalidLotData, codes.InvalidArgument},
+	{domain.ErrInvalidBidAmount, codes.InvalidArgument},
+	{domain.ErrInvalidShillResolution, codes.InvalidArgument},
+	{domain.ErrInvalidNotificationChannel, codes.InvalidArgument},
+	{domain.ErrInvalidWebhookURL, codes.InvalidArgument},
+	{domain.ErrPrivateWebhookURL, codes.InvalidArgument},
+	{domain.ErrInvalidLocale, codes.InvalidArgument},
+	{domain.ErrInvalidNotificationEvent, codes.InvalidArgument},
+	{domain.ErrInvalidQuietHours, codes.InvalidArgument},
+	{domain.ErrInvalidWithdrawalAmount, codes.InvalidArgument},
+	{domain.ErrInvalidWithdrawalDestination, codes.InvalidArgument},
+	{domain.ErrInvalidWithdrawalResolution, codes.InvalidArgument},
+	{domain.ErrInvalidTransactionType, codes.InvalidArgument},
+	{domain.ErrInvalidPaymentAmount, codes.InvalidArgument},
+	{domain.ErrInvalidWebhookEvent, codes.InvalidArgument},
+
+	{domain.ErrInsufficientFunds, codes.FailedPrecondition},
+	{domain.ErrSelfBid, codes.FailedPrecondition},
+	{domain.ErrAuctionCancelled, codes.FailedPrecondition},
+	{domain.ErrAuctionSettled, codes.FailedPrecondition},
+	{domain.ErrAuctionNotClosed, codes.FailedPrecondition},
+	{domain.ErrWithdrawalResolved, codes.FailedPrecondition},
+	{domain.ErrWithdrawalProcessing, codes.FailedPrecondition},
+	{domain.ErrWithdrawalPayoutAttempted, codes.FailedPrecondition},
+
+	{domain.ErrAuctionBusy, codes.Aborted},
+}
+
+// ErrorUnaryInterceptor переводит ошибки предметной области, которые возвращают обработчики,
+// в статусы gRPC. Остальные ошибки передаются как есть и получают код Unknown.
+func ErrorUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, statusFromDomain(err)
+	}
+}
+
+func statusFromDomain(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, c := range domainCodes {
+		if errors.Is(err, c.err) {
+			return status.Error(c.code, err.Error())
+		}
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "Unknown subscription", err: domain.ErrWebhookSubscriptionNotFound, want: codes.NotFound},
+		{name: "Wrapped not found", err: fmt.Errorf("get lot: %w", domain.ErrLotNotFound), want: codes.NotFound},
+		{name: "Invalid argument", err: domain.ErrInvalidBidAmount, want: codes.InvalidArgument},
+		{name: "Failed precondition", err: domain.ErrInsufficientFunds, want: codes.FailedPrecondition},
+		{name: "Status is kept", err: status.Error(codes.PermissionDenied, "forbidden"), want: codes.PermissionDenied},
+		{name: "Other error", err: errors.New("connection refused"), want: codes.Unknown},
+		{name: "No error", err: nil, want: codes.OK},
+	}
+
+	interceptor := ErrorUnaryInterceptor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+				return nil, tt.err
+			})
+			assert.Equal(t, tt.want, status.Code(err))
+			if _, isStatus := status.FromError(tt.err); !isStatus {
+				assert.Equal(t, tt.err.Error(), status.Convert(err).Message())
+			}
+		})
+	}
+}
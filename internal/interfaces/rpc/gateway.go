@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"net/textproto"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// NewGatewayMux создаёт mux REST-шлюза, пробрасывающий в gRPC заголовки,
+// которые нужны интерцепторам сервера
+func NewGatewayMux(opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	opts = append([]runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	}, opts...)
+	return runtime.NewServeMux(opts...)
+}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	switch textproto.CanonicalMIMEHeaderKey(key) {
+	case "Authorization", "X-Forwarded-For":
+		// Шлюз сам передаёт эти заголовки как метаданные authorization и x-forwarded-for
+		// (дописывая адрес клиента), копия с префиксом grpcgateway- не нужна
+		return "", false
+	case "X-Request-Id":
+		return requestIDHeader, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+func outgoingHeaderMatcher(key string) (string, bool) {
+	switch key {
+	case retryAfterHeader:
+		return "Retry-After", true
+	case requestIDHeader:
+		return "X-Request-Id", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -2,11 +2,15 @@ package rpc
 
 import (
 	"auction/internal/domain"
+	"auction/internal/infrastructure/logging"
 	v1 "auction/internal/interfaces/rpc/pb"
 
 	"context"
-	"log"
 	"strconv"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type AuctionHandler struct {
@@ -19,10 +23,15 @@ func NewAuctionHandler(service domain.AuctionService) *AuctionHandler {
 }
 
 func (h *AuctionHandler) CreateLot(ctx context.Context, req *v1.CreateLotRequest) (*v1.CreateLotResponse, error) {
-	lot := NewDomainLotFromRequest(req)
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lot := NewDomainLotFromRequest(req, userID)
 	lotID, err := h.auctionService.CreateLot(ctx, lot)
 	if err != nil {
-		log.Printf("Error creating lot: %v", err)
+		logging.FromContext(ctx).Error("failed to create lot", "error", err)
 		return nil, err
 	}
 
@@ -30,24 +39,470 @@ func (h *AuctionHandler) CreateLot(ctx context.Context, req *v1.CreateLotRequest
 }
 
 func (h *AuctionHandler) RefillBalance(ctx context.Context, req *v1.RefillRequest) (*v1.RefillResponse, error) {
-	userID, _ := strconv.Atoi(req.UserId)
-	err := h.auctionService.RefillBalance(ctx, userID, req.Amount)
+	userID, err := callerID(ctx)
 	if err != nil {
-		log.Printf("Error refilling balance: %v", err)
+		return nil, err
+	}
+
+	if req.UserId == "" || req.UserId == strconv.Itoa(userID) {
+		payment, err := h.auctionService.CreatePayment(ctx, userID, req.Amount)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed to create payment", "error", err)
+			return nil, err
+		}
+		return &v1.RefillResponse{
+			Message: "Payment created, the balance will be refilled after checkout",
+			Payment: NewPaymentResponse(payment),
+		}, nil
+	}
+
+	// Право пополнять чужой баланс проверено интерцептором авторизации
+	targetID, err := strconv.Atoi(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+
+	ctx = logging.With(ctx, "target_user_id", targetID)
+	err = h.auctionService.RefillBalance(ctx, targetID, req.Amount)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to refill balance", "error", err)
 		return nil, err
 	}
 
 	return &v1.RefillResponse{Message: "Balance refilled successfully"}, nil
 }
 
+func (h *AuctionHandler) GetPayment(ctx context.Context, req *v1.GetPaymentRequest) (*v1.GetPaymentResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paymentID, err := strconv.Atoi(req.PaymentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid payment_id")
+	}
+
+	ctx = logging.With(ctx, "payment_id", paymentID)
+	payment, err := h.auctionService.GetPayment(ctx, paymentID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get payment", "error", err)
+		return nil, err
+	}
+	// Чужие платежи неотличимы от несуществующих
+	if payment.UserID != userID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	return &v1.GetPaymentResponse{Payment: NewPaymentResponse(payment)}, nil
+}
+
 func (h *AuctionHandler) PlaceBid(ctx context.Context, req *v1.PlaceBidRequest) (*v1.PlaceBidResponse, error) {
-	bid := NewDomainBidFromRequest(req)
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := h.auctionService.PlaceBid(ctx, bid)
+	bid := NewDomainBidFromRequest(req, userID)
+	ctx = logging.With(ctx, "lot_id", bid.LotID)
+
+	_, err = h.auctionService.PlaceBid(ctx, bid)
 	if err != nil {
-		log.Printf("Error placing bid: %v", err)
+		logging.FromContext(ctx).Error("failed to place bid", "error", err)
 		return nil, err
 	}
 
 	return &v1.PlaceBidResponse{Message: "bid placed"}, nil
 }
+
+func (h *AuctionHandler) CancelAuction(ctx context.Context, req *v1.CancelAuctionRequest) (*v1.CancelAuctionResponse, error) {
+	auctionID, err := strconv.Atoi(req.AuctionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid auction_id")
+	}
+
+	ctx = logging.With(ctx, "auction_id", auctionID)
+	err = h.auctionService.CancelAuction(ctx, auctionID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to cancel auction", "error", err)
+		return nil, err
+	}
+
+	return &v1.CancelAuctionResponse{Message: "auction cancelled"}, nil
+}
+
+func (h *AuctionHandler) GetSettlementBreakdown(ctx context.Context, req *v1.GetSettlementBreakdownRequest) (*v1.GetSettlementBreakdownResponse, error) {
+	auctionID, err := strconv.Atoi(req.AuctionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid auction_id")
+	}
+
+	ctx = logging.With(ctx, "auction_id", auctionID)
+	settlement, err := h.auctionService.GetSettlement(ctx, auctionID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get settlement", "error", err)
+		return nil, err
+	}
+
+	return NewSettlementBreakdownResponse(settlement), nil
+}
+
+func (h *AuctionHandler) WatchAuction(ctx context.Context, req *v1.WatchAuctionRequest) (*v1.WatchAuctionResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auctionID, err := strconv.Atoi(req.AuctionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid auction_id")
+	}
+
+	ctx = logging.With(ctx, "auction_id", auctionID)
+	err = h.auctionService.WatchAuction(ctx, userID, auctionID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to watch auction", "error", err)
+		return nil, err
+	}
+
+	return &v1.WatchAuctionResponse{Message: "auction watched"}, nil
+}
+
+func (h *AuctionHandler) UnwatchAuction(ctx context.Context, req *v1.UnwatchAuctionRequest) (*v1.UnwatchAuctionResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	auctionID, err := strconv.Atoi(req.AuctionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid auction_id")
+	}
+
+	ctx = logging.With(ctx, "auction_id", auctionID)
+	err = h.auctionService.UnwatchAuction(ctx, userID, auctionID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to unwatch auction", "error", err)
+		return nil, err
+	}
+
+	return &v1.UnwatchAuctionResponse{Message: "auction unwatched"}, nil
+}
+
+func (h *AuctionHandler) ListShillReviews(ctx context.Context, req *v1.ListShillReviewsRequest) (*v1.ListShillReviewsResponse, error) {
+	reviews, err := h.auctionService.ListShillReviews(ctx, domain.ShillReviewStatus(req.Status), pageLimit(req.Limit), int(req.Offset))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list shill reviews", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListShillReviewsResponse{Reviews: NewShillReviewsResponse(reviews)}, nil
+}
+
+func (h *AuctionHandler) ResolveShillReview(ctx context.Context, req *v1.ResolveShillReviewRequest) (*v1.ResolveShillReviewResponse, error) {
+	adminID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reviewID, err := strconv.Atoi(req.ReviewId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid review_id")
+	}
+
+	ctx = logging.With(ctx, "review_id", reviewID)
+	err = h.auctionService.ResolveShillReview(ctx, reviewID, domain.ShillReviewStatus(req.Resolution), adminID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to resolve shill review", "error", err)
+		return nil, err
+	}
+
+	return &v1.ResolveShillReviewResponse{Message: "review resolved"}, nil
+}
+
+func (h *AuctionHandler) ListAuditEvents(ctx context.Context, req *v1.ListAuditEventsRequest) (*v1.ListAuditEventsResponse, error) {
+	filter, err := NewAuditFilterFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := h.auctionService.ListAuditEvents(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list audit events", "error", err)
+		return nil, err
+	}
+
+	resp, err := NewAuditEventsResponse(events)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert audit events: %v", err)
+	}
+	return &v1.ListAuditEventsResponse{Events: resp}, nil
+}
+
+func (h *AuctionHandler) ListNotifications(ctx context.Context, req *v1.ListNotificationsRequest) (*v1.ListNotificationsResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := h.auctionService.ListNotifications(ctx, userID, req.UnreadOnly, pageLimit(req.Limit), int(req.Offset))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list notifications", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListNotificationsResponse{Notifications: NewNotificationsResponse(notifications)}, nil
+}
+
+func (h *AuctionHandler) MarkNotificationRead(ctx context.Context, req *v1.MarkNotificationReadRequest) (*v1.MarkNotificationReadResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notificationID, err := strconv.ParseInt(req.NotificationId, 10, 64)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid notification_id")
+	}
+
+	ctx = logging.With(ctx, "notification_id", notificationID)
+	err = h.auctionService.MarkNotificationRead(ctx, userID, notificationID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to mark notification read", "error", err)
+		return nil, err
+	}
+
+	return &v1.MarkNotificationReadResponse{Message: "notification marked read"}, nil
+}
+
+func (h *AuctionHandler) UpdateNotificationChannels(ctx context.Context, req *v1.UpdateNotificationChannelsRequest) (*v1.UpdateNotificationChannelsResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.auctionService.UpdateNotificationSettings(ctx, userID, NewNotificationSettingsFromRequest(req))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to update notification channels", "error", err)
+		return nil, err
+	}
+
+	return &v1.UpdateNotificationChannelsResponse{Message: "notification channels updated"}, nil
+}
+
+func (h *AuctionHandler) GetNotificationPreferences(ctx context.Context, _ *v1.GetNotificationPreferencesRequest) (*v1.GetNotificationPreferencesResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := h.auctionService.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get notification preferences", "error", err)
+		return nil, err
+	}
+
+	return NewNotificationPreferencesResponse(preferences), nil
+}
+
+func (h *AuctionHandler) UpdateNotificationPreferences(ctx context.Context, req *v1.UpdateNotificationPreferencesRequest) (*v1.UpdateNotificationPreferencesResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := NewNotificationPreferencesFromRequest(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.auctionService.UpdateNotificationPreferences(ctx, preferences); err != nil {
+		logging.FromContext(ctx).Error("failed to update notification preferences", "error", err)
+		return nil, err
+	}
+
+	return &v1.UpdateNotificationPreferencesResponse{Message: "notification preferences updated"}, nil
+}
+
+func (h *AuctionHandler) CreateWebhookSubscription(ctx context.Context, req *v1.CreateWebhookSubscriptionRequest) (*v1.CreateWebhookSubscriptionResponse, error) {
+	subscription, err := h.auctionService.CreateWebhookSubscription(ctx, NewWebhookSubscriptionFromRequest(req))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to create webhook subscription", "error", err)
+		return nil, err
+	}
+
+	return &v1.CreateWebhookSubscriptionResponse{
+		SubscriptionId: strconv.Itoa(subscription.SubscriptionID),
+		Secret:         subscription.Secret,
+	}, nil
+}
+
+func (h *AuctionHandler) ListWebhookSubscriptions(ctx context.Context, _ *v1.ListWebhookSubscriptionsRequest) (*v1.ListWebhookSubscriptionsResponse, error) {
+	subscriptions, err := h.auctionService.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list webhook subscriptions", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListWebhookSubscriptionsResponse{Subscriptions: NewWebhookSubscriptionsResponse(subscriptions)}, nil
+}
+
+func (h *AuctionHandler) DeleteWebhookSubscription(ctx context.Context, req *v1.DeleteWebhookSubscriptionRequest) (*v1.DeleteWebhookSubscriptionResponse, error) {
+	subscriptionID, err := strconv.Atoi(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id")
+	}
+
+	ctx = logging.With(ctx, "subscription_id", subscriptionID)
+	if err := h.auctionService.DeleteWebhookSubscription(ctx, subscriptionID); err != nil {
+		logging.FromContext(ctx).Error("failed to delete webhook subscription", "error", err)
+		return nil, err
+	}
+
+	return &v1.DeleteWebhookSubscriptionResponse{Message: "webhook subscription deleted"}, nil
+}
+
+func (h *AuctionHandler) EnableWebhookSubscription(ctx context.Context, req *v1.EnableWebhookSubscriptionRequest) (*v1.EnableWebhookSubscriptionResponse, error) {
+	subscriptionID, err := strconv.Atoi(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id")
+	}
+
+	ctx = logging.With(ctx, "subscription_id", subscriptionID)
+	if err := h.auctionService.EnableWebhookSubscription(ctx, subscriptionID); err != nil {
+		logging.FromContext(ctx).Error("failed to enable webhook subscription", "error", err)
+		return nil, err
+	}
+
+	return &v1.EnableWebhookSubscriptionResponse{Message: "webhook subscription enabled"}, nil
+}
+
+func (h *AuctionHandler) ListWebhookDeliveries(ctx context.Context, req *v1.ListWebhookDeliveriesRequest) (*v1.ListWebhookDeliveriesResponse, error) {
+	subscriptionID, err := strconv.Atoi(req.SubscriptionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid subscription_id")
+	}
+
+	ctx = logging.With(ctx, "subscription_id", subscriptionID)
+	deliveries, err := h.auctionService.ListWebhookDeliveries(ctx, subscriptionID, pageLimit(req.Limit), int(req.Offset))
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list webhook deliveries", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListWebhookDeliveriesResponse{Deliveries: NewWebhookDeliveriesResponse(deliveries)}, nil
+}
+
+func (h *AuctionHandler) RequestWithdrawal(ctx context.Context, req *v1.RequestWithdrawalRequest) (*v1.RequestWithdrawalResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalID, err := h.auctionService.RequestWithdrawal(ctx, domain.Withdrawal{
+		UserID:      userID,
+		Amount:      req.Amount,
+		Destination: req.Destination,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to request withdrawal", "error", err)
+		return nil, err
+	}
+
+	return &v1.RequestWithdrawalResponse{WithdrawalId: strconv.Itoa(withdrawalID)}, nil
+}
+
+func (h *AuctionHandler) ListWithdrawals(ctx context.Context, req *v1.ListWithdrawalsRequest) (*v1.ListWithdrawalsResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := h.auctionService.ListWithdrawals(ctx, domain.WithdrawalFilter{
+		UserID: userID,
+		Limit:  pageLimit(req.Limit),
+		Offset: int(req.Offset),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list withdrawals", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListWithdrawalsResponse{Withdrawals: NewWithdrawalsResponse(withdrawals)}, nil
+}
+
+func (h *AuctionHandler) ListAllWithdrawals(ctx context.Context, req *v1.ListAllWithdrawalsRequest) (*v1.ListWithdrawalsResponse, error) {
+	filter, err := NewAllWithdrawalsFilterFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := h.auctionService.ListWithdrawals(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list withdrawals", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListWithdrawalsResponse{Withdrawals: NewWithdrawalsResponse(withdrawals)}, nil
+}
+
+func (h *AuctionHandler) ResolveWithdrawal(ctx context.Context, req *v1.ResolveWithdrawalRequest) (*v1.ResolveWithdrawalResponse, error) {
+	adminID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	withdrawalID, err := strconv.Atoi(req.WithdrawalId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid withdrawal_id")
+	}
+
+	ctx = logging.With(ctx, "withdrawal_id", withdrawalID)
+	withdrawal, err := h.auctionService.ResolveWithdrawal(ctx, withdrawalID, domain.WithdrawalStatus(req.Resolution), adminID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to resolve withdrawal", "error", err)
+		return nil, err
+	}
+
+	return &v1.ResolveWithdrawalResponse{Withdrawal: NewWithdrawalResponse(withdrawal)}, nil
+}
+
+func (h *AuctionHandler) ListBalanceTransactions(ctx context.Context, req *v1.ListBalanceTransactionsRequest) (*v1.ListBalanceTransactionsResponse, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := NewBalanceTransactionFilterFromRequest(req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = logging.With(ctx, "target_user_id", filter.UserID)
+	transactions, err := h.auctionService.ListBalanceTransactions(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list balance transactions", "error", err)
+		return nil, err
+	}
+
+	return &v1.ListBalanceTransactionsResponse{Transactions: NewBalanceTransactionsResponse(transactions)}, nil
+}
+
+func (h *AuctionHandler) ExportBalanceTransactions(ctx context.Context, req *v1.ListBalanceTransactionsRequest) (*httpbody.HttpBody, error) {
+	userID, err := callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := NewBalanceTransactionFilterFromRequest(req, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Выгрузка содержит весь журнал за период
+	filter.Limit, filter.Offset = 0, 0
+
+	ctx = logging.With(ctx, "target_user_id", filter.UserID)
+	transactions, err := h.auctionService.ListBalanceTransactions(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to export balance transactions", "error", err)
+		return nil, err
+	}
+	data, err := NewBalanceTransactionsCSV(transactions)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to export balance transactions", "error", err)
+		return nil, err
+	}
+
+	return &httpbody.HttpBody{ContentType: "text/csv; charset=utf-8", Data: data}, nil
+}
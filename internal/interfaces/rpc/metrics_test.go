@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewServerMetrics()
+	reg.MustRegister(metrics)
+	interceptor := metrics.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/auction.v1.AuctionService/PlaceBid"}
+
+	ok := func(context.Context, any) (any, error) { return "ok", nil }
+	denied := func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	for _, handler := range []grpc.UnaryHandler{ok, ok, denied} {
+		_, _ = interceptor(context.Background(), nil, info, handler)
+	}
+
+	expected := `
+# HELP grpc_server_handled_total Number of RPCs completed on the server by method and status code.
+# TYPE grpc_server_handled_total counter
+grpc_server_handled_total{grpc_code="OK",grpc_method="/auction.v1.AuctionService/PlaceBid"} 2
+grpc_server_handled_total{grpc_code="PermissionDenied",grpc_method="/auction.v1.AuctionService/PlaceBid"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "grpc_server_handled_total"))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics, "grpc_server_handling_seconds"))
+}
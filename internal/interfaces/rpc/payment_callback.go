@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// maxCallbackSize - предел размера уведомления провайдера
+const maxCallbackSize = 64 << 10
+
+// PaymentCallbackParser проверяет подпись уведомления провайдера и разбирает его
+type PaymentCallbackParser interface {
+	Name() string
+	ParseCallback(header http.Header, body []byte) (domain.PaymentCallback, error)
+}
+
+// NewPaymentCallbackHandler принимает уведомления провайдера о результате оплаты.
+// Провайдер повторяет уведомление, пока не получит 2xx, поэтому временные ошибки
+// отвечают 500, а уведомления, которые не примутся и при повторе, - 4xx.
+func NewPaymentCallbackHandler(service domain.AuctionService, provider PaymentCallbackParser, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := logger.With("payment_provider", provider.Name())
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCallbackSize))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		callback, err := provider.ParseCallback(r.Header, body)
+		if err != nil {
+			logger.Warn("rejected payment callback", "error", err)
+			http.Error(w, "invalid callback", http.StatusUnauthorized)
+			return
+		}
+
+		logger = logger.With("provider_payment_id", callback.ProviderPaymentID)
+		payment, err := service.ConfirmPayment(r.Context(), provider.Name(), callback)
+		switch {
+		case errors.Is(err, domain.ErrPaymentNotFound):
+			logger.Warn("payment callback for unknown payment")
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, domain.ErrInvalidPaymentStatus), errors.Is(err, domain.ErrPaymentAmountMismatch):
+			logger.Error("payment callback does not match the payment", "error", err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			logger.Error("failed to confirm payment", "error", err)
+			http.Error(w, "failed to confirm payment", http.StatusInternalServerError)
+			return
+		}
+
+		logger.Info("payment confirmed", "payment_id", payment.PaymentID, "status", payment.Status)
+		w.WriteHeader(http.StatusOK)
+	})
+}
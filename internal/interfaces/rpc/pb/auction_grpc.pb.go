@@ -8,6 +8,7 @@ package v1
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,9 +20,33 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AuctionService_CreateLot_FullMethodName     = "/auction.v1.AuctionService/CreateLot"
-	AuctionService_RefillBalance_FullMethodName = "/auction.v1.AuctionService/RefillBalance"
-	AuctionService_PlaceBid_FullMethodName      = "/auction.v1.AuctionService/PlaceBid"
+	AuctionService_CreateLot_FullMethodName                     = "/auction.v1.AuctionService/CreateLot"
+	AuctionService_RefillBalance_FullMethodName                 = "/auction.v1.AuctionService/RefillBalance"
+	AuctionService_GetPayment_FullMethodName                    = "/auction.v1.AuctionService/GetPayment"
+	AuctionService_PlaceBid_FullMethodName                      = "/auction.v1.AuctionService/PlaceBid"
+	AuctionService_CancelAuction_FullMethodName                 = "/auction.v1.AuctionService/CancelAuction"
+	AuctionService_GetSettlementBreakdown_FullMethodName        = "/auction.v1.AuctionService/GetSettlementBreakdown"
+	AuctionService_WatchAuction_FullMethodName                  = "/auction.v1.AuctionService/WatchAuction"
+	AuctionService_UnwatchAuction_FullMethodName                = "/auction.v1.AuctionService/UnwatchAuction"
+	AuctionService_ListShillReviews_FullMethodName              = "/auction.v1.AuctionService/ListShillReviews"
+	AuctionService_ResolveShillReview_FullMethodName            = "/auction.v1.AuctionService/ResolveShillReview"
+	AuctionService_ListAuditEvents_FullMethodName               = "/auction.v1.AuctionService/ListAuditEvents"
+	AuctionService_ListNotifications_FullMethodName             = "/auction.v1.AuctionService/ListNotifications"
+	AuctionService_MarkNotificationRead_FullMethodName          = "/auction.v1.AuctionService/MarkNotificationRead"
+	AuctionService_UpdateNotificationChannels_FullMethodName    = "/auction.v1.AuctionService/UpdateNotificationChannels"
+	AuctionService_GetNotificationPreferences_FullMethodName    = "/auction.v1.AuctionService/GetNotificationPreferences"
+	AuctionService_UpdateNotificationPreferences_FullMethodName = "/auction.v1.AuctionService/UpdateNotificationPreferences"
+	AuctionService_CreateWebhookSubscription_FullMethodName     = "/auction.v1.AuctionService/CreateWebhookSubscription"
+	AuctionService_ListWebhookSubscriptions_FullMethodName      = "/auction.v1.AuctionService/ListWebhookSubscriptions"
+	AuctionService_DeleteWebhookSubscription_FullMethodName     = "/auction.v1.AuctionService/DeleteWebhookSubscription"
+	AuctionService_EnableWebhookSubscription_FullMethodName     = "/auction.v1.AuctionService/EnableWebhookSubscription"
+	AuctionService_ListWebhookDeliveries_FullMethodName         = "/auction.v1.AuctionService/ListWebhookDeliveries"
+	AuctionService_ListBalanceTransactions_FullMethodName       = "/auction.v1.AuctionService/ListBalanceTransactions"
+	AuctionService_ExportBalanceTransactions_FullMethodName     = "/auction.v1.AuctionService/ExportBalanceTransactions"
+	AuctionService_RequestWithdrawal_FullMethodName             = "/auction.v1.AuctionService/RequestWithdrawal"
+	AuctionService_ListWithdrawals_FullMethodName               = "/auction.v1.AuctionService/ListWithdrawals"
+	AuctionService_ListAllWithdrawals_FullMethodName            = "/auction.v1.AuctionService/ListAllWithdrawals"
+	AuctionService_ResolveWithdrawal_FullMethodName             = "/auction.v1.AuctionService/ResolveWithdrawal"
 )
 
 // AuctionServiceClient is the client API for AuctionService service.
@@ -30,7 +55,51 @@ const (
 type AuctionServiceClient interface {
 	CreateLot(ctx context.Context, in *CreateLotRequest, opts ...grpc.CallOption) (*CreateLotResponse, error)
 	RefillBalance(ctx context.Context, in *RefillRequest, opts ...grpc.CallOption) (*RefillResponse, error)
+	// Состояние платежа автора запроса
+	GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*GetPaymentResponse, error)
 	PlaceBid(ctx context.Context, in *PlaceBidRequest, opts ...grpc.CallOption) (*PlaceBidResponse, error)
+	CancelAuction(ctx context.Context, in *CancelAuctionRequest, opts ...grpc.CallOption) (*CancelAuctionResponse, error)
+	// Итог расчёта аукциона: цена, комиссия площадки и выплата продавцу
+	GetSettlementBreakdown(ctx context.Context, in *GetSettlementBreakdownRequest, opts ...grpc.CallOption) (*GetSettlementBreakdownResponse, error)
+	// Подписка на напоминание о завершении аукциона
+	WatchAuction(ctx context.Context, in *WatchAuctionRequest, opts ...grpc.CallOption) (*WatchAuctionResponse, error)
+	UnwatchAuction(ctx context.Context, in *UnwatchAuctionRequest, opts ...grpc.CallOption) (*UnwatchAuctionResponse, error)
+	// Очередь проверки подозрительных ставок (только для администраторов)
+	ListShillReviews(ctx context.Context, in *ListShillReviewsRequest, opts ...grpc.CallOption) (*ListShillReviewsResponse, error)
+	ResolveShillReview(ctx context.Context, in *ResolveShillReviewRequest, opts ...grpc.CallOption) (*ResolveShillReviewResponse, error)
+	// Журнал аудита (только для администраторов)
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
+	// Лента уведомлений автора запроса в приложении
+	ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error)
+	MarkNotificationRead(ctx context.Context, in *MarkNotificationReadRequest, opts ...grpc.CallOption) (*MarkNotificationReadResponse, error)
+	// Каналы, по которым автор запроса получает уведомления
+	UpdateNotificationChannels(ctx context.Context, in *UpdateNotificationChannelsRequest, opts ...grpc.CallOption) (*UpdateNotificationChannelsResponse, error)
+	// Какие события и по каким каналам получает автор запроса, тихие часы
+	GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error)
+	// Заменяет настройки целиком: события, которых нет в запросе, приходят по каналам пользователя
+	UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error)
+	// Подписки внешних систем на события аукциона (только для администраторов)
+	CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*CreateWebhookSubscriptionResponse, error)
+	ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error)
+	DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*DeleteWebhookSubscriptionResponse, error)
+	// Включает подписку, отключённую после неудачных доставок
+	EnableWebhookSubscription(ctx context.Context, in *EnableWebhookSubscriptionRequest, opts ...grpc.CallOption) (*EnableWebhookSubscriptionResponse, error)
+	// Журнал доставок подписки, новые первыми
+	ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error)
+	// Журнал движений средств с балансом после каждого движения, новые первыми.
+	// Администраторы могут указать user_id другого пользователя.
+	ListBalanceTransactions(ctx context.Context, in *ListBalanceTransactionsRequest, opts ...grpc.CallOption) (*ListBalanceTransactionsResponse, error)
+	// Тот же журнал целиком в CSV для бухгалтерии, без постраничного вывода
+	ExportBalanceTransactions(ctx context.Context, in *ListBalanceTransactionsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	// Заявка на вывод средств: сумма сразу удерживается с баланса до решения администратора
+	RequestWithdrawal(ctx context.Context, in *RequestWithdrawalRequest, opts ...grpc.CallOption) (*RequestWithdrawalResponse, error)
+	// Заявки автора запроса, новые первыми
+	ListWithdrawals(ctx context.Context, in *ListWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error)
+	// Заявки всех пользователей (только для администраторов)
+	ListAllWithdrawals(ctx context.Context, in *ListAllWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error)
+	// Одобряет или отклоняет заявку. Одобренная заявка сразу выплачивается, при ошибке
+	// провайдера одобрение можно повторить.
+	ResolveWithdrawal(ctx context.Context, in *ResolveWithdrawalRequest, opts ...grpc.CallOption) (*ResolveWithdrawalResponse, error)
 }
 
 type auctionServiceClient struct {
@@ -61,6 +130,16 @@ func (c *auctionServiceClient) RefillBalance(ctx context.Context, in *RefillRequ
 	return out, nil
 }
 
+func (c *auctionServiceClient) GetPayment(ctx context.Context, in *GetPaymentRequest, opts ...grpc.CallOption) (*GetPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPaymentResponse)
+	err := c.cc.Invoke(ctx, AuctionService_GetPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *auctionServiceClient) PlaceBid(ctx context.Context, in *PlaceBidRequest, opts ...grpc.CallOption) (*PlaceBidResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PlaceBidResponse)
@@ -71,13 +150,287 @@ func (c *auctionServiceClient) PlaceBid(ctx context.Context, in *PlaceBidRequest
 	return out, nil
 }
 
+func (c *auctionServiceClient) CancelAuction(ctx context.Context, in *CancelAuctionRequest, opts ...grpc.CallOption) (*CancelAuctionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelAuctionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_CancelAuction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) GetSettlementBreakdown(ctx context.Context, in *GetSettlementBreakdownRequest, opts ...grpc.CallOption) (*GetSettlementBreakdownResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSettlementBreakdownResponse)
+	err := c.cc.Invoke(ctx, AuctionService_GetSettlementBreakdown_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) WatchAuction(ctx context.Context, in *WatchAuctionRequest, opts ...grpc.CallOption) (*WatchAuctionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WatchAuctionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_WatchAuction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) UnwatchAuction(ctx context.Context, in *UnwatchAuctionRequest, opts ...grpc.CallOption) (*UnwatchAuctionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnwatchAuctionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_UnwatchAuction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListShillReviews(ctx context.Context, in *ListShillReviewsRequest, opts ...grpc.CallOption) (*ListShillReviewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListShillReviewsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListShillReviews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ResolveShillReview(ctx context.Context, in *ResolveShillReviewRequest, opts ...grpc.CallOption) (*ResolveShillReviewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveShillReviewResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ResolveShillReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListAuditEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListNotifications(ctx context.Context, in *ListNotificationsRequest, opts ...grpc.CallOption) (*ListNotificationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNotificationsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListNotifications_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) MarkNotificationRead(ctx context.Context, in *MarkNotificationReadRequest, opts ...grpc.CallOption) (*MarkNotificationReadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkNotificationReadResponse)
+	err := c.cc.Invoke(ctx, AuctionService_MarkNotificationRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) UpdateNotificationChannels(ctx context.Context, in *UpdateNotificationChannelsRequest, opts ...grpc.CallOption) (*UpdateNotificationChannelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNotificationChannelsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_UpdateNotificationChannels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*GetNotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, AuctionService_GetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) UpdateNotificationPreferences(ctx context.Context, in *UpdateNotificationPreferencesRequest, opts ...grpc.CallOption) (*UpdateNotificationPreferencesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNotificationPreferencesResponse)
+	err := c.cc.Invoke(ctx, AuctionService_UpdateNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) CreateWebhookSubscription(ctx context.Context, in *CreateWebhookSubscriptionRequest, opts ...grpc.CallOption) (*CreateWebhookSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateWebhookSubscriptionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_CreateWebhookSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListWebhookSubscriptions(ctx context.Context, in *ListWebhookSubscriptionsRequest, opts ...grpc.CallOption) (*ListWebhookSubscriptionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookSubscriptionsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListWebhookSubscriptions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) DeleteWebhookSubscription(ctx context.Context, in *DeleteWebhookSubscriptionRequest, opts ...grpc.CallOption) (*DeleteWebhookSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteWebhookSubscriptionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_DeleteWebhookSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) EnableWebhookSubscription(ctx context.Context, in *EnableWebhookSubscriptionRequest, opts ...grpc.CallOption) (*EnableWebhookSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnableWebhookSubscriptionResponse)
+	err := c.cc.Invoke(ctx, AuctionService_EnableWebhookSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListWebhookDeliveries(ctx context.Context, in *ListWebhookDeliveriesRequest, opts ...grpc.CallOption) (*ListWebhookDeliveriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWebhookDeliveriesResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListWebhookDeliveries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListBalanceTransactions(ctx context.Context, in *ListBalanceTransactionsRequest, opts ...grpc.CallOption) (*ListBalanceTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBalanceTransactionsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListBalanceTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ExportBalanceTransactions(ctx context.Context, in *ListBalanceTransactionsRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, AuctionService_ExportBalanceTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) RequestWithdrawal(ctx context.Context, in *RequestWithdrawalRequest, opts ...grpc.CallOption) (*RequestWithdrawalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestWithdrawalResponse)
+	err := c.cc.Invoke(ctx, AuctionService_RequestWithdrawal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListWithdrawals(ctx context.Context, in *ListWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWithdrawalsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListWithdrawals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ListAllWithdrawals(ctx context.Context, in *ListAllWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWithdrawalsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ListAllWithdrawals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) ResolveWithdrawal(ctx context.Context, in *ResolveWithdrawalRequest, opts ...grpc.CallOption) (*ResolveWithdrawalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveWithdrawalResponse)
+	err := c.cc.Invoke(ctx, AuctionService_ResolveWithdrawal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuctionServiceServer is the server API for AuctionService service.
 // All implementations must embed UnimplementedAuctionServiceServer
 // for forward compatibility.
 type AuctionServiceServer interface {
 	CreateLot(context.Context, *CreateLotRequest) (*CreateLotResponse, error)
 	RefillBalance(context.Context, *RefillRequest) (*RefillResponse, error)
+	// Состояние платежа автора запроса
+	GetPayment(context.Context, *GetPaymentRequest) (*GetPaymentResponse, error)
 	PlaceBid(context.Context, *PlaceBidRequest) (*PlaceBidResponse, error)
+	CancelAuction(context.Context, *CancelAuctionRequest) (*CancelAuctionResponse, error)
+	// Итог расчёта аукциона: цена, комиссия площадки и выплата продавцу
+	GetSettlementBreakdown(context.Context, *GetSettlementBreakdownRequest) (*GetSettlementBreakdownResponse, error)
+	// Подписка на напоминание о завершении аукциона
+	WatchAuction(context.Context, *WatchAuctionRequest) (*WatchAuctionResponse, error)
+	UnwatchAuction(context.Context, *UnwatchAuctionRequest) (*UnwatchAuctionResponse, error)
+	// Очередь проверки подозрительных ставок (только для администраторов)
+	ListShillReviews(context.Context, *ListShillReviewsRequest) (*ListShillReviewsResponse, error)
+	ResolveShillReview(context.Context, *ResolveShillReviewRequest) (*ResolveShillReviewResponse, error)
+	// Журнал аудита (только для администраторов)
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
+	// Лента уведомлений автора запроса в приложении
+	ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error)
+	MarkNotificationRead(context.Context, *MarkNotificationReadRequest) (*MarkNotificationReadResponse, error)
+	// Каналы, по которым автор запроса получает уведомления
+	UpdateNotificationChannels(context.Context, *UpdateNotificationChannelsRequest) (*UpdateNotificationChannelsResponse, error)
+	// Какие события и по каким каналам получает автор запроса, тихие часы
+	GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error)
+	// Заменяет настройки целиком: события, которых нет в запросе, приходят по каналам пользователя
+	UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error)
+	// Подписки внешних систем на события аукциона (только для администраторов)
+	CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*CreateWebhookSubscriptionResponse, error)
+	ListWebhookSubscriptions(context.Context, *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error)
+	DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*DeleteWebhookSubscriptionResponse, error)
+	// Включает подписку, отключённую после неудачных доставок
+	EnableWebhookSubscription(context.Context, *EnableWebhookSubscriptionRequest) (*EnableWebhookSubscriptionResponse, error)
+	// Журнал доставок подписки, новые первыми
+	ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error)
+	// Журнал движений средств с балансом после каждого движения, новые первыми.
+	// Администраторы могут указать user_id другого пользователя.
+	ListBalanceTransactions(context.Context, *ListBalanceTransactionsRequest) (*ListBalanceTransactionsResponse, error)
+	// Тот же журнал целиком в CSV для бухгалтерии, без постраничного вывода
+	ExportBalanceTransactions(context.Context, *ListBalanceTransactionsRequest) (*httpbody.HttpBody, error)
+	// Заявка на вывод средств: сумма сразу удерживается с баланса до решения администратора
+	RequestWithdrawal(context.Context, *RequestWithdrawalRequest) (*RequestWithdrawalResponse, error)
+	// Заявки автора запроса, новые первыми
+	ListWithdrawals(context.Context, *ListWithdrawalsRequest) (*ListWithdrawalsResponse, error)
+	// Заявки всех пользователей (только для администраторов)
+	ListAllWithdrawals(context.Context, *ListAllWithdrawalsRequest) (*ListWithdrawalsResponse, error)
+	// Одобряет или отклоняет заявку. Одобренная заявка сразу выплачивается, при ошибке
+	// провайдера одобрение можно повторить.
+	ResolveWithdrawal(context.Context, *ResolveWithdrawalRequest) (*ResolveWithdrawalResponse, error)
 	mustEmbedUnimplementedAuctionServiceServer()
 }
 
@@ -94,9 +447,81 @@ func (UnimplementedAuctionServiceServer) CreateLot(context.Context, *CreateLotRe
 func (UnimplementedAuctionServiceServer) RefillBalance(context.Context, *RefillRequest) (*RefillResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RefillBalance not implemented")
 }
+func (UnimplementedAuctionServiceServer) GetPayment(context.Context, *GetPaymentRequest) (*GetPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPayment not implemented")
+}
 func (UnimplementedAuctionServiceServer) PlaceBid(context.Context, *PlaceBidRequest) (*PlaceBidResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PlaceBid not implemented")
 }
+func (UnimplementedAuctionServiceServer) CancelAuction(context.Context, *CancelAuctionRequest) (*CancelAuctionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelAuction not implemented")
+}
+func (UnimplementedAuctionServiceServer) GetSettlementBreakdown(context.Context, *GetSettlementBreakdownRequest) (*GetSettlementBreakdownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSettlementBreakdown not implemented")
+}
+func (UnimplementedAuctionServiceServer) WatchAuction(context.Context, *WatchAuctionRequest) (*WatchAuctionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WatchAuction not implemented")
+}
+func (UnimplementedAuctionServiceServer) UnwatchAuction(context.Context, *UnwatchAuctionRequest) (*UnwatchAuctionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnwatchAuction not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListShillReviews(context.Context, *ListShillReviewsRequest) (*ListShillReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShillReviews not implemented")
+}
+func (UnimplementedAuctionServiceServer) ResolveShillReview(context.Context, *ResolveShillReviewRequest) (*ResolveShillReviewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveShillReview not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListNotifications(context.Context, *ListNotificationsRequest) (*ListNotificationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNotifications not implemented")
+}
+func (UnimplementedAuctionServiceServer) MarkNotificationRead(context.Context, *MarkNotificationReadRequest) (*MarkNotificationReadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkNotificationRead not implemented")
+}
+func (UnimplementedAuctionServiceServer) UpdateNotificationChannels(context.Context, *UpdateNotificationChannelsRequest) (*UpdateNotificationChannelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationChannels not implemented")
+}
+func (UnimplementedAuctionServiceServer) GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*GetNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedAuctionServiceServer) UpdateNotificationPreferences(context.Context, *UpdateNotificationPreferencesRequest) (*UpdateNotificationPreferencesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNotificationPreferences not implemented")
+}
+func (UnimplementedAuctionServiceServer) CreateWebhookSubscription(context.Context, *CreateWebhookSubscriptionRequest) (*CreateWebhookSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWebhookSubscription not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListWebhookSubscriptions(context.Context, *ListWebhookSubscriptionsRequest) (*ListWebhookSubscriptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookSubscriptions not implemented")
+}
+func (UnimplementedAuctionServiceServer) DeleteWebhookSubscription(context.Context, *DeleteWebhookSubscriptionRequest) (*DeleteWebhookSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWebhookSubscription not implemented")
+}
+func (UnimplementedAuctionServiceServer) EnableWebhookSubscription(context.Context, *EnableWebhookSubscriptionRequest) (*EnableWebhookSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableWebhookSubscription not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListWebhookDeliveries(context.Context, *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWebhookDeliveries not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListBalanceTransactions(context.Context, *ListBalanceTransactionsRequest) (*ListBalanceTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBalanceTransactions not implemented")
+}
+func (UnimplementedAuctionServiceServer) ExportBalanceTransactions(context.Context, *ListBalanceTransactionsRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportBalanceTransactions not implemented")
+}
+func (UnimplementedAuctionServiceServer) RequestWithdrawal(context.Context, *RequestWithdrawalRequest) (*RequestWithdrawalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestWithdrawal not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListWithdrawals(context.Context, *ListWithdrawalsRequest) (*ListWithdrawalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWithdrawals not implemented")
+}
+func (UnimplementedAuctionServiceServer) ListAllWithdrawals(context.Context, *ListAllWithdrawalsRequest) (*ListWithdrawalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAllWithdrawals not implemented")
+}
+func (UnimplementedAuctionServiceServer) ResolveWithdrawal(context.Context, *ResolveWithdrawalRequest) (*ResolveWithdrawalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveWithdrawal not implemented")
+}
 func (UnimplementedAuctionServiceServer) mustEmbedUnimplementedAuctionServiceServer() {}
 func (UnimplementedAuctionServiceServer) testEmbeddedByValue()                        {}
 
@@ -154,6 +579,24 @@ func _AuctionService_RefillBalance_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuctionService_GetPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).GetPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_GetPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).GetPayment(ctx, req.(*GetPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AuctionService_PlaceBid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PlaceBidRequest)
 	if err := dec(in); err != nil {
@@ -172,24 +615,534 @@ func _AuctionService_PlaceBid_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-// AuctionService_ServiceDesc is the grpc.ServiceDesc for AuctionService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var AuctionService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "auction.v1.AuctionService",
-	HandlerType: (*AuctionServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "CreateLot",
-			Handler:    _AuctionService_CreateLot_Handler,
-		},
-		{
-			MethodName: "RefillBalance",
-			Handler:    _AuctionService_RefillBalance_Handler,
-		},
-		{
-			MethodName: "PlaceBid",
-			Handler:    _AuctionService_PlaceBid_Handler,
+func _AuctionService_CancelAuction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelAuctionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).CancelAuction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_CancelAuction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).CancelAuction(ctx, req.(*CancelAuctionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_GetSettlementBreakdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSettlementBreakdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).GetSettlementBreakdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_GetSettlementBreakdown_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).GetSettlementBreakdown(ctx, req.(*GetSettlementBreakdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_WatchAuction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WatchAuctionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).WatchAuction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_WatchAuction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).WatchAuction(ctx, req.(*WatchAuctionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_UnwatchAuction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnwatchAuctionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).UnwatchAuction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_UnwatchAuction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).UnwatchAuction(ctx, req.(*UnwatchAuctionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListShillReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShillReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListShillReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListShillReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListShillReviews(ctx, req.(*ListShillReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ResolveShillReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveShillReviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ResolveShillReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ResolveShillReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ResolveShillReview(ctx, req.(*ResolveShillReviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListNotifications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListNotifications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListNotifications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListNotifications(ctx, req.(*ListNotificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_MarkNotificationRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkNotificationReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).MarkNotificationRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_MarkNotificationRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).MarkNotificationRead(ctx, req.(*MarkNotificationReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_UpdateNotificationChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNotificationChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).UpdateNotificationChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_UpdateNotificationChannels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).UpdateNotificationChannels(ctx, req.(*UpdateNotificationChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).GetNotificationPreferences(ctx, req.(*GetNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_UpdateNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).UpdateNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_UpdateNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).UpdateNotificationPreferences(ctx, req.(*UpdateNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_CreateWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).CreateWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_CreateWebhookSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).CreateWebhookSubscription(ctx, req.(*CreateWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListWebhookSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookSubscriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListWebhookSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListWebhookSubscriptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListWebhookSubscriptions(ctx, req.(*ListWebhookSubscriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_DeleteWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).DeleteWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_DeleteWebhookSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).DeleteWebhookSubscription(ctx, req.(*DeleteWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_EnableWebhookSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableWebhookSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).EnableWebhookSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_EnableWebhookSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).EnableWebhookSubscription(ctx, req.(*EnableWebhookSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListWebhookDeliveries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhookDeliveriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListWebhookDeliveries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListWebhookDeliveries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListWebhookDeliveries(ctx, req.(*ListWebhookDeliveriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListBalanceTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBalanceTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListBalanceTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListBalanceTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListBalanceTransactions(ctx, req.(*ListBalanceTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ExportBalanceTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBalanceTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ExportBalanceTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ExportBalanceTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ExportBalanceTransactions(ctx, req.(*ListBalanceTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_RequestWithdrawal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestWithdrawalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).RequestWithdrawal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_RequestWithdrawal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).RequestWithdrawal(ctx, req.(*RequestWithdrawalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListWithdrawals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWithdrawalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListWithdrawals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListWithdrawals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListWithdrawals(ctx, req.(*ListWithdrawalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ListAllWithdrawals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAllWithdrawalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ListAllWithdrawals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ListAllWithdrawals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ListAllWithdrawals(ctx, req.(*ListAllWithdrawalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_ResolveWithdrawal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveWithdrawalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).ResolveWithdrawal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_ResolveWithdrawal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).ResolveWithdrawal(ctx, req.(*ResolveWithdrawalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuctionService_ServiceDesc is the grpc.ServiceDesc for AuctionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuctionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "auction.v1.AuctionService",
+	HandlerType: (*AuctionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateLot",
+			Handler:    _AuctionService_CreateLot_Handler,
+		},
+		{
+			MethodName: "RefillBalance",
+			Handler:    _AuctionService_RefillBalance_Handler,
+		},
+		{
+			MethodName: "GetPayment",
+			Handler:    _AuctionService_GetPayment_Handler,
+		},
+		{
+			MethodName: "PlaceBid",
+			Handler:    _AuctionService_PlaceBid_Handler,
+		},
+		{
+			MethodName: "CancelAuction",
+			Handler:    _AuctionService_CancelAuction_Handler,
+		},
+		{
+			MethodName: "GetSettlementBreakdown",
+			Handler:    _AuctionService_GetSettlementBreakdown_Handler,
+		},
+		{
+			MethodName: "WatchAuction",
+			Handler:    _AuctionService_WatchAuction_Handler,
+		},
+		{
+			MethodName: "UnwatchAuction",
+			Handler:    _AuctionService_UnwatchAuction_Handler,
+		},
+		{
+			MethodName: "ListShillReviews",
+			Handler:    _AuctionService_ListShillReviews_Handler,
+		},
+		{
+			MethodName: "ResolveShillReview",
+			Handler:    _AuctionService_ResolveShillReview_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _AuctionService_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "ListNotifications",
+			Handler:    _AuctionService_ListNotifications_Handler,
+		},
+		{
+			MethodName: "MarkNotificationRead",
+			Handler:    _AuctionService_MarkNotificationRead_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationChannels",
+			Handler:    _AuctionService_UpdateNotificationChannels_Handler,
+		},
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _AuctionService_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "UpdateNotificationPreferences",
+			Handler:    _AuctionService_UpdateNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "CreateWebhookSubscription",
+			Handler:    _AuctionService_CreateWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "ListWebhookSubscriptions",
+			Handler:    _AuctionService_ListWebhookSubscriptions_Handler,
+		},
+		{
+			MethodName: "DeleteWebhookSubscription",
+			Handler:    _AuctionService_DeleteWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "EnableWebhookSubscription",
+			Handler:    _AuctionService_EnableWebhookSubscription_Handler,
+		},
+		{
+			MethodName: "ListWebhookDeliveries",
+			Handler:    _AuctionService_ListWebhookDeliveries_Handler,
+		},
+		{
+			MethodName: "ListBalanceTransactions",
+			Handler:    _AuctionService_ListBalanceTransactions_Handler,
+		},
+		{
+			MethodName: "ExportBalanceTransactions",
+			Handler:    _AuctionService_ExportBalanceTransactions_Handler,
+		},
+		{
+			MethodName: "RequestWithdrawal",
+			Handler:    _AuctionService_RequestWithdrawal_Handler,
+		},
+		{
+			MethodName: "ListWithdrawals",
+			Handler:    _AuctionService_ListWithdrawals_Handler,
+		},
+		{
+			MethodName: "ListAllWithdrawals",
+			Handler:    _AuctionService_ListAllWithdrawals_Handler,
+		},
+		{
+			MethodName: "ResolveWithdrawal",
+			Handler:    _AuctionService_ResolveWithdrawal_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
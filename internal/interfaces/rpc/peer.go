@@ -0,0 +1,80 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const forwardedForHeader = "x-forwarded-for"
+
+// TrustedProxies - адреса прокси (в том числе REST-шлюза), которым разрешено
+// передавать адрес клиента в x-forwarded-for
+type TrustedProxies []netip.Prefix
+
+func ParseTrustedProxies(addrs []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(addrs))
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "/") {
+			ip, err := netip.ParseAddr(addr)
+			if err != nil {
+				return nil, err
+			}
+			proxies = append(proxies, netip.PrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(addr)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, prefix)
+	}
+	return proxies, nil
+}
+
+func (p TrustedProxies) trusts(ip netip.Addr) bool {
+	for _, prefix := range p {
+		if prefix.Contains(ip.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP возвращает адрес клиента. x-forwarded-for учитывается, только если запрос
+// пришёл от доверенного прокси: адреса перебираются справа налево до первого недоверенного.
+func (p TrustedProxies) ClientIP(ctx context.Context) string {
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(pr.Addr.String())
+	if err != nil {
+		host = pr.Addr.String()
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	if !p.trusts(ip) {
+		return ip.Unmap().String()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	forwarded := strings.Split(strings.Join(md.Get(forwardedForHeader), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		ip = hop
+		if !p.trusts(hop) {
+			break
+		}
+	}
+	return ip.Unmap().String()
+}
@@ -0,0 +1,196 @@
+package rpc
+
+import (
+	"auction/internal/infrastructure/auth"
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	retryAfterHeader = "retry-after"
+	// bucketIdleTTL - через сколько неиспользуемые корзины удаляются из памяти
+	bucketIdleTTL = 10 * time.Minute
+)
+
+// RateLimit - параметры token bucket: Rate запросов в секунду с запасом Burst
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// MethodRateLimit - ограничения RPC-метода на пользователя и на IP-адрес клиента.
+// Нулевой RateLimit не ограничивает.
+type MethodRateLimit struct {
+	User RateLimit
+	IP   RateLimit
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter ограничивает частоту вызовов по пользователю и по IP-адресу клиента
+type RateLimiter struct {
+	limits  map[string]MethodRateLimit
+	proxies TrustedProxies
+	now     func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewRateLimiter(limits map[string]MethodRateLimit, proxies TrustedProxies) *RateLimiter {
+	return &RateLimiter{
+		limits:  limits,
+		proxies: proxies,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// IPUnaryInterceptor ограничивает вызовы по IP-адресу клиента. Ставится перед
+// аутентификацией, чтобы запросы без токена и с неверным токеном тоже расходовали запас адреса.
+func (l *RateLimiter) IPUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, delay := l.limitIP(ctx, info.FullMethod)
+		if delay > 0 {
+			return nil, rateLimited(delay, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+		}
+		return handler(ctx, req)
+	}
+}
+
+// UserUnaryInterceptor ограничивает вызовы по пользователю и ставится после аутентификации
+func (l *RateLimiter) UserUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if delay := l.limitUser(ctx, info.FullMethod); delay > 0 {
+			return nil, rateLimited(delay, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+		}
+		return handler(ctx, req)
+	}
+}
+
+// IPStreamInterceptor - IPUnaryInterceptor для потоков: токен расходуется при открытии потока
+func (l *RateLimiter) IPStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, delay := l.limitIP(ss.Context(), info.FullMethod)
+		if delay > 0 {
+			return rateLimited(delay, ss.SetHeader)
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// UserStreamInterceptor - UserUnaryInterceptor для потоков
+func (l *RateLimiter) UserStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if delay := l.limitUser(ss.Context(), info.FullMethod); delay > 0 {
+			return rateLimited(delay, ss.SetHeader)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// ipReservationKey - ключ контекста с токеном, взятым из корзины IP-адреса
+type ipReservationKey struct{}
+
+// limitIP берёт токен из корзины IP-адреса и сохраняет его в контексте: если запрос
+// отклонит ограничение пользователя, токен вернётся в корзину
+func (l *RateLimiter) limitIP(ctx context.Context, method string) (context.Context, time.Duration) {
+	limit, ok := l.limits[method]
+	if !ok || limit.IP.Rate <= 0 {
+		return ctx, 0
+	}
+	ip := l.proxies.ClientIP(ctx)
+	if ip == "" {
+		return ctx, 0
+	}
+
+	reservation, delay := l.reserve(method+"|ip|"+ip, limit.IP)
+	if delay > 0 {
+		return ctx, delay
+	}
+	return context.WithValue(ctx, ipReservationKey{}, reservation), 0
+}
+
+func (l *RateLimiter) limitUser(ctx context.Context, method string) time.Duration {
+	limit, ok := l.limits[method]
+	if !ok || limit.User.Rate <= 0 {
+		return 0
+	}
+	user, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return 0
+	}
+
+	_, delay := l.reserve(method+"|user|"+strconv.Itoa(user.ID), limit.User)
+	if delay > 0 {
+		// Отклонённый запрос не расходует запас IP-адреса
+		if reservation, ok := ctx.Value(ipReservationKey{}).(*rate.Reservation); ok {
+			reservation.CancelAt(l.now())
+		}
+	}
+	return delay
+}
+
+// reserve берёт токен из корзины. Если токенов нет, резервирование отменяется
+// и возвращается время ожидания.
+func (l *RateLimiter) reserve(key string, limit RateLimit) (*rate.Reservation, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), max(limit.Burst, 1))}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return nil, delay
+	}
+	return r, 0
+}
+
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimited возвращает ResourceExhausted с RetryInfo и передаёт клиенту заголовок retry-after
+func rateLimited(delay time.Duration, setHeader func(metadata.MD) error) error {
+	seconds := int(math.Ceil(delay.Seconds()))
+	_ = setHeader(metadata.Pairs(retryAfterHeader, strconv.Itoa(seconds)))
+
+	st := status.Newf(codes.ResourceExhausted, "rate limit exceeded, retry in %ds", seconds)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,160 @@
+package rpc
+
+import (
+	"auction/internal/infrastructure/auth"
+	v1 "auction/internal/interfaces/rpc/pb"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func requestContext(userID int, remoteIP string, forwardedFor string) context.Context {
+	ctx := auth.ContextWithUser(context.Background(), auth.User{ID: userID})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 40000}})
+	if forwardedFor != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(forwardedForHeader, forwardedFor))
+	}
+	return ctx
+}
+
+func TestRateLimiter(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"127.0.0.1"})
+	require.NoError(t, err)
+
+	limiter := NewRateLimiter(map[string]MethodRateLimit{
+		v1.AuctionService_PlaceBid_FullMethodName: {
+			User: RateLimit{Rate: 1, Burst: 2},
+			IP:   RateLimit{Rate: 1, Burst: 3},
+		},
+	}, proxies)
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	ipInterceptor, userInterceptor := limiter.IPUnaryInterceptor(), limiter.UserUnaryInterceptor()
+	call := func(ctx context.Context, method string) error {
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		_, err := ipInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return userInterceptor(ctx, req, info, func(context.Context, any) (any, error) {
+				return nil, nil
+			})
+		})
+		return err
+	}
+
+	// Пользователь 1 исчерпывает свой запас
+	assert.NoError(t, call(requestContext(1, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName))
+	assert.NoError(t, call(requestContext(1, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName))
+	err = call(requestContext(1, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	details := status.Convert(err).Details()
+	if assert.Len(t, details, 1) {
+		assert.Equal(t, time.Second, details[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+	}
+
+	// Отклонённый запрос не расходует запас IP: третий запрос с адреса проходит от другого пользователя
+	assert.NoError(t, call(requestContext(2, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName))
+	err = call(requestContext(3, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Методы без ограничений не учитываются
+	assert.NoError(t, call(requestContext(1, "10.0.0.1", ""), v1.AuctionService_CreateLot_FullMethodName))
+
+	// Через секунду корзины пополняются
+	now = now.Add(time.Second)
+	assert.NoError(t, call(requestContext(1, "10.0.0.1", ""), v1.AuctionService_PlaceBid_FullMethodName))
+}
+
+func TestRateLimiterLimitsIPBeforeAuthentication(t *testing.T) {
+	limiter := NewRateLimiter(map[string]MethodRateLimit{
+		v1.AuctionService_PlaceBid_FullMethodName: {
+			User: RateLimit{Rate: 1, Burst: 5},
+			IP:   RateLimit{Rate: 1, Burst: 2},
+		},
+	}, nil)
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	// Аутентификация отклоняет запросы с неверным токеном, но запас адреса уже израсходован
+	interceptor := limiter.IPUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: v1.AuctionService_PlaceBid_FullMethodName}
+	unauthenticated := func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	anonymous := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.5"), Port: 40000}})
+	for range 2 {
+		_, err := interceptor(anonymous, nil, info, unauthenticated)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+	_, err := interceptor(anonymous, nil, info, unauthenticated)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// fakeServerStream - поток с контекстом клиента
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func (s *fakeServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRateLimiterLimitsStreams(t *testing.T) {
+	const method = "/auction.v1.AuctionService/Watch"
+	limiter := NewRateLimiter(map[string]MethodRateLimit{
+		method: {User: RateLimit{Rate: 1, Burst: 1}},
+	}, nil)
+	now := time.Date(2024, 10, 17, 10, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	interceptor := limiter.UserStreamInterceptor()
+	open := func(stream *fakeServerStream) error {
+		return interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: method}, func(any, grpc.ServerStream) error {
+			return nil
+		})
+	}
+
+	assert.NoError(t, open(&fakeServerStream{ctx: requestContext(1, "10.0.0.1", "")}))
+	stream := &fakeServerStream{ctx: requestContext(1, "10.0.0.1", "")}
+	err := open(stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1"}, stream.header.Get(retryAfterHeader))
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"127.0.0.1", "10.1.0.0/16"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteIP     string
+		forwardedFor string
+		want         string
+	}{
+		{name: "Direct client", remoteIP: "203.0.113.5", want: "203.0.113.5"},
+		{name: "Direct client cannot spoof", remoteIP: "203.0.113.5", forwardedFor: "198.51.100.1", want: "203.0.113.5"},
+		{name: "Through gateway", remoteIP: "127.0.0.1", forwardedFor: "198.51.100.1", want: "198.51.100.1"},
+		{name: "Through load balancer and gateway", remoteIP: "127.0.0.1", forwardedFor: "6.6.6.6, 198.51.100.1, 10.1.2.3", want: "198.51.100.1"},
+		{name: "Gateway without header", remoteIP: "127.0.0.1", want: "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, proxies.ClientIP(requestContext(1, tt.remoteIP, tt.forwardedFor)))
+		})
+	}
+}
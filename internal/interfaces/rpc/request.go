@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"auction/internal/infrastructure/logging"
+	"auction/internal/infrastructure/request"
+	"context"
+	"log/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	requestIDHeader    = "x-request-id"
+	maxRequestIDLength = 128
+)
+
+// RequestInfoUnaryInterceptor сохраняет в контексте ID запроса (из x-request-id или новый),
+// адрес клиента и логгер с ID запроса и методом. ID запроса возвращается клиенту
+// в заголовке x-request-id.
+func RequestInfoUnaryInterceptor(proxies TrustedProxies, logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = withRequestInfo(ctx, proxies, logger, info.FullMethod)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, request.InfoFromContext(ctx).ID))
+		return handler(ctx, req)
+	}
+}
+
+func RequestInfoStreamInterceptor(proxies TrustedProxies, logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestInfo(ss.Context(), proxies, logger, info.FullMethod)
+		_ = ss.SetHeader(metadata.Pairs(requestIDHeader, request.InfoFromContext(ctx).ID))
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withRequestInfo(ctx context.Context, proxies TrustedProxies, logger *slog.Logger, method string) context.Context {
+	info := request.Info{
+		ID:       incomingRequestID(ctx),
+		ClientIP: proxies.ClientIP(ctx),
+	}
+	ctx = request.ContextWithInfo(ctx, info)
+	return logging.ContextWithLogger(ctx, logger.With("request_id", info.ID, "method", method))
+}
+
+func incomingRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(requestIDHeader); len(values) > 0 && validRequestID(values[0]) {
+		return values[0]
+	}
+	return request.NewID()
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
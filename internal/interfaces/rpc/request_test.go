@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"auction/internal/domain"
+	"auction/internal/infrastructure/auth"
+	"auction/internal/infrastructure/logging"
+	v1 "auction/internal/interfaces/rpc/pb"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type loggingAuctionService struct {
+	domain.AuctionService
+}
+
+func (loggingAuctionService) ListShillReviews(ctx context.Context, _ domain.ShillReviewStatus, _, _ int) ([]domain.ShillReview, error) {
+	logging.FromContext(ctx).Info("listing shill reviews")
+	return nil, nil
+}
+
+// newGateway поднимает gRPC-сервер с интерцептором ID запроса и REST-шлюз к нему
+func newGateway(t *testing.T, logger *slog.Logger) http.Handler {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(RequestInfoUnaryInterceptor(TrustedProxies{}, logger)))
+	v1.RegisterAuctionServiceServer(server, NewAuctionHandler(loggingAuctionService{}))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	mux := NewGatewayMux()
+	require.NoError(t, v1.RegisterAuctionServiceHandler(context.Background(), mux, conn))
+	return mux
+}
+
+func decodeLogLines(t *testing.T, out *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var line map[string]any
+		require.NoError(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestIDThroughGateway(t *testing.T) {
+	var out bytes.Buffer
+	gateway := newGateway(t, slog.New(slog.NewJSONHandler(&out, nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/shill-reviews", nil)
+	req.Header.Set("X-Request-Id", "req-42")
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req-42", rec.Header().Get("X-Request-Id"))
+	lines := decodeLogLines(t, &out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "req-42", lines[0]["request_id"])
+	assert.Equal(t, v1.AuctionService_ListShillReviews_FullMethodName, lines[0]["method"])
+
+	// Без заголовка ID назначается сервером и возвращается клиенту
+	rec = httptest.NewRecorder()
+	gateway.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/shill-reviews", nil))
+
+	requestID := rec.Header().Get("X-Request-Id")
+	assert.Len(t, requestID, 32)
+	lines = decodeLogLines(t, &out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, requestID, lines[0]["request_id"])
+}
+
+type staticVerifier struct {
+	user auth.User
+}
+
+func (v staticVerifier) Verify(string) (auth.User, error) {
+	return v.user, nil
+}
+
+func TestAuthAddsUserToLogger(t *testing.T) {
+	var out bytes.Buffer
+	ctx := logging.ContextWithLogger(context.Background(), slog.New(slog.NewJSONHandler(&out, nil)))
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, "Bearer token"))
+	interceptor := AuthUnaryInterceptor(staticVerifier{user: auth.User{ID: 7}})
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: v1.AuctionService_PlaceBid_FullMethodName},
+		func(ctx context.Context, _ any) (any, error) {
+			logging.FromContext(ctx).Info("placing bid")
+			return nil, nil
+		})
+
+	require.NoError(t, err)
+	lines := decodeLogLines(t, &out)
+	require.Len(t, lines, 1)
+	assert.Equal(t, 7.0, lines[0]["user_id"])
+}